@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dnatag/mission-toolkit/pkg/mission"
+	"github.com/spf13/cobra"
+)
+
+// archiveCmd represents the archive command
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Manage archived missions",
+	Long:  `Maintain the archive of completed missions and its index (.mission/completed/index.json).`,
+}
+
+// archiveReindexCmd rebuilds the archive index from the archived mission files
+var archiveReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the archive index from archived mission files",
+	Long: `Re-parse every archived mission in .mission/completed and rewrite index.json.
+
+The index is kept up to date by m mission archive and rebuilt automatically when
+mission files are added or removed. Run reindex after editing archived missions
+by hand. Commit hashes already recorded in the index are preserved.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index := mission.NewArchiveIndex(missionFs, filepath.Join(missionDir, "completed"))
+		entries, err := index.Rebuild()
+		if err != nil {
+			return fmt.Errorf("rebuilding archive index: %w", err)
+		}
+
+		fmt.Printf("Indexed %d archived missions in %s\n", len(entries), index.Path())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archiveReindexCmd)
+}
@@ -1,14 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/dnatag/mission-toolkit/pkg/checkpoint"
+	"github.com/dnatag/mission-toolkit/pkg/git"
 	"github.com/dnatag/mission-toolkit/pkg/mission"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+// defaultKeepArchived is how long the checkpoints of archived missions are kept by
+// m checkpoint gc
+const defaultKeepArchived = "7d"
+
 // checkpointCmd represents the checkpoint command
 var checkpointCmd = &cobra.Command{
 	Use:   "checkpoint",
@@ -16,37 +30,72 @@ var checkpointCmd = &cobra.Command{
 	Long:  `Create, restore, and clear checkpoints during mission execution.`,
 }
 
+// newGitClient opens the repository whose work tree is rooted at dir with the git client
+// selected by the git.client setting. Each git command is bounded by git.timeout.
+func newGitClient(ctx context.Context, dir string) (git.GitClient, error) {
+	client, err := git.NewClient(ctx, dir, git.ClientOptions{
+		Kind:    viper.GetString(configGitClient),
+		Timeout: viper.GetDuration(configGitTimeout),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository: %w", err)
+	}
+	return client, nil
+}
+
+// optionalGitClient is newGitClient for the current directory that returns no client
+// outside a git repository, for commands that also work without one
+func optionalGitClient(ctx context.Context) (git.GitClient, error) {
+	client, err := newGitClient(ctx, ".")
+	if errors.Is(err, git.ErrNotRepository) {
+		return nil, nil
+	}
+	return client, err
+}
+
+// newCheckpointService creates the checkpoint service for the active mission using
+// the configured checkpoint backend. Outside a git repository checkpoints default to
+// filesystem snapshots.
+func newCheckpointService(ctx context.Context) (*checkpoint.Service, error) {
+	gitClient, err := optionalGitClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint.NewServiceWithBackend(missionFs, activeMissionDir(), gitClient, viper.GetString(configCheckpointBackend))
+}
+
 // checkpointCreateCmd creates a new checkpoint
 var checkpointCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a checkpoint of current working directory state",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get mission ID
-		idService := mission.NewIDService(missionFs, missionPath)
+		idService := mission.NewIDService(missionFs, activeMissionPath())
 		missionID, err := idService.GetCurrentID()
 		if err != nil {
 			return fmt.Errorf("getting mission ID: %w", err)
 		}
 
 		// Create checkpoint service
-		svc, err := checkpoint.NewService(missionFs, missionDir)
+		svc, err := newCheckpointService(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("initializing checkpoint service: %w", err)
 		}
 
 		// Create checkpoint
-		checkpointName, err := svc.Create(missionID)
+		label, _ := cmd.Flags().GetString("label")
+		checkpointName, err := svc.CreateWithLabel(cmd.Context(), missionID, label)
 		if err != nil {
 			return fmt.Errorf("creating checkpoint: %w", err)
 		}
 
 		fmt.Printf("Checkpoint created: %s\n", checkpointName)
 
-		// Display baseline tag info if this is the first checkpoint
+		// Display baseline info if this is the first checkpoint
 		if strings.HasSuffix(checkpointName, "-1") {
-			baselineTag := missionID + "-baseline"
-			fmt.Printf("\n📌 Baseline tag created: %s\n", baselineTag)
-			fmt.Printf("   View cumulative changes: git diff %s\n", baselineTag)
+			baseline := missionID + "-baseline"
+			fmt.Printf("\n📌 Baseline checkpoint created: %s\n", baseline)
+			fmt.Printf("   View cumulative changes: m checkpoint diff\n")
 		}
 
 		return nil
@@ -57,54 +106,77 @@ var checkpointCreateCmd = &cobra.Command{
 var checkpointRestoreCmd = &cobra.Command{
 	Use:   "restore <checkpoint>",
 	Short: "Restore working directory to specified checkpoint",
-	Args:  cobra.ExactArgs(1),
+	Long: `Restore the SCOPE files to a checkpoint.
+
+With --all the SCOPE files are restored to the baseline, files in SCOPE that did not
+exist when the baseline was taken are deleted (unless --keep-new is given), and all
+checkpoints of the mission are cleared. Other untracked files are never touched.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		checkpointName := args[0]
-
 		// Handle --all flag
 		if cmd.Flags().Changed("all") {
 			// Get mission ID
-			idService := mission.NewIDService(missionFs, missionPath)
+			idService := mission.NewIDService(missionFs, activeMissionPath())
 			missionID, err := idService.GetCurrentID()
 			if err != nil {
 				return fmt.Errorf("getting mission ID: %w", err)
 			}
 
 			// Create checkpoint service
-			svc, err := checkpoint.NewService(missionFs, missionDir)
+			svc, err := newCheckpointService(cmd.Context())
 			if err != nil {
 				return fmt.Errorf("initializing checkpoint service: %w", err)
 			}
 
 			// Restore all changes and clear checkpoints
-			count, untrackedFiles, err := svc.RestoreAll(missionID)
+			keepNew, _ := cmd.Flags().GetBool("keep-new")
+			result, err := svc.RestoreAllWithOptions(cmd.Context(), missionID, checkpoint.RestoreAllOptions{KeepNew: keepNew})
 			if err != nil {
 				return fmt.Errorf("reverting all changes: %w", err)
 			}
 
-			fmt.Printf("Restored all changes, cleared %d checkpoint(s)\n", count)
+			fmt.Printf("Restored all changes, cleared %d checkpoint(s)\n", result.Cleared)
+
+			if len(result.Removed) > 0 {
+				fmt.Println("\nRemoved files created during the mission:")
+				for _, file := range result.Removed {
+					fmt.Printf("  - %s\n", file)
+				}
+			}
 
-			// Warn about untracked files if any exist
-			if len(untrackedFiles) > 0 {
-				fmt.Println("\n⚠️  Warning: Untracked files detected (created during mission):")
-				for _, file := range untrackedFiles {
+			// Untracked files the baseline did not list are never deleted, only reported
+			if len(result.Untracked) > 0 {
+				fmt.Println("\n⚠️  Warning: Untracked files were left in place:")
+				for _, file := range result.Untracked {
 					fmt.Printf("  - %s\n", file)
 				}
-				fmt.Println("\nTo remove these files, run:")
-				fmt.Println("  git clean -fd")
+				fmt.Println("\nRemove them by hand if the mission created them.")
+			}
+
+			if result.BaseBranch != "" {
+				fmt.Printf("\nSwitched back to %s\n", result.BaseBranch)
+				if !result.DeletedBranch {
+					fmt.Printf("Kept %s because it has commits that are not in %s\n", result.MissionBranch, result.BaseBranch)
+				}
 			}
 
 			return nil
 		}
+		checkpointName := args[0]
 
 		// Create checkpoint service
-		svc, err := checkpoint.NewService(missionFs, missionDir)
+		svc, err := newCheckpointService(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("initializing checkpoint service: %w", err)
 		}
 
 		// Restore to checkpoint
-		if err := svc.Restore(checkpointName); err != nil {
+		if err := svc.Restore(cmd.Context(), checkpointName); err != nil {
 			return fmt.Errorf("restoring to checkpoint: %w", err)
 		}
 
@@ -119,20 +191,20 @@ var checkpointClearCmd = &cobra.Command{
 	Short: "Clear all checkpoints for current mission",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get mission ID
-		idService := mission.NewIDService(missionFs, missionPath)
+		idService := mission.NewIDService(missionFs, activeMissionPath())
 		missionID, err := idService.GetCurrentID()
 		if err != nil {
 			return fmt.Errorf("getting mission ID: %w", err)
 		}
 
 		// Create checkpoint service
-		svc, err := checkpoint.NewService(missionFs, missionDir)
+		svc, err := newCheckpointService(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("initializing checkpoint service: %w", err)
 		}
 
 		// Clear checkpoints
-		count, err := svc.Clear(missionID)
+		count, err := svc.Clear(cmd.Context(), missionID)
 		if err != nil {
 			return fmt.Errorf("clearing checkpoints: %w", err)
 		}
@@ -142,37 +214,290 @@ var checkpointClearCmd = &cobra.Command{
 	},
 }
 
+// checkpointGCCmd removes checkpoints left behind by missions that are gone
+var checkpointGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove checkpoints of missions that are no longer active, paused or recently archived",
+	Long: `Find checkpoint tags (<id>-N, <id>-baseline) and refs (refs/mission/<id>/...) whose
+mission is not in the workspace or another worktree, not paused and not archived
+within --keep-archived, and delete them. Such checkpoints are left behind when a
+mission is abandoned, its .mission directory deleted or m.apply crashes.
+Use --dry-run to list them first. Checkpoint commits already on a branch stay in
+its history.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keep, _ := cmd.Flags().GetString("keep-archived")
+		archivedSince, err := mission.ParseHistoryTime(keep, time.Now(), false)
+		if err != nil {
+			return fmt.Errorf("invalid --keep-archived: %w", err)
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		gitClient, err := newGitClient(cmd.Context(), ".")
+		if err != nil {
+			return err
+		}
+		svc, err := checkpoint.NewServiceWithBackend(missionFs, missionDir, gitClient, viper.GetString(configCheckpointBackend))
+		if err != nil {
+			return fmt.Errorf("initializing checkpoint service: %w", err)
+		}
+		result, err := svc.GC(cmd.Context(), checkpoint.GCOptions{ArchivedSince: archivedSince, DryRun: dryRun})
+		if err != nil {
+			return fmt.Errorf("collecting checkpoints: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if result.Orphans == nil {
+				result.Orphans = []checkpoint.Orphan{}
+			}
+			jsonOutput, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		if len(result.Orphans) == 0 {
+			fmt.Println("No orphaned checkpoints")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MISSION\tCHECKPOINT\tCOMMIT\tREF")
+		for _, orphan := range result.Orphans {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", orphan.MissionID, orphan.Name, shortHash(orphan.Commit), orphan.Ref)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if dryRun {
+			fmt.Printf("Would remove %d checkpoint(s) of %d mission(s); run without --dry-run to remove them\n", len(result.Orphans), len(result.Missions))
+		} else {
+			fmt.Printf("Removed %d checkpoint(s) of %d mission(s)\n", len(result.Orphans), len(result.Missions))
+		}
+		return nil
+	},
+}
+
+// staleCheckpointWarning names the abandoned missions whose checkpoints m checkpoint
+// gc would remove, going by checkpoint metadata only. It returns "" when there are none
+// and describes the failure when they cannot be determined.
+func staleCheckpointWarning(ctx context.Context) string {
+	svc, err := newCheckpointService(ctx)
+	if err != nil {
+		return fmt.Sprintf("Could not check for stale checkpoints: %v", err)
+	}
+	archivedSince, _ := mission.ParseHistoryTime(defaultKeepArchived, time.Now(), false)
+	stale, err := svc.StaleMissions(ctx, archivedSince)
+	if err != nil {
+		return fmt.Sprintf("Could not check for stale checkpoints: %v", err)
+	}
+	if len(stale) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d abandoned mission(s) still have checkpoints: %s. Run m checkpoint gc --dry-run to review and m checkpoint gc to remove them.",
+		len(stale), strings.Join(stale, ", "))
+}
+
+// checkpointListCmd lists the checkpoints of the current or given mission
+var checkpointListCmd = &cobra.Command{
+	Use:   "list [mission-id]",
+	Short: "List checkpoints with their changes since the previous checkpoint",
+	Long: `List the checkpoints of the current mission, or of the given mission ID, oldest
+first. Each checkpoint shows its commit, when it was created, its label and the
+files changed since the previous checkpoint (for the first checkpoint, since the
+commit it was created on). Use --json to pick a restore target programmatically.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var missionID string
+		if len(args) > 0 {
+			missionID = args[0]
+		} else {
+			id, err := mission.NewIDService(missionFs, activeMissionPath()).GetCurrentID()
+			if err != nil {
+				return fmt.Errorf("getting mission ID: %w", err)
+			}
+			missionID = id
+		}
+
+		svc, err := newCheckpointService(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("initializing checkpoint service: %w", err)
+		}
+
+		checkpoints, err := svc.List(cmd.Context(), missionID)
+		if err != nil {
+			return fmt.Errorf("listing checkpoints: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if checkpoints == nil {
+				checkpoints = []checkpoint.Checkpoint{}
+			}
+			jsonOutput, err := json.MarshalIndent(checkpoints, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		if len(checkpoints) == 0 {
+			fmt.Printf("No checkpoints for mission %s\n", missionID)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCOMMIT\tCREATED\tFILES\tLINES\tLABEL")
+		for _, cp := range checkpoints {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t+%d -%d\t%s\n", cp.Name, shortHash(cp.Hash), cp.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+				len(cp.Files), cp.Additions, cp.Deletions, cp.Label)
+		}
+		return w.Flush()
+	},
+}
+
+// checkpointDiffCmd shows the changes between two checkpoints or the working tree
+var checkpointDiffCmd = &cobra.Command{
+	Use:   "diff [from] [to]",
+	Short: "Show changes between checkpoints or the working tree",
+	Long: `Show the changes of the current mission between two checkpoints. Each side may be
+a checkpoint name (MISSION-ID-N), a checkpoint number (N), "baseline" (the state the
+mission started from), "latest" (the most recent checkpoint) or, for the second
+side only, "working" (the working tree including untracked files). The defaults
+are baseline and working, showing everything the mission changed so far.
+
+Only files in the mission SCOPE are shown unless --all is given.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var from, to string
+		if len(args) > 0 {
+			from = args[0]
+		}
+		if len(args) > 1 {
+			to = args[1]
+		}
+
+		missionID, err := mission.NewIDService(missionFs, activeMissionPath()).GetCurrentID()
+		if err != nil {
+			return fmt.Errorf("getting mission ID: %w", err)
+		}
+
+		svc, err := newCheckpointService(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("initializing checkpoint service: %w", err)
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+		result, err := svc.Diff(cmd.Context(), missionID, from, to, checkpoint.DiffOptions{All: all})
+		if err != nil {
+			return fmt.Errorf("diffing checkpoints: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			jsonOutput, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		if len(result.Files) == 0 {
+			fmt.Printf("No changes between %s and %s\n", result.From, result.To)
+			return nil
+		}
+
+		if nameOnly, _ := cmd.Flags().GetBool("name-only"); nameOnly {
+			for _, file := range result.Files {
+				fmt.Println(file.Path)
+			}
+			return nil
+		}
+
+		if stat, _ := cmd.Flags().GetBool("stat"); stat {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, file := range result.Files {
+				lines := fmt.Sprintf("+%d -%d", file.Additions, file.Deletions)
+				if file.Binary {
+					lines = "binary"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", file.Path, file.Change, lines)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Printf("%d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)\n", len(result.Files), result.Additions, result.Deletions)
+			return nil
+		}
+
+		for _, file := range result.Files {
+			fmt.Print(file.Patch)
+		}
+		return nil
+	},
+}
+
 // checkpointCommitCmd creates the final commit for the mission
 var checkpointCommitCmd = &cobra.Command{
 	Use:   "commit",
 	Short: "Create final commit for the mission and clear checkpoints",
+	Long: `Squash the checkpoints of the mission into one commit and clear them.
+
+For a mission started with m mission start, the commit is made on its mission/<id>
+branch. --merge ff then fast-forwards the base branch to it, and --merge squash
+adds its changes to the base branch as a new commit with the same message; either
+way the base branch is checked out afterwards. A mission started with --worktree
+is merged in the main checkout, which must have the base branch checked out.
+
+The message is given with -m, read from a file with --message-file (- for stdin), or
+built from the mission with --from-mission: its type, domains, INTENT, completed PLAN
+steps, verification result and the failures in execution.log, rendered with
+.mission/commit-message.tmpl if it exists. --narrative adds a paragraph to the body.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get mission ID
-		idService := mission.NewIDService(missionFs, missionPath)
+		idService := mission.NewIDService(missionFs, activeMissionPath())
 		missionID, err := idService.GetCurrentID()
 		if err != nil {
 			return fmt.Errorf("getting mission ID: %w", err)
 		}
 
-		// Get commit message from flag
-		commitMsg, _ := cmd.Flags().GetString("message")
-		if commitMsg == "" {
-			return fmt.Errorf("commit message cannot be empty")
+		commitMsg, err := commitMessage(cmd)
+		if err != nil {
+			return err
 		}
 
 		// Create checkpoint service
-		svc, err := checkpoint.NewService(missionFs, missionDir)
+		svc, err := newCheckpointService(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("initializing checkpoint service: %w", err)
 		}
 
-		// Consolidate and commit
-		result, err := svc.Consolidate(missionID, commitMsg)
+		// Consolidate and commit. A mission in its own worktree merges in the main
+		// checkout, which has the base branch checked out.
+		opts := checkpoint.ConsolidateOptions{}
+		opts.Merge, _ = cmd.Flags().GetString("merge")
+		if m, err := mission.NewReader(missionFs, activeMissionPath()).Read(); err == nil && m.Worktree != "" && opts.Merge != "" {
+			gitClient, err := newGitClient(cmd.Context(), ".")
+			if err != nil {
+				return err
+			}
+			mainCheckout, err := mission.NewWorktreeService(missionFs, gitClient).Main(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if opts.BaseCheckout, err = newGitClient(cmd.Context(), mainCheckout); err != nil {
+				return err
+			}
+		}
+		result, err := svc.ConsolidateWithOptions(cmd.Context(), missionID, commitMsg, opts)
 		if err != nil {
 			return fmt.Errorf("consolidating commit: %w", err)
 		}
 
 		fmt.Printf("Final commit created: %s\n", result.CommitHash)
+		if result.MergedInto != "" {
+			fmt.Printf("Merged into %s: %s\n", result.MergedInto, result.MergeHash)
+		}
 
 		if len(result.UnstagedFiles) > 0 {
 			fmt.Printf("\n⚠️  UNSTAGED FILES DETECTED:\n")
@@ -189,10 +514,66 @@ var checkpointCommitCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(checkpointCmd)
-	checkpointCmd.AddCommand(checkpointCreateCmd, checkpointRestoreCmd, checkpointClearCmd, checkpointCommitCmd)
+	checkpointCmd.AddCommand(checkpointCreateCmd, checkpointListCmd, checkpointDiffCmd, checkpointRestoreCmd, checkpointClearCmd, checkpointCommitCmd, checkpointGCCmd)
 
 	// Add flags
+	checkpointCreateCmd.Flags().StringP("label", "l", "", "Short description of the checkpoint shown by checkpoint list")
+	checkpointListCmd.Flags().Bool("json", false, "Output as JSON")
+	checkpointDiffCmd.Flags().Bool("stat", false, "Show changed files with line counts")
+	checkpointDiffCmd.Flags().Bool("name-only", false, "Show only the names of changed files")
+	checkpointDiffCmd.Flags().Bool("json", false, "Output a JSON summary of the changed files")
+	checkpointDiffCmd.Flags().Bool("all", false, "Include files outside the mission scope")
+	checkpointDiffCmd.MarkFlagsMutuallyExclusive("stat", "name-only", "json")
 	checkpointRestoreCmd.Flags().Bool("all", false, "Restore all mission changes")
+	checkpointRestoreCmd.Flags().Bool("keep-new", false, "With --all, keep files created during the mission")
 	checkpointCommitCmd.Flags().StringP("message", "m", "", "Commit message for the final commit")
-	checkpointCommitCmd.MarkFlagRequired("message")
+	checkpointCommitCmd.Flags().String("message-file", "", "Read the commit message from a file (- for stdin)")
+	checkpointCommitCmd.Flags().Bool("from-mission", false, "Build the commit message from the mission and its execution log")
+	checkpointCommitCmd.Flags().String("narrative", "", "Paragraph added to the body of a message built with --from-mission")
+	checkpointCommitCmd.MarkFlagsOneRequired("message", "message-file", "from-mission")
+	checkpointCommitCmd.MarkFlagsMutuallyExclusive("message", "message-file", "from-mission")
+	checkpointCommitCmd.Flags().String("merge", "", "Merge the mission branch back into its base branch (ff or squash)")
+	checkpointGCCmd.Flags().Bool("dry-run", false, "List the orphaned checkpoints without removing them")
+	checkpointGCCmd.Flags().String("keep-archived", defaultKeepArchived, "Keep checkpoints of missions archived within this age or since this date")
+	checkpointGCCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// commitMessage returns the commit message given with -m, --message-file or
+// --from-mission
+func commitMessage(cmd *cobra.Command) (string, error) {
+	narrative, _ := cmd.Flags().GetString("narrative")
+	fromMission, _ := cmd.Flags().GetBool("from-mission")
+	if narrative != "" && !fromMission {
+		return "", fmt.Errorf("--narrative needs --from-mission")
+	}
+
+	var message string
+	switch file, _ := cmd.Flags().GetString("message-file"); {
+	case fromMission:
+		built, err := mission.NewCommitMessageBuilder(missionFs, activeMissionPath()).Build(narrative)
+		if err != nil {
+			return "", fmt.Errorf("building commit message: %w", err)
+		}
+		message = built
+	case file == "-":
+		content, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("reading commit message: %w", err)
+		}
+		message = string(content)
+	case file != "":
+		content, err := afero.ReadFile(missionFs, file)
+		if err != nil {
+			return "", fmt.Errorf("reading commit message: %w", err)
+		}
+		message = string(content)
+	default:
+		message, _ = cmd.Flags().GetString("message")
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "", fmt.Errorf("commit message cannot be empty")
+	}
+	return message, nil
 }
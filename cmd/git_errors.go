@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+)
+
+// GitErrorReport is printed as JSON when a command fails with a git error the prompts
+// can act on. Code is stable; Error is the message git or the client gave.
+type GitErrorReport struct {
+	Error    string `json:"error"`
+	Code     string `json:"code"`
+	NextStep string `json:"next_step"`
+}
+
+// gitErrorCodes maps each classified git error to its code and the recovery to suggest
+var gitErrorCodes = []struct {
+	err      error
+	code     string
+	nextStep string
+}{
+	{git.ErrNotRepository, "GIT_NOT_A_REPOSITORY", "STOP. Run m from the root of the project's Git repository, or initialize one with git init."},
+	{git.ErrTagExists, "GIT_TAG_EXISTS", "STOP. A checkpoint tag with this name already exists. Run m checkpoint list and ask the user before deleting or clearing checkpoints."},
+	{git.ErrDetachedHead, "GIT_DETACHED_HEAD", "STOP. HEAD is detached. Ask the user to switch to a branch with git switch <branch>, then retry."},
+	{git.ErrPathspec, "GIT_PATHSPEC", "STOP. A file in SCOPE does not exist and is not tracked. Fix the SCOPE paths in mission.md, then retry."},
+	{git.ErrIndexLocked, "GIT_INDEX_LOCKED", "STOP. Another git process holds .git/index.lock. Wait for it to finish and retry; if none is running, ask the user to remove the lock file."},
+	{git.ErrNoCommits, "GIT_NO_COMMITS", "STOP. The repository has no commits yet. Ask the user to make an initial commit, then retry."},
+	{git.ErrTimeout, "GIT_TIMEOUT", "STOP. A git command timed out. Retry, or ask the user to raise git.timeout (MISSION_GIT_TIMEOUT)."},
+}
+
+// gitErrorReport returns the report for err, or nil if it is not a classified git error
+func gitErrorReport(err error) *GitErrorReport {
+	for _, c := range gitErrorCodes {
+		if errors.Is(err, c.err) {
+			return &GitErrorReport{Error: err.Error(), Code: c.code, NextStep: c.nextStep}
+		}
+	}
+	return nil
+}
+
+// reportGitError prints the JSON report of a classified git error to stdout, where
+// the prompts read command output
+func reportGitError(err error) {
+	report := gitErrorReport(err)
+	if report == nil {
+		return
+	}
+	jsonOutput, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return
+	}
+	fmt.Println(string(jsonOutput))
+}
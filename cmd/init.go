@@ -19,6 +19,9 @@ import (
 var aiType string
 var globalMode bool
 
+// noGit skips git setup for directories that are not meant to be repositories
+var noGit bool
+
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
@@ -30,7 +33,9 @@ and AI-specific prompt templates.
 By default, templates are installed in the current project directory. Use --global flag
 to install to global config directory in user home directory instead.
 
-If a Git repository is not found, it will be initialized automatically.`,
+If a Git repository is not found, it will be initialized automatically. Use --no-git
+to leave the directory without one; checkpoints are then kept as filesystem snapshots
+in .mission/snapshots.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate AI type
 		if err := templates.ValidateAIType(aiType); err != nil {
@@ -88,6 +93,10 @@ If a Git repository is not found, it will be initialized automatically.`,
 			fmt.Printf("Mission Toolkit project initialized successfully for AI type: %s\n", aiType)
 		}
 
+		if noGit {
+			return
+		}
+
 		// Add .mission/ to .gitignore
 		if err := git.EnsureEntry(fs, cwd, ".mission/"); err != nil {
 			fmt.Fprintf(os.Stderr, "Error updating .gitignore: %v\n", err)
@@ -116,4 +125,7 @@ func init() {
 
 	// Add --global flag
 	initCmd.Flags().BoolVarP(&globalMode, "global", "g", false, "Install to global config directory in user home")
+
+	// Add --no-git flag
+	initCmd.Flags().BoolVar(&noGit, "no-git", false, "Do not initialize a Git repository or update .gitignore")
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/dnatag/mission-toolkit/pkg/logger"
 	"github.com/dnatag/mission-toolkit/pkg/mission"
@@ -22,7 +23,8 @@ var logCmd = &cobra.Command{
 		message := args[0]
 
 		// Get mission ID from centralized service
-		idService := mission.NewIDService(afero.NewOsFs(), ".mission/mission.md")
+		workspace := mission.NewWorkspace(afero.NewOsFs(), mission.DefaultDir)
+		idService := mission.NewIDService(afero.NewOsFs(), workspace.MissionPath())
 		missionID, err := idService.GetCurrentID()
 		if err != nil {
 			fmt.Printf("Warning: Could not get mission ID: %v\n", err)
@@ -36,6 +38,10 @@ var logCmd = &cobra.Command{
 		} else {
 			config.Output = logger.OutputBoth
 			config.FilePath = file
+			// Without an explicit path, log to the active mission's execution.log
+			if !cmd.Flags().Changed("file") {
+				config.FilePath = filepath.Join(workspace.MissionDir(), "execution.log")
+			}
 		}
 
 		// Create logger with custom config
@@ -54,5 +60,5 @@ func init() {
 	// Add flags
 	logCmd.Flags().StringP("level", "l", "SUCCESS", "Log level (DEBUG, INFO, WARN, ERROR, SUCCESS)")
 	logCmd.Flags().StringP("step", "s", "General", "Mission step name")
-	logCmd.Flags().StringP("file", "f", ".mission/execution.log", "Log file path (empty string for console only)")
+	logCmd.Flags().StringP("file", "f", ".mission/execution.log", "Log file path (defaults to the active mission's execution.log, empty string for console only)")
 }
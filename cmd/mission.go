@@ -2,20 +2,37 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/dnatag/mission-toolkit/pkg/git"
 	"github.com/dnatag/mission-toolkit/pkg/mission"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
-	missionFs   = afero.NewOsFs()
-	missionDir  = ".mission"
-	missionPath = ".mission/mission.md"
+	missionFs  = afero.NewOsFs()
+	missionDir = mission.DefaultDir
 )
 
+// activeMissionPath resolves the mission file of the active mission
+func activeMissionPath() string {
+	return mission.NewWorkspace(missionFs, missionDir).MissionPath()
+}
+
+// activeMissionDir resolves the artifact directory of the active mission
+func activeMissionDir() string {
+	return mission.NewWorkspace(missionFs, missionDir).MissionDir()
+}
+
 // missionCmd represents the mission command
 var missionCmd = &cobra.Command{
 	Use:   "mission",
@@ -28,7 +45,7 @@ var missionCheckCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check mission state and validate artifacts",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		checkService := mission.NewCheckService(missionFs, missionPath)
+		checkService := mission.NewCheckService(missionFs, activeMissionPath())
 
 		// Set command context if provided
 		context, _ := cmd.Flags().GetString("context")
@@ -40,6 +57,11 @@ var missionCheckCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("checking mission state: %w", err)
 		}
+		if context == "plan" {
+			if warning := staleCheckpointWarning(cmd.Context()); warning != "" {
+				status.Warnings = append(status.Warnings, warning)
+			}
+		}
 
 		jsonOutput, err := json.MarshalIndent(status, "", "  ")
 		if err != nil {
@@ -55,11 +77,12 @@ var missionUpdateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update mission status or sections",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		writer := mission.NewWriter(missionFs, missionPath)
+		writer := mission.NewWriter(missionFs, activeMissionPath())
 
 		// Handle status update
 		if cmd.Flags().Changed("status") {
 			status, _ := cmd.Flags().GetString("status")
+			writer.SetCommand(fmt.Sprintf("%s --status %s", cmd.CommandPath(), status))
 			if err := writer.UpdateStatus(status); err != nil {
 				return fmt.Errorf("updating mission status: %w", err)
 			}
@@ -114,7 +137,7 @@ var missionIDCmd = &cobra.Command{
 	Use:   "id",
 	Short: "Get or create mission ID",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		idService := mission.NewIDService(missionFs, missionPath)
+		idService := mission.NewIDService(missionFs, activeMissionPath())
 
 		id, err := idService.GetCurrentID()
 		if err != nil {
@@ -141,18 +164,19 @@ var missionCreateCmd = &cobra.Command{
 		}
 
 		// Get mission ID
-		idService := mission.NewIDService(missionFs, missionPath)
+		idService := mission.NewIDService(missionFs, activeMissionPath())
 		missionID, err := idService.GetOrCreateID()
 		if err != nil {
 			return fmt.Errorf("getting mission ID: %w", err)
 		}
 
-		writer := mission.NewWriter(missionFs, missionPath)
+		path := activeMissionPath()
+		writer := mission.NewWriter(missionFs, path)
 
 		if err := writer.CreateWithIntent(missionID, intent); err != nil {
 			return fmt.Errorf("creating mission with intent: %w", err)
 		}
-		fmt.Printf("Mission created: %s\n", missionDir+"/mission.md")
+		fmt.Printf("Mission created: %s\n", path)
 		return nil
 	},
 }
@@ -164,16 +188,31 @@ var missionArchiveCmd = &cobra.Command{
 	Short: "Archive mission files to completed directory and clean up obsolete files",
 	Long: `Archive the current mission files to the completed directory and clean up obsolete files.
 
+The mission commit is found by its Mission-Id trailer, added by m checkpoint commit,
+and its message is archived with the mission. Archiving is refused when there is no
+such commit or HEAD is not the mission commit.
+
 The --force flag controls behavior when no mission exists:
   - With --force: silently succeeds (no-op)
-  - Without --force: returns an error`,
+  - Without --force: returns an error
+With --force a mission without a commit is archived without one, and a mission whose
+commit is not HEAD is archived with that commit.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		force, _ := cmd.Flags().GetBool("force")
 
-		gitClient := git.NewCmdGitClient(".")
-		archiver := mission.NewArchiver(missionFs, missionPath, gitClient)
+		gitClient, err := optionalGitClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		archiver := mission.NewArchiver(missionFs, activeMissionPath(), gitClient)
+
+		// Read before archiving, which removes the mission file
+		var worktree string
+		if m, err := mission.NewReader(missionFs, activeMissionPath()).Read(); err == nil {
+			worktree = m.Worktree
+		}
 
-		if err := archiver.Archive(force); err != nil {
+		if err := archiver.Archive(cmd.Context(), force); err != nil {
 			return fmt.Errorf("archiving mission: %w", err)
 		}
 
@@ -183,6 +222,20 @@ The --force flag controls behavior when no mission exists:
 		}
 
 		fmt.Println("Mission archived successfully")
+
+		if worktree != "" {
+			worktrees := mission.NewWorktreeService(missionFs, gitClient)
+			mainCheckout, err := worktrees.Main(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := worktrees.Remove(cmd.Context(), worktree); err != nil {
+				fmt.Printf("\n⚠️  Warning: %v\n", err)
+				fmt.Println("Commit or discard the changes, then run `m mission worktrees --prune`.")
+				return nil
+			}
+			fmt.Printf("Removed worktree %s; continue in %s\n", worktree, mainCheckout)
+		}
 		return nil
 	},
 }
@@ -192,7 +245,7 @@ var missionFinalizeCmd = &cobra.Command{
 	Use:   "finalize",
 	Short: "Validate and display mission.md for review",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		finalizer := mission.NewFinalizeService(missionFs, missionPath)
+		finalizer := mission.NewFinalizeService(missionFs, activeMissionPath())
 
 		result, err := finalizer.Finalize()
 		if err != nil {
@@ -204,17 +257,172 @@ var missionFinalizeCmd = &cobra.Command{
 	},
 }
 
+// missionStartCmd moves the current mission onto its own branch
+var missionStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Create and switch to a mission/<id> branch for the current mission",
+	Long: `Create a mission/<id> branch from the current branch and switch to it.
+
+Checkpoints are then committed on the mission branch, and the branch it was
+started from is recorded in the mission frontmatter as base_branch. Use
+m checkpoint commit --merge ff|squash to merge the mission back into it;
+m checkpoint restore --all switches back to it and deletes the mission branch.
+Local changes are carried over to the mission branch.
+
+Run this before the first checkpoint of the mission.
+
+With --worktree the mission branch is checked out in a new git worktree under the
+worktree.dir setting (../<repository>-worktrees by default) instead, and
+the mission moves to its .mission directory, which shares completed missions,
+the backlog and governance files with this checkout. This checkout keeps its
+branch and local changes for other work; continue the mission in the worktree.
+m mission archive removes the worktree again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gitClient, err := optionalGitClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		starter := mission.NewStarter(missionFs, activeMissionPath(), gitClient)
+
+		var opts mission.StartOptions
+		if opts.Worktree, _ = cmd.Flags().GetBool("worktree"); opts.Worktree {
+			dir, err := missionWorktreeDir()
+			if err != nil {
+				return fmt.Errorf("resolving worktree directory: %w", err)
+			}
+			opts.WorktreeDir = dir
+		}
+
+		result, err := starter.StartWithOptions(cmd.Context(), opts)
+		if err != nil {
+			return fmt.Errorf("starting mission: %w", err)
+		}
+
+		if result.AlreadyStarted {
+			fmt.Printf("Mission %s is already on %s (base branch %s)\n", result.MissionID, result.Branch, result.BaseBranch)
+			return nil
+		}
+		if result.Worktree != "" {
+			fmt.Printf("Created worktree %s on %s (base branch %s)\n", result.Worktree, result.Branch, result.BaseBranch)
+			fmt.Printf("Continue the mission there: cd %s\n", result.Worktree)
+			return nil
+		}
+		fmt.Printf("Switched to %s (base branch %s)\n", result.Branch, result.BaseBranch)
+		return nil
+	},
+}
+
+// missionWorktreeDir resolves the directory mission worktrees are created in
+func missionWorktreeDir() (string, error) {
+	dir := viper.GetString(configWorktreeDir)
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(filepath.Dir(cwd), filepath.Base(cwd)+"-worktrees")
+	}
+	return filepath.Abs(dir)
+}
+
+// missionWorktreesCmd lists the worktrees of missions started with --worktree
+var missionWorktreesCmd = &cobra.Command{
+	Use:   "worktrees",
+	Short: "List mission worktrees, or prune finished ones",
+	Long: `List the worktrees created by m mission start --worktree with the mission in each.
+
+--prune forgets worktrees whose directory was deleted and removes worktrees whose
+mission was archived. Worktrees with local changes are kept and reported.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gitClient, err := newGitClient(cmd.Context(), ".")
+		if err != nil {
+			return err
+		}
+		worktrees := mission.NewWorktreeService(missionFs, gitClient)
+
+		if prune, _ := cmd.Flags().GetBool("prune"); prune {
+			result, err := worktrees.Prune(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, path := range result.Forgotten {
+				fmt.Printf("Forgot deleted worktree %s\n", path)
+			}
+			for _, path := range result.Removed {
+				fmt.Printf("Removed worktree %s\n", path)
+			}
+			for _, path := range slices.Sorted(maps.Keys(result.Kept)) {
+				fmt.Printf("Kept worktree %s: %s\n", path, strings.TrimSpace(result.Kept[path]))
+			}
+			if len(result.Forgotten)+len(result.Removed)+len(result.Kept) == 0 {
+				fmt.Println("No mission worktrees to prune")
+			}
+			return nil
+		}
+
+		list, err := worktrees.List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if list == nil {
+				list = []mission.MissionWorktree{}
+			}
+			data, err := json.MarshalIndent(list, "", "  ")
+			if err != nil {
+				return fmt.Errorf("encoding worktrees: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(list) == 0 {
+			fmt.Println("No mission worktrees")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BRANCH\tMISSION\tSTATUS\tPATH")
+		for _, wt := range list {
+			status := wt.Status
+			switch {
+			case wt.Prunable:
+				status = "deleted"
+			case wt.MissionID == "":
+				status = "archived"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", wt.Branch, wt.MissionID, status, wt.Path)
+		}
+		return w.Flush()
+	},
+}
+
 // missionPauseCmd pauses the current mission to .mission/paused/
 var missionPauseCmd = &cobra.Command{
 	Use:   "pause",
 	Short: "Pause current mission and save to .mission/paused/ folder",
+	Long: `Pause the current mission and save it to .mission/paused/.
+
+Working tree changes to files in SCOPE (or every changed file with --all) are
+stashed to refs/mission/paused/<id> and reverted, so another mission can start
+from a clean tree. The stash does not appear in git stash list; m mission restore
+reapplies it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pauser := mission.NewPauser(missionFs, missionPath)
+		all, _ := cmd.Flags().GetBool("all")
+		gitClient, err := optionalGitClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		pauser := mission.NewPauser(missionFs, activeMissionPath(), gitClient)
+		pauser.SetCommand(cmd.CommandPath())
 
-		if err := pauser.Pause(); err != nil {
+		record, err := pauser.Pause(cmd.Context(), mission.PauseOptions{AllChanges: all})
+		if err != nil {
 			return fmt.Errorf("pausing mission: %w", err)
 		}
 
+		if record.StashRef != "" {
+			fmt.Printf("Stashed changes to %d files in %s\n", len(record.Files), record.StashRef)
+		}
 		fmt.Println("Mission paused successfully")
 		return nil
 	},
@@ -224,24 +432,217 @@ var missionPauseCmd = &cobra.Command{
 var missionRestoreCmd = &cobra.Command{
 	Use:   "restore [mission-id]",
 	Short: "Restore a paused mission from .mission/paused/ folder",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Restore a paused mission from .mission/paused/ and reapply its stashed changes.
+
+If commits made since the pause changed files in the mission's SCOPE, or its
+checkpoints are no longer in the history of HEAD, restore lists them with the
+archived missions that made them and stops. Use --force to merge the stashed
+changes anyway, or --replay to first recreate the missing checkpoints on top of
+HEAD like a rebase.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pauser := mission.NewPauser(missionFs, missionPath)
+		gitClient, err := optionalGitClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		pauser := mission.NewPauser(missionFs, activeMissionPath(), gitClient)
+		pauser.SetCommand(cmd.CommandPath())
 
 		var missionID string
 		if len(args) > 0 {
 			missionID = args[0]
 		}
 
-		if err := pauser.Restore(missionID); err != nil {
+		var opts mission.RestoreOptions
+		opts.Force, _ = cmd.Flags().GetBool("force")
+		opts.Replay, _ = cmd.Flags().GetBool("replay")
+
+		result, err := pauser.Restore(cmd.Context(), missionID, opts)
+		if err != nil {
+			var overlapErr *mission.ScopeOverlapError
+			if errors.As(err, &overlapErr) {
+				printScopeOverlaps(overlapErr.MissionID, overlapErr.Overlaps)
+				if len(overlapErr.MissingCheckpoints) > 0 {
+					fmt.Printf("⚠️  Checkpoints no longer in the history of HEAD: %s\n", strings.Join(overlapErr.MissingCheckpoints, ", "))
+				}
+			}
 			return fmt.Errorf("restoring mission: %w", err)
 		}
 
+		printScopeOverlaps(result.MissionID, result.Overlaps)
+		if len(result.Replayed) > 0 {
+			fmt.Printf("Replayed checkpoints onto HEAD: %s\n", strings.Join(result.Replayed, ", "))
+		}
+
+		if record := result.Record; record != nil && record.StashRef != "" {
+			fmt.Printf("Reapplied stashed changes to %d files\n", len(record.Files))
+			if result.HeadMoved {
+				fmt.Printf("⚠️  HEAD moved since the mission was paused (was %s)\n", shortHash(record.BaseCommit))
+			}
+			if len(result.Conflicts) > 0 {
+				fmt.Println("⚠️  Conflicts with changes made since the pause; resolve the markers in:")
+				for _, file := range result.Conflicts {
+					fmt.Printf("   %s\n", file)
+				}
+			}
+		}
 		fmt.Println("Mission restored successfully")
 		return nil
 	},
 }
 
+// printScopeOverlaps lists SCOPE files changed since a mission was paused and the commits that changed them
+func printScopeOverlaps(missionID string, overlaps []mission.ScopeOverlap) {
+	if len(overlaps) == 0 {
+		return
+	}
+	fmt.Printf("⚠️  SCOPE files of %s changed since it was paused:\n", missionID)
+	for _, overlap := range overlaps {
+		fmt.Printf("   %s\n", overlap.Path)
+		for _, commit := range overlap.Commits {
+			by := ""
+			if commit.MissionID != "" {
+				by = fmt.Sprintf(" (mission %s)", commit.MissionID)
+			}
+			fmt.Printf("      %s %s%s\n", shortHash(commit.Hash), firstLine(commit.Subject, 60), by)
+		}
+	}
+}
+
+// missionPausedCmd groups commands that inspect paused missions
+var missionPausedCmd = &cobra.Command{
+	Use:   "paused",
+	Short: "List, show and drop paused missions",
+	Long: `Inspect missions paused to .mission/paused/.
+
+Each paused mission is described by a manifest (TIMESTAMP-ID-pause.json) that
+records its ID, when it was paused, its checkpoints and any stashed code changes.`,
+}
+
+// missionPausedListCmd lists paused missions
+var missionPausedListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List paused missions, most recently paused first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paused, err := mission.NewPauser(missionFs, activeMissionPath(), nil).List()
+		if err != nil {
+			return fmt.Errorf("listing paused missions: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if paused == nil {
+				paused = []mission.PausedMission{}
+			}
+			jsonOutput, err := json.MarshalIndent(paused, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		if len(paused) == 0 {
+			fmt.Println("No paused missions found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTATUS\tPAUSED\tCHECKPOINTS\tPLAN\tINTENT")
+		for _, p := range paused {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", p.MissionID, valueOrDash(p.Status), p.PausedAt.Local().Format("2006-01-02 15:04"),
+				len(p.Checkpoints), planProgressLabel(p.Progress), firstLine(p.Intent, 60))
+		}
+		return w.Flush()
+	},
+}
+
+// missionPausedShowCmd prints a paused mission and its pause manifest
+var missionPausedShowCmd = &cobra.Command{
+	Use:   "show <mission-id>",
+	Short: "Show a paused mission and its stashed changes",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paused, err := mission.NewPauser(missionFs, activeMissionPath(), nil).Show(args[0])
+		if err != nil {
+			return err
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			jsonOutput, err := json.MarshalIndent(paused, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		fmt.Printf("Mission:     %s\n", paused.MissionID)
+		fmt.Printf("Status:      %s\n", valueOrDash(paused.Status))
+		fmt.Printf("Paused at:   %s\n", paused.PausedAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("Plan:        %s\n", planProgressLabel(paused.Progress))
+		fmt.Printf("Checkpoints: %s\n", valueOrDash(strings.Join(paused.Checkpoints, ", ")))
+		if paused.BaseCommit != "" {
+			fmt.Printf("Base commit: %s\n", shortHash(paused.BaseCommit))
+		}
+		if paused.StashRef != "" {
+			fmt.Printf("Stash:       %s (%d files)\n", paused.StashRef, len(paused.Files))
+			for _, file := range paused.Files {
+				fmt.Printf("   %s\n", file)
+			}
+		}
+		printArchivedSection("Mission "+paused.MissionID, paused.Content, "")
+		return nil
+	},
+}
+
+// missionPausedDropCmd discards a paused mission and its stashed changes
+var missionPausedDropCmd = &cobra.Command{
+	Use:   "drop <mission-id>",
+	Short: "Discard a paused mission and its stashed code changes",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gitClient, err := optionalGitClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		pauser := mission.NewPauser(missionFs, activeMissionPath(), gitClient)
+		dropped, err := pauser.Drop(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("dropping paused mission: %w", err)
+		}
+
+		if dropped.StashRef != "" {
+			fmt.Printf("Deleted stashed changes to %d files in %s\n", len(dropped.Files), dropped.StashRef)
+		}
+		fmt.Printf("Dropped paused mission %s\n", dropped.MissionID)
+		return nil
+	},
+}
+
+// planProgressLabel summarizes plan progress, or returns "-" without a plan
+func planProgressLabel(progress *mission.PlanProgress) string {
+	if progress == nil {
+		return "-"
+	}
+	return progress.Summary
+}
+
+// valueOrDash returns value, or "-" if it is empty
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
 // missionMarkCompleteCmd marks a plan step as complete
 var missionMarkCompleteCmd = &cobra.Command{
 	Use:   "mark-complete",
@@ -252,7 +653,7 @@ var missionMarkCompleteCmd = &cobra.Command{
 			return fmt.Errorf("--step is required")
 		}
 
-		writer := mission.NewWriter(missionFs, missionPath)
+		writer := mission.NewWriter(missionFs, activeMissionPath())
 
 		status, _ := cmd.Flags().GetString("status")
 		message, _ := cmd.Flags().GetString("message")
@@ -266,22 +667,415 @@ var missionMarkCompleteCmd = &cobra.Command{
 	},
 }
 
+// missionStepCmd groups the plan step status commands
+var missionStepCmd = &cobra.Command{
+	Use:   "step",
+	Short: "Track plan step status (start, done, skip, fail, reset, list)",
+	Long: `Track the status of PLAN steps. Steps are referenced by their stable ID (s3)
+or by their position in the plan (3). Statuses are rendered as checkbox markers:
+[ ] pending, [~] in_progress, [x] done, [-] skipped, [!] failed.`,
+}
+
+// newMissionStepCmd creates a step subcommand that moves a step to status
+func newMissionStepCmd(use, status, short string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use + " <step>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			message, _ := cmd.Flags().GetString("message")
+
+			writer := mission.NewWriter(missionFs, activeMissionPath())
+			if err := writer.UpdateStepStatus(args[0], status, message); err != nil {
+				return fmt.Errorf("updating step %s: %w", args[0], err)
+			}
+
+			fmt.Printf("Plan step %s marked as %s\n", args[0], status)
+			return nil
+		},
+	}
+	cmd.Flags().String("message", "", "Message to log for this step")
+	return cmd
+}
+
+// missionStepListCmd lists plan steps with their status
+var missionStepListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List plan steps with their status",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := mission.NewReader(missionFs, activeMissionPath()).Read()
+		if err != nil {
+			return fmt.Errorf("reading mission: %w", err)
+		}
+		steps := m.PlanSteps()
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			jsonOutput, err := json.MarshalIndent(steps, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		if len(steps) == 0 {
+			fmt.Println("No plan steps found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tID\tSTATUS\tSTEP")
+		for i, step := range steps {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, step.ID, step.Status, firstLine(step.Text, 60))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\nPlan: %s\n", m.PlanProgress().Summary)
+		return nil
+	},
+}
+
+// missionListCmd lists all missions in the workspace
+var missionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all missions and show which one is active",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := mission.NewWorkspace(missionFs, missionDir).List()
+		if err != nil {
+			return fmt.Errorf("listing missions: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			jsonOutput, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No missions found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tID\tSTATUS\tINTENT")
+		for _, entry := range entries {
+			marker := ""
+			if entry.Active {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, entry.ID, entry.Status, firstLine(entry.Intent, 60))
+		}
+		return w.Flush()
+	},
+}
+
+// missionHistoryCmd lists archived missions matching the given filters
+var missionHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List archived missions with optional filters",
+	Long: `List missions archived in .mission/completed, newest first.
+
+Filters combine with AND. --since and --until accept a date (2026-01-31), an
+RFC 3339 timestamp or an age such as 7d, 2w or 12h and compare against the
+completion time. --touching matches a file or directory against each mission's
+SCOPE. --text searches the archived mission and its commit message.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := historyFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		entries, err := mission.NewHistory(missionFs, activeMissionPath()).Query(filter)
+		if err != nil {
+			return fmt.Errorf("querying mission history: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if entries == nil {
+				entries = []mission.ArchiveEntry{}
+			}
+			jsonOutput, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No archived missions found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTATUS\tTYPE\tTRACK\tCOMPLETED\tINTENT")
+		for _, entry := range entries {
+			completed := "-"
+			if entry.CompletedAt != nil {
+				completed = entry.CompletedAt.Local().Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", entry.ID, entry.Status, entry.Type, entry.Track, completed, firstLine(entry.Intent, 60))
+		}
+		return w.Flush()
+	},
+}
+
+// historyFilterFromFlags builds a history filter from the history command flags
+func historyFilterFromFlags(cmd *cobra.Command) (mission.HistoryFilter, error) {
+	var filter mission.HistoryFilter
+	filter.Status, _ = cmd.Flags().GetString("status")
+	filter.Type, _ = cmd.Flags().GetString("type")
+	filter.Track, _ = cmd.Flags().GetInt("track")
+	filter.Domain, _ = cmd.Flags().GetString("domain")
+	filter.Touching, _ = cmd.Flags().GetString("touching")
+	filter.Text, _ = cmd.Flags().GetString("text")
+
+	now := time.Now()
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	var err error
+	if filter.Since, err = mission.ParseHistoryTime(since, now, false); err != nil {
+		return filter, fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = mission.ParseHistoryTime(until, now, true); err != nil {
+		return filter, fmt.Errorf("invalid --until: %w", err)
+	}
+	return filter, nil
+}
+
+// missionShowCmd prints an archived mission with its execution log and commit message
+var missionShowCmd = &cobra.Command{
+	Use:   "show <mission-id>",
+	Short: "Show an archived mission with its execution log and commit message",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archived, err := mission.NewHistory(missionFs, activeMissionPath()).Show(args[0])
+		if err != nil {
+			return err
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			jsonOutput, err := json.MarshalIndent(archived, "", "  ")
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+
+		fmt.Printf("=== Mission %s ===\n\n%s\n", archived.Entry.ID, strings.TrimSpace(archived.Content))
+		printArchivedSection("Execution Log", archived.ExecutionLog, "No execution log archived")
+		commitTitle := "Commit Message"
+		if archived.Entry.CommitHash != "" {
+			commitTitle = fmt.Sprintf("Commit Message (%s)", archived.Entry.CommitHash)
+		}
+		printArchivedSection(commitTitle, archived.CommitMessage, "No commit message archived")
+		return nil
+	},
+}
+
+// printArchivedSection prints a titled section of mission show output
+func printArchivedSection(title, content, empty string) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		content = empty
+	}
+	fmt.Printf("\n=== %s ===\n\n%s\n", title, content)
+}
+
+// missionSwitchCmd switches the active mission
+var missionSwitchCmd = &cobra.Command{
+	Use:   "switch [mission-id]",
+	Short: "Switch the active mission, or detach with --new to plan another one",
+	Long: `Switch the active mission to the given mission ID.
+
+Each mission keeps its own mission.md, execution.log, plan.json and checkpoints
+under .mission/missions/<id>/. Use --new to detach from the current mission so a
+new one can be planned alongside it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace := mission.NewWorkspace(missionFs, missionDir)
+
+		if newMission, _ := cmd.Flags().GetBool("new"); newMission {
+			if len(args) > 0 {
+				return fmt.Errorf("--new does not take a mission ID")
+			}
+			if err := workspace.Detach(); err != nil {
+				return fmt.Errorf("detaching from current mission: %w", err)
+			}
+			fmt.Println("Detached from current mission, ready to plan a new one")
+			return nil
+		}
+
+		if len(args) == 0 {
+			return fmt.Errorf("mission ID is required (or use --new)")
+		}
+		if err := workspace.Switch(args[0]); err != nil {
+			return fmt.Errorf("switching mission: %w", err)
+		}
+		fmt.Printf("Switched to mission: %s\n", args[0])
+		return nil
+	},
+}
+
+// missionScopeCheckCmd reports working tree changes outside the mission scope
+var missionScopeCheckCmd = &cobra.Command{
+	Use:   "scope-check",
+	Short: "Check working tree changes against mission SCOPE",
+	Long: `Diff the working tree against the mission baseline checkpoint (or HEAD before the
+first checkpoint) and report out-of-scope modified, created and deleted files and unused
+scope entries as JSON.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gitClient, err := newGitClient(cmd.Context(), ".")
+		if err != nil {
+			return err
+		}
+		svc, err := newCheckpointService(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("initializing checkpoint service: %w", err)
+		}
+		checker := mission.NewScopeChecker(missionFs, activeMissionPath(), gitClient, svc)
+
+		report, err := checker.Check(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("checking scope: %w", err)
+		}
+
+		jsonOutput, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+		fmt.Println(string(jsonOutput))
+		return nil
+	},
+}
+
+// missionScopeCmd prints the mission SCOPE, optionally expanded to concrete files
+var missionScopeCmd = &cobra.Command{
+	Use:   "scope",
+	Short: "Print mission SCOPE entries, or the files they match with --resolve",
+	Long: `Print the SCOPE entries of the active mission, one per line.
+
+Entries may be literal files, directories (pkg/auth/), doublestar globs
+(pkg/**/*.go) and negations (!pkg/**/*_test.go). Use --resolve to expand them
+to the files they currently match on disk.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reader := mission.NewReader(missionFs, activeMissionPath())
+
+		var scope string
+		var err error
+		if resolve, _ := cmd.Flags().GetBool("resolve"); resolve {
+			scope, err = reader.ReadResolvedScope()
+		} else {
+			scope, err = reader.ReadScope()
+		}
+		if err != nil {
+			return fmt.Errorf("reading scope: %w", err)
+		}
+		if scope != "" {
+			fmt.Println(scope)
+		}
+		return nil
+	},
+}
+
+// missionVerifyCmd runs the VERIFICATION commands and records the results
+var missionVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Run the VERIFICATION commands and record the results",
+	Long: `Extract the commands from the VERIFICATION section and run them in order with
+a per-command timeout, stopping at the first failure. Exit code, duration and
+truncated stdout/stderr of every command are logged to execution.log and printed
+as JSON. Use --update-status to move the mission to executed or failed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		maxOutput, _ := cmd.Flags().GetInt("max-output")
+		updateStatus, _ := cmd.Flags().GetBool("update-status")
+
+		verifier := mission.NewVerifier(missionFs, activeMissionPath())
+		result, err := verifier.Verify(mission.VerifyOptions{
+			Timeout:      timeout,
+			MaxOutput:    maxOutput,
+			UpdateStatus: updateStatus,
+		})
+		if err != nil {
+			return fmt.Errorf("running verification: %w", err)
+		}
+
+		jsonOutput, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+		fmt.Println(string(jsonOutput))
+		return nil
+	},
+}
+
+// firstLine returns the first line of s truncated to maxLen characters
+func firstLine(s string, maxLen int) string {
+	if idx := strings.Index(s, "\n"); idx != -1 {
+		s = s[:idx]
+	}
+	if len(s) > maxLen {
+		return s[:maxLen-3] + "..."
+	}
+	return s
+}
+
 func init() {
 	rootCmd.AddCommand(missionCmd)
-	missionCmd.AddCommand(missionCheckCmd, missionUpdateCmd, missionIDCmd, missionCreateCmd, missionArchiveCmd, missionFinalizeCmd, missionPauseCmd, missionRestoreCmd, missionMarkCompleteCmd)
+	missionStepCmd.AddCommand(
+		newMissionStepCmd("start", mission.StepInProgress, "Mark a plan step as in progress"),
+		newMissionStepCmd("done", mission.StepDone, "Mark a plan step as done"),
+		newMissionStepCmd("skip", mission.StepSkipped, "Mark a plan step as skipped"),
+		newMissionStepCmd("fail", mission.StepFailed, "Mark a plan step as failed"),
+		newMissionStepCmd("reset", mission.StepPending, "Reset a plan step to pending"),
+		missionStepListCmd,
+	)
+	missionPausedCmd.AddCommand(missionPausedListCmd, missionPausedShowCmd, missionPausedDropCmd)
+	missionCmd.AddCommand(missionCheckCmd, missionUpdateCmd, missionIDCmd, missionCreateCmd, missionArchiveCmd, missionFinalizeCmd, missionStartCmd, missionWorktreesCmd, missionPauseCmd, missionRestoreCmd, missionPausedCmd, missionMarkCompleteCmd, missionListCmd, missionHistoryCmd, missionShowCmd, missionSwitchCmd, missionScopeCheckCmd, missionScopeCmd, missionVerifyCmd, missionStepCmd)
 
 	// Add flags
 	missionCheckCmd.Flags().StringP("context", "c", "", "Context for validation (plan, apply, complete, or debug)")
-	missionUpdateCmd.Flags().StringP("status", "s", "", "New mission status")
+	missionUpdateCmd.Flags().StringP("status", "s", "", "New mission status (planning, planned, active, executed, completed, failed, paused)")
 	missionUpdateCmd.Flags().String("section", "", "Section to update (intent, verification, scope, plan)")
 	missionUpdateCmd.Flags().String("content", "", "Content for text sections")
 	missionUpdateCmd.Flags().StringArray("item", nil, "Items for list sections")
 	missionUpdateCmd.Flags().Bool("append", false, "Append items instead of replacing all existing items")
-	missionUpdateCmd.Flags().StringSlice("frontmatter", nil, "Frontmatter key=value pairs")
+	missionUpdateCmd.Flags().StringSlice("frontmatter", nil, "Frontmatter edits: key=value (set), key+=value (list add), key-=value (list remove), -key (unset)")
 	missionCreateCmd.Flags().String("intent", "", "Intent text for initial mission creation")
 	missionCreateCmd.MarkFlagRequired("intent")
-	missionArchiveCmd.Flags().Bool("force", false, "Forcefully archive mission or no-op if no current mission exists")
+	missionArchiveCmd.Flags().Bool("force", false, "Archive the mission without its commit at HEAD, or no-op if no current mission exists")
+	missionStartCmd.Flags().Bool("worktree", false, "Check the mission branch out in a new git worktree and move the mission there")
+	missionWorktreesCmd.Flags().Bool("prune", false, "Forget deleted worktrees and remove those whose mission was archived")
+	missionWorktreesCmd.Flags().Bool("json", false, "Output as JSON")
+	missionPauseCmd.Flags().Bool("all", false, "Stash every working tree change, not only files in SCOPE")
+	missionRestoreCmd.Flags().Bool("force", false, "Restore even if SCOPE files changed since the pause")
+	missionRestoreCmd.Flags().Bool("replay", false, "Recreate checkpoints missing from the history of HEAD on top of it before restoring")
+	missionPausedListCmd.Flags().Bool("json", false, "Output as JSON")
+	missionPausedShowCmd.Flags().Bool("json", false, "Output as JSON")
 	missionMarkCompleteCmd.Flags().Int("step", 0, "Step number to mark as complete")
 	missionMarkCompleteCmd.Flags().String("status", "INFO", "Status level for logging (INFO, SUCCESS, FAILED, etc.)")
 	missionMarkCompleteCmd.Flags().String("message", "", "Message to log for this step")
+	missionListCmd.Flags().Bool("json", false, "Output as JSON")
+	missionHistoryCmd.Flags().String("status", "", "Only missions with this final status (completed, failed)")
+	missionHistoryCmd.Flags().String("type", "", "Only missions of this type (WET or DRY)")
+	missionHistoryCmd.Flags().Int("track", 0, "Only missions on this track (1-4)")
+	missionHistoryCmd.Flags().String("domain", "", "Only missions tagged with this domain")
+	missionHistoryCmd.Flags().String("since", "", "Only missions completed at or after this time (date, RFC 3339 or age like 7d)")
+	missionHistoryCmd.Flags().String("until", "", "Only missions completed at or before this time (date, RFC 3339 or age like 7d)")
+	missionHistoryCmd.Flags().String("touching", "", "Only missions whose SCOPE covers this file or directory")
+	missionHistoryCmd.Flags().String("text", "", "Only missions whose content or commit message contains this text")
+	missionHistoryCmd.Flags().Bool("json", false, "Output as JSON")
+	missionShowCmd.Flags().Bool("json", false, "Output as JSON")
+	missionStepListCmd.Flags().Bool("json", false, "Output as JSON")
+	missionSwitchCmd.Flags().Bool("new", false, "Detach from the current mission to plan a new one")
+	missionScopeCmd.Flags().Bool("resolve", false, "Expand glob and directory entries to matching files")
+	missionVerifyCmd.Flags().Duration("timeout", mission.DefaultVerifyTimeout, "Timeout for each verification command")
+	missionVerifyCmd.Flags().Int("max-output", mission.DefaultVerifyMaxOutput, "Bytes of stdout and stderr to keep per command")
+	missionVerifyCmd.Flags().Bool("update-status", false, "Set status to executed on success or failed on failure")
 }
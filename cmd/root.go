@@ -22,8 +22,12 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -31,6 +35,22 @@ import (
 
 var cfgFile string
 
+// Configuration keys, also settable as MISSION_<KEY> environment variables with dots
+// replaced by underscores
+const (
+	// configCheckpointBackend selects where checkpoints are stored: tags (default) or refs
+	configCheckpointBackend = "checkpoint.backend"
+	// configWorktreeDir is the directory m mission start --worktree creates worktrees in,
+	// ../<repository>-worktrees by default
+	configWorktreeDir = "worktree.dir"
+	// configGitClient selects the git implementation: cmd (default) runs the git CLI,
+	// go-git works without it
+	configGitClient = "git.client"
+	// configGitTimeout bounds each git command, 5m by default; 0 keeps the default and
+	// a negative duration disables the limit
+	configGitTimeout = "git.timeout"
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "m",
@@ -43,8 +63,13 @@ var rootCmd = &cobra.Command{
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Interrupting m cancels the git command it is running.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		reportGitError(err)
+		stop()
 		os.Exit(1)
 	}
 }
@@ -79,6 +104,8 @@ func initConfig() {
 		viper.SetConfigName(".mission")
 	}
 
+	viper.SetEnvPrefix("mission")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
@@ -7,6 +7,7 @@ import (
 	"text/template"
 
 	"github.com/dnatag/mission-toolkit/pkg/logger"
+	"github.com/dnatag/mission-toolkit/pkg/mission"
 	"github.com/spf13/afero"
 )
 
@@ -39,6 +40,11 @@ func (s *BaseService) FS() afero.Fs {
 	return s.fs
 }
 
+// MissionPath returns the path of the active mission file
+func (s *BaseService) MissionPath() string {
+	return mission.NewWorkspace(s.fs, mission.DefaultDir).MissionPath()
+}
+
 // Log returns the logger
 func (s *BaseService) Log() *logger.Logger {
 	return s.log
@@ -3,7 +3,6 @@ package analyze
 import (
 	_ "embed"
 	"fmt"
-	"path/filepath"
 
 	"github.com/dnatag/mission-toolkit/pkg/logger"
 	"github.com/dnatag/mission-toolkit/pkg/mission"
@@ -36,15 +35,14 @@ func NewComplexityServiceWithConfig(fs afero.Fs, loggerConfig *logger.Config) *C
 func (s *ComplexityService) ProvideTemplate() (string, error) {
 	s.Log().LogStep(logger.LevelSuccess, "AnalyzeComplexity", "Starting complexity analysis")
 
-	missionPath := filepath.Join(".mission", "mission.md")
-	reader := mission.NewReader(s.FS(), missionPath)
+	reader := mission.NewReader(s.FS(), s.MissionPath())
 
 	intent, err := reader.ReadIntent()
 	if err != nil {
 		return "", fmt.Errorf("reading current intent: %w", err)
 	}
 
-	scope, err := reader.ReadScope()
+	scope, err := reader.ReadResolvedScope()
 	if err != nil {
 		return "", fmt.Errorf("reading current scope: %w", err)
 	}
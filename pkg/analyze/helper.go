@@ -22,16 +22,19 @@ func CreateTestLoggerConfig(fs afero.Fs) *logger.Config {
 	}
 }
 
-// CreateLogger creates a logger with optional config, reading mission ID from filesystem
+// CreateLogger creates a logger with optional config, reading mission ID from filesystem.
+// Without a config, logs go to the active mission's execution.log.
 func CreateLogger(fs afero.Fs, loggerConfig *logger.Config) *logger.Logger {
-	missionPath := filepath.Join(".mission", "mission.md")
-	reader := mission.NewReader(fs, missionPath)
+	workspace := mission.NewWorkspace(fs, mission.DefaultDir)
+	reader := mission.NewReader(fs, workspace.MissionPath())
 	missionID, _ := reader.GetMissionID()
 
 	if loggerConfig != nil {
 		return logger.NewWithConfig(missionID, loggerConfig)
 	}
-	return logger.New(missionID)
+	config := logger.DefaultConfig()
+	config.FilePath = filepath.Join(workspace.MissionDir(), "execution.log")
+	return logger.NewWithConfig(missionID, config)
 }
 
 // FormatOutput writes template to .mission/templates/ and returns JSON with path
@@ -3,7 +3,6 @@ package analyze
 import (
 	_ "embed"
 	"fmt"
-	"path/filepath"
 
 	"github.com/dnatag/mission-toolkit/pkg/logger"
 	"github.com/dnatag/mission-toolkit/pkg/mission"
@@ -36,8 +35,7 @@ func NewScopeServiceWithConfig(fs afero.Fs, loggerConfig *logger.Config) *ScopeS
 func (s *ScopeService) ProvideTemplate() (string, error) {
 	s.Log().LogStep(logger.LevelSuccess, "AnalyzeScope", "Starting scope analysis")
 
-	missionPath := filepath.Join(".mission", "mission.md")
-	reader := mission.NewReader(s.FS(), missionPath)
+	reader := mission.NewReader(s.FS(), s.MissionPath())
 
 	intent, err := reader.ReadIntent()
 	if err != nil {
@@ -2,7 +2,9 @@ package analyze
 
 import (
 	"fmt"
+	"path/filepath"
 
+	"github.com/dnatag/mission-toolkit/pkg/mission"
 	"github.com/spf13/afero"
 )
 
@@ -29,7 +31,7 @@ func (s *Service) InitializePlan(intent string) error {
 		OriginalIntent: intent,
 	}
 
-	if err := SaveState(s.fs, state, ".mission/plan.json"); err != nil {
+	if err := SaveState(s.fs, state, s.statePath()); err != nil {
 		return fmt.Errorf("initializing plan: %w", err)
 	}
 
@@ -39,11 +41,16 @@ func (s *Service) InitializePlan(intent string) error {
 // GetPlanState retrieves the current plan state from disk.
 // Returns an error if plan.json doesn't exist or is invalid.
 func (s *Service) GetPlanState() (*PlanState, error) {
-	return LoadState(s.fs, ".mission/plan.json")
+	return LoadState(s.fs, s.statePath())
 }
 
 // UpdatePlanState persists changes to the plan state.
 // Used by analysis steps to incrementally build up the plan.
 func (s *Service) UpdatePlanState(state *PlanState) error {
-	return SaveState(s.fs, state, ".mission/plan.json")
+	return SaveState(s.fs, state, s.statePath())
+}
+
+// statePath returns the plan.json path of the active mission.
+func (s *Service) statePath() string {
+	return filepath.Join(mission.NewWorkspace(s.fs, mission.DefaultDir).MissionDir(), "plan.json")
 }
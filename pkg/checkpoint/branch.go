@@ -0,0 +1,113 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dnatag/mission-toolkit/pkg/mission"
+)
+
+// Merge modes for ConsolidateOptions.Merge
+const (
+	// MergeFastForward moves the base branch up to the mission commit
+	MergeFastForward = "ff"
+	// MergeSquash adds the changes of the mission branch to the base branch as one new commit
+	MergeSquash = "squash"
+)
+
+// mergeableMission reads the mission and checks, before anything is committed, that it
+// runs on its own branch, which is checked out, that the base branch is checked out in
+// opts.BaseCheckout if given, and that a fast-forward is possible
+func (s *Service) mergeableMission(ctx context.Context, opts ConsolidateOptions) (*mission.Mission, error) {
+	if opts.Merge != MergeFastForward && opts.Merge != MergeSquash {
+		return nil, fmt.Errorf("unknown merge mode %q (use %s or %s)", opts.Merge, MergeFastForward, MergeSquash)
+	}
+	m, err := s.missionReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+	if m.Branch == "" || m.BaseBranch == "" {
+		return nil, fmt.Errorf("mission %s does not run on its own branch; start it with `m mission start` to merge it back", m.ID)
+	}
+	current, err := s.git.CurrentBranch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("checking branch: %w", err)
+	}
+	if current != m.Branch {
+		return nil, fmt.Errorf("mission branch %s is not checked out (on %s)", m.Branch, branchName(current))
+	}
+
+	if opts.BaseCheckout != nil {
+		base, err := opts.BaseCheckout.CurrentBranch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("checking branch of the main checkout: %w", err)
+		}
+		if base != m.BaseBranch {
+			return nil, fmt.Errorf("the main checkout is on %s; switch it to %s to merge the mission", branchName(base), m.BaseBranch)
+		}
+	}
+
+	if opts.Merge == MergeFastForward {
+		ahead, err := s.git.CommitsBetween(ctx, m.Branch, m.BaseBranch)
+		if err != nil {
+			return nil, fmt.Errorf("comparing %s with %s: %w", m.BaseBranch, m.Branch, err)
+		}
+		if len(ahead) > 0 {
+			return nil, fmt.Errorf("cannot fast-forward %s: it has %d commits that %s lacks; use --merge %s or rebase %s",
+				m.BaseBranch, len(ahead), m.Branch, MergeSquash, m.Branch)
+		}
+	}
+	return m, nil
+}
+
+// mergeBack switches to the base branch of the mission and merges the mission branch
+// into it, or merges in opts.BaseCheckout where the base branch is already checked out
+func (s *Service) mergeBack(ctx context.Context, m *mission.Mission, opts ConsolidateOptions, message string) (string, error) {
+	target := opts.BaseCheckout
+	if target == nil {
+		if err := s.git.SwitchBranch(ctx, m.BaseBranch); err != nil {
+			return "", fmt.Errorf("switching to %s: %w", m.BaseBranch, err)
+		}
+		target = s.git
+	}
+	if opts.Merge == MergeFastForward {
+		return target.MergeFastForward(ctx, m.Branch)
+	}
+	return target.MergeSquash(ctx, m.Branch, message)
+}
+
+// leaveMissionBranch switches a mission started on its own branch back to its base
+// branch once every checkpoint is undone, deletes the mission branch when it holds no
+// other commits, and forgets both branches in the mission frontmatter. A mission in its
+// own worktree stays there, since the base branch is checked out elsewhere.
+func (s *Service) leaveMissionBranch(ctx context.Context, result *RestoreAllResult) error {
+	if s.git == nil {
+		return nil
+	}
+	m, err := s.missionReader.Read()
+	if err != nil {
+		return fmt.Errorf("reading mission: %w", err)
+	}
+	if m.Branch == "" || m.BaseBranch == "" || m.Worktree != "" {
+		return nil
+	}
+	current, err := s.git.CurrentBranch(ctx)
+	if err != nil {
+		return fmt.Errorf("checking branch: %w", err)
+	}
+	if current != m.Branch {
+		return nil
+	}
+
+	if err := s.git.SwitchBranch(ctx, m.BaseBranch); err != nil {
+		return fmt.Errorf("restored the baseline but switching back to %s failed: %w", m.BaseBranch, err)
+	}
+	result.MissionBranch, result.BaseBranch = m.Branch, m.BaseBranch
+	result.DeletedBranch = s.git.DeleteBranch(ctx, m.Branch) == nil
+
+	m.Branch, m.BaseBranch = "", ""
+	if err := mission.NewWriter(s.fs, s.missionPath()).Write(m); err != nil {
+		return fmt.Errorf("updating mission branch: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package checkpoint
+
+import (
+	"testing"
+
+	internalgit "github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/dnatag/mission-toolkit/pkg/mission"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startBranchMission starts a mission scoped to a.txt on its own branch and creates three
+// checkpoints there, the first before any change
+func startBranchMission(t *testing.T, backend string) (afero.Fs, internalgit.GitClient, *Service) {
+	fs, repo := setupTestRepo(t)
+	createMissionFile(t, fs, "test-branch", []string{"a.txt"})
+	gitClient := internalgit.NewMemGitClient(repo, fs)
+	_, err := mission.NewStarter(fs, ".mission/mission.md", gitClient).Start(t.Context())
+	require.NoError(t, err)
+	svc := newTestService(t, fs, gitClient, backend)
+
+	_, err = svc.Create(t.Context(), "test-branch")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a1"), 0644))
+	_, err = svc.Create(t.Context(), "test-branch")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a2"), 0644))
+	_, err = svc.Create(t.Context(), "test-branch")
+	require.NoError(t, err)
+	return fs, gitClient, svc
+}
+
+func currentBranch(t *testing.T, gitClient internalgit.GitClient) string {
+	branch, err := gitClient.CurrentBranch(t.Context())
+	require.NoError(t, err)
+	return branch
+}
+
+func TestService_Consolidate_MergeFastForward(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		_, gitClient, svc := startBranchMission(t, backend)
+		base, err := gitClient.GetTagCommit(t.Context(), "master")
+		require.NoError(t, err)
+
+		result, err := svc.ConsolidateWithOptions(t.Context(), "test-branch", "feat: branch", ConsolidateOptions{Merge: MergeFastForward})
+		require.NoError(t, err)
+		assert.Equal(t, "master", result.MergedInto)
+		assert.Equal(t, result.CommitHash, result.MergeHash)
+		assert.Equal(t, "master", currentBranch(t, gitClient))
+
+		commits, err := gitClient.CommitsBetween(t.Context(), base, "HEAD")
+		require.NoError(t, err)
+		require.Len(t, commits, 1, "the checkpoints are squashed into one commit")
+		assert.Equal(t, "feat: branch", commits[0].Subject())
+	})
+}
+
+func TestService_Consolidate_MergeSquash(t *testing.T) {
+	fs, gitClient, svc := startBranchMission(t, BackendTags)
+
+	// master moves on while the mission runs
+	require.NoError(t, gitClient.SwitchBranch(t.Context(), "master"))
+	require.NoError(t, afero.WriteFile(fs, "other.txt", []byte("other"), 0644))
+	require.NoError(t, gitClient.Add(t.Context(), []string{"other.txt"}))
+	other, err := gitClient.Commit(t.Context(), "fix: other")
+	require.NoError(t, err)
+	require.NoError(t, gitClient.SwitchBranch(t.Context(), "mission/test-branch"))
+
+	_, err = svc.ConsolidateWithOptions(t.Context(), "test-branch", "feat: branch", ConsolidateOptions{Merge: MergeFastForward})
+	assert.ErrorContains(t, err, "cannot fast-forward master: it has 1 commits that mission/test-branch lacks")
+	assert.True(t, checkpointExists(svc, "test-branch-2"), "nothing is committed when the fast-forward is impossible")
+
+	result, err := svc.ConsolidateWithOptions(t.Context(), "test-branch", "feat: branch", ConsolidateOptions{Merge: MergeSquash})
+	require.NoError(t, err)
+	assert.Equal(t, "master", currentBranch(t, gitClient))
+	commits, err := gitClient.CommitsBetween(t.Context(), other, "HEAD")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, result.MergeHash, commits[0].Hash)
+	assert.Equal(t, "feat: branch", commits[0].Subject())
+	assert.Equal(t, []string{"a.txt"}, commits[0].Files)
+}
+
+func TestService_Consolidate_MergeNeedsMissionBranch(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	createMissionFile(t, fs, "test-branch", []string{"a.txt"})
+	svc := newTestService(t, fs, internalgit.NewMemGitClient(repo, fs), BackendTags)
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a1"), 0644))
+	_, err := svc.Create(t.Context(), "test-branch")
+	require.NoError(t, err)
+
+	_, err = svc.ConsolidateWithOptions(t.Context(), "test-branch", "feat: branch", ConsolidateOptions{Merge: MergeFastForward})
+	assert.ErrorContains(t, err, "mission test-branch does not run on its own branch")
+	_, err = svc.ConsolidateWithOptions(t.Context(), "test-branch", "feat: branch", ConsolidateOptions{Merge: "rebase"})
+	assert.ErrorContains(t, err, `unknown merge mode "rebase"`)
+	assert.True(t, checkpointExists(svc, "test-branch-1"), "nothing is committed")
+}
+
+func TestService_RestoreAll_LeavesMissionBranch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, gitClient, svc := startBranchMission(t, backend)
+
+		result, err := svc.RestoreAllWithOptions(t.Context(), "test-branch", RestoreAllOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "mission/test-branch", result.MissionBranch)
+		assert.Equal(t, "master", result.BaseBranch)
+		assert.True(t, result.DeletedBranch)
+		assert.Equal(t, "master", currentBranch(t, gitClient))
+		_, err = gitClient.GetTagCommit(t.Context(), "mission/test-branch")
+		assert.Error(t, err, "the mission branch is deleted")
+
+		m, err := mission.NewReader(fs, ".mission/mission.md").Read()
+		require.NoError(t, err)
+		assert.Empty(t, m.Branch)
+		assert.Empty(t, m.BaseBranch)
+	})
+}
@@ -0,0 +1,135 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/dnatag/mission-toolkit/pkg/mission"
+)
+
+// Names accepted by Diff besides checkpoint names and numbers
+const (
+	// RefBaseline is the state the mission started from
+	RefBaseline = "baseline"
+	// RefLatest is the most recent numbered checkpoint
+	RefLatest = "latest"
+	// RefWorking is the working tree, including untracked files
+	RefWorking = "working"
+)
+
+// DiffOptions controls which files Diff reports
+type DiffOptions struct {
+	// All includes files outside the mission scope
+	All bool
+}
+
+// DiffResult is the change between two checkpoints, or a checkpoint and the working tree
+type DiffResult struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Scoped    bool           `json:"scoped"`
+	Files     []git.FileDiff `json:"files"`
+	Additions int            `json:"additions"`
+	Deletions int            `json:"deletions"`
+}
+
+// Diff compares two checkpoints of a mission. from and to may be a checkpoint name, a
+// checkpoint number, baseline, latest or (for to only) working; they default to
+// baseline and working. Unless opts.All is set only files in the mission scope are reported.
+func (s *Service) Diff(ctx context.Context, missionID, from, to string, opts DiffOptions) (*DiffResult, error) {
+	if from == "" {
+		from = RefBaseline
+	}
+	if to == "" {
+		to = RefWorking
+	}
+	if from == RefWorking {
+		return nil, fmt.Errorf("%s can only be the second checkpoint of a diff", RefWorking)
+	}
+
+	fromName, fromRef, err := s.resolveDiffRef(ctx, missionID, from)
+	if err != nil {
+		return nil, err
+	}
+	toName, toRef, err := s.resolveDiffRef(ctx, missionID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var scope *mission.Scope
+	if !opts.All {
+		m, err := s.missionReader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading mission: %w", err)
+		}
+		scope = mission.NewScope(s.fs, m.GetScope())
+	}
+
+	diffs, err := s.store.diff(ctx, fromRef, toRef, nil)
+	if err != nil {
+		return nil, fmt.Errorf("diffing %s and %s: %w", fromName, toName, err)
+	}
+
+	result := &DiffResult{From: fromName, To: toName, Scoped: scope != nil, Files: []git.FileDiff{}}
+	for _, d := range diffs {
+		if scope != nil && !scope.Match(d.Path) {
+			continue
+		}
+		result.Files = append(result.Files, d)
+		result.Additions += d.Additions
+		result.Deletions += d.Deletions
+	}
+	return result, nil
+}
+
+// BaselineChanges lists the files changed in the working tree since the baseline of a
+// mission and returns the name of the baseline. Before the first checkpoint there is
+// no baseline and it returns an empty name and no changes.
+func (s *Service) BaselineChanges(ctx context.Context, missionID string) (string, []git.FileChange, error) {
+	names, err := s.store.names(ctx, missionID)
+	if err != nil {
+		return "", nil, fmt.Errorf("listing checkpoints: %w", err)
+	}
+	name := missionID + "-baseline"
+	if !slices.Contains(names, name) {
+		return "", nil, nil
+	}
+	changes, err := s.store.changes(ctx, s.store.ref(name))
+	if err != nil {
+		return "", nil, fmt.Errorf("listing changes since %s: %w", name, err)
+	}
+	return name, changes, nil
+}
+
+// resolveDiffRef returns the display name and store ref of a Diff argument. The working
+// tree has an empty ref.
+func (s *Service) resolveDiffRef(ctx context.Context, missionID, name string) (string, string, error) {
+	switch name {
+	case RefWorking:
+		return RefWorking, "", nil
+	case RefBaseline:
+		name = missionID + "-baseline"
+	case RefLatest:
+		num, err := s.getNextCheckpointNumber(ctx, missionID)
+		if err != nil {
+			return "", "", fmt.Errorf("getting latest checkpoint: %w", err)
+		}
+		if num == 1 {
+			return "", "", fmt.Errorf("mission %s has no checkpoints", missionID)
+		}
+		name = fmt.Sprintf("%s-%d", missionID, num-1)
+	default:
+		if _, err := strconv.Atoi(name); err == nil {
+			name = fmt.Sprintf("%s-%s", missionID, name)
+		}
+	}
+
+	ref := s.store.ref(name)
+	if _, err := s.store.resolve(ctx, ref); err != nil {
+		return "", "", fmt.Errorf("checkpoint %s not found: %w", name, err)
+	}
+	return name, ref, nil
+}
@@ -0,0 +1,119 @@
+package checkpoint
+
+import (
+	"testing"
+
+	internalgit "github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Diff(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+		require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# Test"), 0644))
+
+		missionID := "test-diff"
+		createMissionFile(t, fs, missionID, []string{"a.txt", "b.txt"})
+		svc := newTestService(t, fs, internalgit.NewMemGitClient(repo, fs), backend)
+
+		_, err := svc.Diff(t.Context(), missionID, "", "", DiffOptions{})
+		assert.ErrorContains(t, err, "test-diff-baseline not found")
+		_, err = svc.Diff(t.Context(), missionID, "latest", "", DiffOptions{})
+		assert.ErrorContains(t, err, "has no checkpoints")
+
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a\n"), 0644))
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, "b.txt", []byte("b\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "other.txt", []byte("out of scope\n"), 0644))
+
+		t.Run("defaults to baseline against the working tree", func(t *testing.T) {
+			result, err := svc.Diff(t.Context(), missionID, "", "", DiffOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "test-diff-baseline", result.From)
+			assert.Equal(t, RefWorking, result.To)
+			assert.True(t, result.Scoped)
+			require.Len(t, result.Files, 2, "files outside the scope are left out")
+			assert.Equal(t, "a.txt", result.Files[0].Path)
+			assert.Equal(t, "b.txt", result.Files[1].Path)
+			assert.Contains(t, result.Files[1].Patch, "+b")
+			assert.Equal(t, 2, result.Additions)
+		})
+
+		t.Run("checkpoint numbers and latest", func(t *testing.T) {
+			result, err := svc.Diff(t.Context(), missionID, "1", "latest", DiffOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "test-diff-1", result.From)
+			assert.Equal(t, "test-diff-2", result.To)
+			require.Len(t, result.Files, 1)
+			assert.Equal(t, internalgit.ChangeAdded, result.Files[0].Change)
+
+			result, err = svc.Diff(t.Context(), missionID, "test-diff-2", "working", DiffOptions{})
+			require.NoError(t, err)
+			require.Len(t, result.Files, 1)
+			assert.Equal(t, "b.txt", result.Files[0].Path)
+		})
+
+		t.Run("all files", func(t *testing.T) {
+			result, err := svc.Diff(t.Context(), missionID, "latest", "", DiffOptions{All: true})
+			require.NoError(t, err)
+			assert.False(t, result.Scoped)
+			require.Len(t, result.Files, 2)
+			assert.Equal(t, "other.txt", result.Files[1].Path)
+		})
+
+		t.Run("invalid references", func(t *testing.T) {
+			_, err := svc.Diff(t.Context(), missionID, "working", "latest", DiffOptions{})
+			assert.Error(t, err)
+			_, err = svc.Diff(t.Context(), missionID, "7", "", DiffOptions{})
+			assert.ErrorContains(t, err, "checkpoint test-diff-7 not found")
+		})
+	})
+}
+
+func TestService_BaselineChanges(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+		require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# Test"), 0644))
+
+		missionID := "test-baseline"
+		createMissionFile(t, fs, missionID, []string{"a.txt"})
+		svc := newTestService(t, fs, internalgit.NewMemGitClient(repo, fs), backend)
+
+		base, changes, err := svc.BaselineChanges(t.Context(), missionID)
+		require.NoError(t, err)
+		assert.Empty(t, base, "no baseline before the first checkpoint")
+		assert.Empty(t, changes)
+
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "other.txt", []byte("out of scope\n"), 0644))
+
+		base, changes, err = svc.BaselineChanges(t.Context(), missionID)
+		require.NoError(t, err)
+		assert.Equal(t, "test-baseline-baseline", base)
+		assert.Contains(t, changes, internalgit.FileChange{Path: "a.txt", Change: internalgit.ChangeAdded})
+		assert.Contains(t, changes, internalgit.FileChange{Path: "other.txt", Change: internalgit.ChangeAdded})
+	})
+
+	t.Run(BackendFS, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		missionID := "test-baseline"
+		svc := newFSTestService(t, fs, missionID, []string{"a.txt"})
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a1\n"), 0644))
+
+		_, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a2\n"), 0644))
+
+		base, changes, err := svc.BaselineChanges(t.Context(), missionID)
+		require.NoError(t, err)
+		assert.Equal(t, "test-baseline-baseline", base)
+		assert.Equal(t, []internalgit.FileChange{{Path: "a.txt", Change: internalgit.ChangeModified}}, changes)
+	})
+}
@@ -0,0 +1,550 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/dnatag/mission-toolkit/pkg/mission"
+	"github.com/spf13/afero"
+)
+
+// snapshotsDir holds the fs backend's objects and refs in the shared mission root
+const snapshotsDir = "snapshots"
+
+// snapshotManifest is the tree of an fs snapshot: every file it holds and its blob
+type snapshotManifest struct {
+	Files map[string]snapshotFile `json:"files"`
+}
+
+// snapshotFile is a file of an fs snapshot
+type snapshotFile struct {
+	Blob string      `json:"blob"`
+	Mode os.FileMode `json:"mode"`
+}
+
+// fsStore keeps checkpoints without git. File contents and manifests are stored as
+// content-addressed objects under snapshots/objects, and snapshots/refs/<id>/<n> holds
+// the manifest hash of each checkpoint. Like the refs backend, each snapshot builds on
+// the previous checkpoint, so files that left the scope are still restorable.
+type fsStore struct {
+	s *Service
+}
+
+func (f *fsStore) ref(name string) string {
+	return name
+}
+
+func (f *fsStore) names(ctx context.Context, missionID string) ([]string, error) {
+	infos, err := afero.ReadDir(f.s.fs, filepath.Join(f.dir(), "refs", missionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() {
+			names = append(names, missionID+"-"+info.Name())
+		}
+	}
+	return names, nil
+}
+
+func (f *fsStore) create(ctx context.Context, missionID string, num int) (string, error) {
+	parent := ""
+	if num > 1 {
+		previous := fmt.Sprintf("%s-%d", missionID, num-1)
+		if _, err := f.resolve(ctx, previous); err == nil {
+			parent = previous
+		}
+	}
+
+	snapshot := &snapshotManifest{Files: make(map[string]snapshotFile)}
+	if parent != "" {
+		var err error
+		if snapshot, err = f.load(ctx, parent); err != nil {
+			return "", err
+		}
+	}
+
+	files, err := f.s.getScope(ctx, parent)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		info, err := f.s.fs.Stat(file)
+		if err != nil || !info.Mode().IsRegular() {
+			delete(snapshot.Files, file)
+			continue
+		}
+		content, err := afero.ReadFile(f.s.fs, file)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", file, err)
+		}
+		blob, err := f.writeObject(content)
+		if err != nil {
+			return "", fmt.Errorf("storing %s: %w", file, err)
+		}
+		snapshot.Files[file] = snapshotFile{Blob: blob, Mode: info.Mode().Perm()}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("encoding snapshot: %w", err)
+	}
+	hash, err := f.writeObject(data)
+	if err != nil {
+		return "", fmt.Errorf("storing snapshot: %w", err)
+	}
+	if err := f.mark(ctx, fmt.Sprintf("%s-%d", missionID, num), hash); err != nil {
+		return "", fmt.Errorf("creating checkpoint ref: %w", err)
+	}
+	return hash, nil
+}
+
+func (f *fsStore) mark(ctx context.Context, name, hash string) error {
+	refPath, ok := f.refPath(name)
+	if !ok {
+		return fmt.Errorf("invalid checkpoint name %q", name)
+	}
+	if err := f.s.fs.MkdirAll(filepath.Dir(refPath), 0755); err != nil {
+		return err
+	}
+	return afero.WriteFile(f.s.fs, refPath, []byte(hash+"\n"), 0644)
+}
+
+// remove deletes the ref of checkpoint name and then every object no remaining ref
+// reaches, so clearing a mission frees its snapshots
+func (f *fsStore) remove(ctx context.Context, name string) error {
+	refPath, ok := f.refPath(name)
+	if !ok {
+		return fmt.Errorf("invalid checkpoint name %q", name)
+	}
+	if err := f.s.fs.Remove(refPath); err != nil {
+		return err
+	}
+	if infos, err := afero.ReadDir(f.s.fs, filepath.Dir(refPath)); err == nil && len(infos) == 0 {
+		if err := f.s.fs.Remove(filepath.Dir(refPath)); err != nil {
+			return err
+		}
+	}
+	return f.prune(ctx)
+}
+
+func (f *fsStore) onBranch() bool {
+	return false
+}
+
+// resolve accepts a checkpoint name or a manifest hash
+func (f *fsStore) resolve(ctx context.Context, rev string) (string, error) {
+	hash := rev
+	if refPath, ok := f.refPath(rev); ok {
+		if data, err := afero.ReadFile(f.s.fs, refPath); err == nil {
+			hash = strings.TrimSpace(string(data))
+		}
+	}
+	if !isObjectHash(hash) {
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+	if exists, _ := afero.Exists(f.s.fs, f.objectPath(hash)); !exists {
+		return "", fmt.Errorf("snapshot %s is missing", hash)
+	}
+	return hash, nil
+}
+
+func (f *fsStore) createdAt(ctx context.Context, hash string) (time.Time, error) {
+	return time.Time{}, fmt.Errorf("snapshots do not record when they were taken")
+}
+
+// since compares the first checkpoint with itself, as nothing before it was recorded
+func (f *fsStore) since(ctx context.Context, hash string) string {
+	return hash
+}
+
+func (f *fsStore) deleted(ctx context.Context, rev string) ([]string, error) {
+	if rev == "" {
+		return nil, nil
+	}
+	snapshot, err := f.load(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for file := range snapshot.Files {
+		if exists, _ := afero.Exists(f.s.fs, file); !exists {
+			deleted = append(deleted, file)
+		}
+	}
+	sort.Strings(deleted)
+	return deleted, nil
+}
+
+func (f *fsStore) restore(ctx context.Context, rev string, files []string) error {
+	snapshot, err := f.load(ctx, rev)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		entry, ok := snapshot.Files[file]
+		if !ok {
+			continue
+		}
+		content, err := f.readObject(entry.Blob)
+		if err != nil {
+			return fmt.Errorf("reading %s from snapshot: %w", file, err)
+		}
+		if err := f.s.fs.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("restoring %s: %w", file, err)
+		}
+		if err := afero.WriteFile(f.s.fs, file, content, entry.Mode); err != nil {
+			return fmt.Errorf("restoring %s: %w", file, err)
+		}
+		if err := f.s.fs.Chmod(file, entry.Mode); err != nil {
+			return fmt.Errorf("restoring %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// diff compares two snapshots, or a snapshot and the working tree. Only files in the
+// snapshot or the mission scope are known, so files outside both are never reported.
+func (f *fsStore) diff(ctx context.Context, from, to string, paths []string) ([]git.FileDiff, error) {
+	fromFiles, err := f.contents(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	var toFiles map[string][]byte
+	if to == "" {
+		toFiles, err = f.working(ctx, from)
+	} else {
+		toFiles, err = f.contents(ctx, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for file := range fromFiles {
+		files = append(files, file)
+	}
+	for file := range toFiles {
+		if _, ok := fromFiles[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	var diffs []git.FileDiff
+	for _, file := range files {
+		if !underPaths(file, paths) {
+			continue
+		}
+		before, after := fromFiles[file], toFiles[file]
+		if before != nil && after != nil && string(before) == string(after) {
+			continue
+		}
+		d, err := git.DiffContent(file, before, after)
+		if err != nil {
+			return nil, fmt.Errorf("diffing %s: %w", file, err)
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+func (f *fsStore) diffStat(ctx context.Context, from, to string) ([]git.FileStat, error) {
+	diffs, err := f.diff(ctx, from, to, nil)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]git.FileStat, 0, len(diffs))
+	for _, d := range diffs {
+		stats = append(stats, d.FileStat)
+	}
+	return stats, nil
+}
+
+// changes compares the snapshot files and the scope with rev. Snapshots hold nothing
+// else, so files outside them are compared with HEAD when there is a git repository.
+func (f *fsStore) changes(ctx context.Context, rev string) ([]git.FileChange, error) {
+	diffs, err := f.diff(ctx, rev, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]git.FileChange, 0, len(diffs))
+	for _, d := range diffs {
+		changes = append(changes, git.FileChange{Path: d.Path, Change: d.Change})
+	}
+	if f.s.git == nil {
+		return changes, nil
+	}
+
+	snapshot, err := f.load(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := f.s.getScope(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	covered := make(map[string]bool, len(snapshot.Files)+len(scope))
+	for file := range snapshot.Files {
+		covered[file] = true
+	}
+	for _, file := range scope {
+		covered[file] = true
+	}
+
+	head, err := f.s.git.GetChangedFiles(ctx, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range head {
+		if !covered[change.Path] {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// created lists the scope files on disk that the baseline does not hold
+func (f *fsStore) created(ctx context.Context, baseline string) ([]string, error) {
+	snapshot, err := f.load(ctx, baseline)
+	if err != nil {
+		return nil, err
+	}
+	files, err := f.s.getScope(ctx, baseline)
+	if err != nil {
+		return nil, err
+	}
+	var created []string
+	for _, file := range files {
+		if _, ok := snapshot.Files[file]; ok {
+			continue
+		}
+		if exists, _ := afero.Exists(f.s.fs, file); exists {
+			created = append(created, file)
+		}
+	}
+	return created, nil
+}
+
+// contents reads every file of the snapshot at rev
+func (f *fsStore) contents(ctx context.Context, rev string) (map[string][]byte, error) {
+	snapshot, err := f.load(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[string][]byte, len(snapshot.Files))
+	for file, entry := range snapshot.Files {
+		if contents[file], err = f.readObject(entry.Blob); err != nil {
+			return nil, fmt.Errorf("reading %s from snapshot: %w", file, err)
+		}
+	}
+	return contents, nil
+}
+
+// working reads the files of the snapshot at rev and the scope from the working tree.
+// Missing files are left out.
+func (f *fsStore) working(ctx context.Context, rev string) (map[string][]byte, error) {
+	snapshot, err := f.load(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	files, err := f.s.getScope(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	for file := range snapshot.Files {
+		files = append(files, file)
+	}
+
+	contents := make(map[string][]byte)
+	for _, file := range files {
+		info, err := f.s.fs.Stat(file)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		content, err := afero.ReadFile(f.s.fs, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		if content == nil {
+			content = []byte{}
+		}
+		contents[file] = content
+	}
+	return contents, nil
+}
+
+// load reads the manifest of the snapshot at rev
+func (f *fsStore) load(ctx context.Context, rev string) (*snapshotManifest, error) {
+	hash, err := f.resolve(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	data, err := f.readObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot %s: %w", hash, err)
+	}
+	snapshot := &snapshotManifest{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", hash, err)
+	}
+	if snapshot.Files == nil {
+		snapshot.Files = make(map[string]snapshotFile)
+	}
+	return snapshot, nil
+}
+
+// prune removes the objects no ref reaches, or the whole snapshot directory once no
+// refs are left
+func (f *fsStore) prune(ctx context.Context) error {
+	refPaths, err := f.walkFiles(filepath.Join(f.dir(), "refs"))
+	if err != nil {
+		return err
+	}
+	if len(refPaths) == 0 {
+		return f.s.fs.RemoveAll(f.dir())
+	}
+
+	reachable := make(map[string]bool)
+	for _, refPath := range refPaths {
+		data, err := afero.ReadFile(f.s.fs, refPath)
+		if err != nil {
+			return err
+		}
+		hash := strings.TrimSpace(string(data))
+		if reachable[hash] {
+			continue
+		}
+		reachable[hash] = true
+		snapshot, err := f.load(ctx, hash)
+		if err != nil {
+			return err
+		}
+		for _, entry := range snapshot.Files {
+			reachable[entry.Blob] = true
+		}
+	}
+
+	objectsDir := filepath.Join(f.dir(), "objects")
+	objectPaths, err := f.walkFiles(objectsDir)
+	if err != nil {
+		return err
+	}
+	for _, objectPath := range objectPaths {
+		rel, err := filepath.Rel(objectsDir, objectPath)
+		if err != nil {
+			return err
+		}
+		if !reachable[strings.ReplaceAll(filepath.ToSlash(rel), "/", "")] {
+			if err := f.s.fs.Remove(objectPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkFiles lists the files below dir, which may not exist
+func (f *fsStore) walkFiles(dir string) ([]string, error) {
+	var files []string
+	err := afero.Walk(f.s.fs, dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// writeObject stores content under its hash and returns the hash
+func (f *fsStore) writeObject(content []byte) (string, error) {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	objectPath := f.objectPath(hash)
+	if exists, _ := afero.Exists(f.s.fs, objectPath); exists {
+		return hash, nil
+	}
+	if err := f.s.fs.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+		return "", err
+	}
+	if err := afero.WriteFile(f.s.fs, objectPath, content, 0644); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// readObject returns the content stored under hash
+func (f *fsStore) readObject(hash string) ([]byte, error) {
+	content, err := afero.ReadFile(f.s.fs, f.objectPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		content = []byte{}
+	}
+	return content, nil
+}
+
+// dir returns the snapshot directory in the shared mission root
+func (f *fsStore) dir() string {
+	return filepath.Join(mission.NewBaseService(f.s.fs, f.s.missionDir).RootDir(), snapshotsDir)
+}
+
+// objectPath fans objects out over directories named by the first two hash digits
+func (f *fsStore) objectPath(hash string) string {
+	return filepath.Join(f.dir(), "objects", hash[:2], hash[2:])
+}
+
+// refPath returns the file holding the snapshot hash of checkpoint name <id>-<n>. Names
+// that are not checkpoint names have no ref.
+func (f *fsStore) refPath(name string) (string, bool) {
+	idx := strings.LastIndex(name, "-")
+	if idx <= 0 || idx == len(name)-1 || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return "", false
+	}
+	return filepath.Join(f.dir(), "refs", name[:idx], name[idx+1:]), true
+}
+
+// isObjectHash reports whether s is a hex SHA-256 hash
+func isObjectHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// underPaths reports whether file is one of paths or lies below one of them. An empty
+// list matches every file.
+func underPaths(file string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		p = strings.TrimSuffix(path.Clean(p), "/")
+		if p == "." || p == file || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
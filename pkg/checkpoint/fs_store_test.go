@@ -0,0 +1,164 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	internalgit "github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFSTestService creates a checkpoint service without git, which picks the fs backend
+func newFSTestService(t *testing.T, fs afero.Fs, missionID string, scope []string) *Service {
+	createMissionFile(t, fs, missionID, scope)
+	svc, err := NewServiceWithBackend(fs, ".mission", nil, "")
+	require.NoError(t, err)
+	require.IsType(t, &fsStore{}, svc.store)
+	return svc
+}
+
+func TestNewServiceWithBackend_GitBackendWithoutGit(t *testing.T) {
+	_, err := NewServiceWithBackend(afero.NewMemMapFs(), ".mission", nil, BackendRefs)
+	assert.ErrorContains(t, err, "checkpoint backend refs needs a git repository")
+}
+
+func TestFSStore_CreateAndRestore(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionID := "test-fs"
+	svc := newFSTestService(t, fs, missionID, []string{"a.txt", "src/**/*.go"})
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a1\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "src/main.go", []byte("package main\n"), 0755))
+	first, err := svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+	assert.Equal(t, "test-fs-1", first)
+	assert.True(t, checkpointExists(svc, "test-fs-baseline"))
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a2\n"), 0644))
+	require.NoError(t, fs.Remove("src/main.go"))
+	second, err := svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+
+	// Identical content is stored once
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a1\n"), 0644))
+	third, err := svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+	firstHash, err := svc.store.resolve(t.Context(), first)
+	require.NoError(t, err)
+	thirdHash, err := svc.store.resolve(t.Context(), third)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstHash, thirdHash, "the third snapshot no longer holds src/main.go")
+	blobs, err := svc.store.(*fsStore).walkFiles(".mission/snapshots/objects")
+	require.NoError(t, err)
+	assert.Len(t, blobs, 6, "two a.txt versions, main.go and three manifests")
+
+	require.NoError(t, svc.Restore(t.Context(), second))
+	content, err := afero.ReadFile(fs, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a2\n", string(content))
+
+	require.NoError(t, svc.Restore(t.Context(), first))
+	content, err = afero.ReadFile(fs, "src/main.go")
+	require.NoError(t, err, "deleted files are restored")
+	assert.Equal(t, "package main\n", string(content))
+	info, err := fs.Stat("src/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "-rwxr-xr-x", info.Mode().String())
+
+	err = svc.Restore(t.Context(), "test-fs-9")
+	assert.ErrorContains(t, err, "checkpoint test-fs-9 not found")
+}
+
+func TestFSStore_ListAndDiff(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionID := "test-fs-list"
+	svc := newFSTestService(t, fs, missionID, []string{"a.txt", "b.txt"})
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a\n"), 0644))
+	_, err := svc.CreateWithLabel(t.Context(), missionID, "start")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a\nmore\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "b.txt", []byte("b\n"), 0644))
+	_, err = svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+
+	checkpoints, err := svc.List(t.Context(), missionID)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	assert.Equal(t, "start", checkpoints[0].Label)
+	assert.Empty(t, checkpoints[0].Files, "nothing was recorded before the first snapshot")
+	assert.Equal(t, "test-fs-list-1", checkpoints[1].Since)
+	require.Len(t, checkpoints[1].Files, 2)
+	assert.Equal(t, internalgit.FileStat{Path: "a.txt", Change: internalgit.ChangeModified, Additions: 1}, checkpoints[1].Files[0])
+	assert.Equal(t, internalgit.ChangeAdded, checkpoints[1].Files[1].Change)
+
+	require.NoError(t, fs.Remove("b.txt"))
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("changed\n"), 0644))
+	result, err := svc.Diff(t.Context(), missionID, "latest", "", DiffOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 2)
+	assert.Equal(t, internalgit.ChangeModified, result.Files[0].Change)
+	assert.Contains(t, result.Files[0].Patch, "+changed")
+	assert.Equal(t, internalgit.ChangeDeleted, result.Files[1].Change)
+	assert.Equal(t, 1, result.Additions)
+	assert.Equal(t, 3, result.Deletions)
+
+	result, err = svc.Diff(t.Context(), missionID, "baseline", "latest", DiffOptions{})
+	require.NoError(t, err)
+	assert.Len(t, result.Files, 2)
+}
+
+func TestFSStore_RestoreAll(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionID := "test-fs-all"
+	svc := newFSTestService(t, fs, missionID, []string{"a.txt", "new.txt"})
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("before\n"), 0644))
+	_, err := svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("after\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "new.txt", []byte("new\n"), 0644))
+	_, err = svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+
+	result, err := svc.RestoreAllWithOptions(t.Context(), missionID, RestoreAllOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Cleared)
+	assert.Equal(t, []string{"new.txt"}, result.Removed)
+	assert.Empty(t, result.Untracked)
+	content, err := afero.ReadFile(fs, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", string(content))
+	exists, err := afero.Exists(fs, "new.txt")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Clearing the last checkpoint removes the snapshots with it
+	exists, err = afero.Exists(fs, filepath.Join(".mission", snapshotsDir))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestFSStore_RemovePrunesUnreachableObjects(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	svc := newFSTestService(t, fs, "first", []string{"a.txt"})
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("shared\n"), 0644))
+	_, err := svc.Create(t.Context(), "first")
+	require.NoError(t, err)
+	_, err = svc.Create(t.Context(), "second")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("only first\n"), 0644))
+	_, err = svc.Create(t.Context(), "first")
+	require.NoError(t, err)
+
+	_, err = svc.Clear(t.Context(), "first")
+	require.NoError(t, err)
+
+	st := svc.store.(*fsStore)
+	objects, err := st.walkFiles(filepath.Join(st.dir(), "objects"))
+	require.NoError(t, err)
+	assert.Len(t, objects, 2, "the blob and manifest the second mission still uses")
+	require.NoError(t, svc.Restore(t.Context(), "second-1"))
+}
@@ -0,0 +1,223 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/mission"
+)
+
+// checkpointTagPattern matches checkpoint names, <id>-N and <id>-baseline
+var checkpointTagPattern = regexp.MustCompile(`^(.+)-([0-9]+|baseline)$`)
+
+// Orphan is a checkpoint left behind by a mission that is no longer active, paused or
+// recently archived, such as an abandoned plan or a deleted .mission directory
+type Orphan struct {
+	MissionID string `json:"mission_id"`
+	Name      string `json:"name"`
+	// Ref is the tag or ref holding the checkpoint
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+}
+
+// GCOptions controls which checkpoints GC removes
+type GCOptions struct {
+	// ArchivedSince keeps the checkpoints of missions archived at or after this time
+	ArchivedSince time.Time
+	// DryRun only reports the orphaned checkpoints
+	DryRun bool
+}
+
+// GCResult lists the orphaned checkpoints GC found, removed unless DryRun was set
+type GCResult struct {
+	Orphans  []Orphan `json:"orphans"`
+	Missions []string `json:"missions"`
+	DryRun   bool     `json:"dry_run"`
+}
+
+// Orphans finds the checkpoints in either git backend whose mission is not in the
+// workspace, paused or archived at or after archivedSince
+func (s *Service) Orphans(ctx context.Context, archivedSince time.Time) ([]Orphan, error) {
+	if s.git == nil {
+		return nil, fmt.Errorf("finding orphaned checkpoints needs a git repository")
+	}
+	rootDir := mission.NewBaseService(s.fs, s.missionDir).RootDir()
+	live, err := mission.LiveMissionIDs(ctx, s.fs, rootDir, s.git, archivedSince)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged, err := s.checkpointTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.checkpointRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []Orphan
+	for _, candidate := range append(tagged, snapshots...) {
+		if live[candidate.MissionID] {
+			continue
+		}
+		if candidate.Commit, err = s.git.GetTagCommit(ctx, candidate.Ref); err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", candidate.Ref, err)
+		}
+		orphans = append(orphans, candidate)
+	}
+	sort.SliceStable(orphans, func(i, j int) bool { return orphans[i].MissionID < orphans[j].MissionID })
+	return orphans, nil
+}
+
+// StaleMissions lists the missions that checkpoint metadata still records checkpoints
+// for but that are not in the workspace, paused or archived at or after archivedSince.
+// It reads checkpoints.json instead of every tag, ref and commit, so it is cheap enough
+// to run before each plan; Orphans also finds checkpoints whose metadata is gone.
+func (s *Service) StaleMissions(ctx context.Context, archivedSince time.Time) ([]string, error) {
+	metadata, err := s.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for name := range metadata {
+		if match := checkpointTagPattern.FindStringSubmatch(name); match != nil {
+			ids[match[1]] = true
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rootDir := mission.NewBaseService(s.fs, s.missionDir).RootDir()
+	live, err := mission.LiveMissionIDs(ctx, s.fs, rootDir, s.git, archivedSince)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for id := range ids {
+		if !live[id] {
+			stale = append(stale, id)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// GC deletes the orphaned checkpoints and their metadata
+func (s *Service) GC(ctx context.Context, opts GCOptions) (*GCResult, error) {
+	orphans, err := s.Orphans(ctx, opts.ArchivedSince)
+	if err != nil {
+		return nil, err
+	}
+	result := &GCResult{Orphans: orphans, Missions: []string{}, DryRun: opts.DryRun}
+	for _, orphan := range orphans {
+		if n := len(result.Missions); n == 0 || result.Missions[n-1] != orphan.MissionID {
+			result.Missions = append(result.Missions, orphan.MissionID)
+		}
+	}
+	if opts.DryRun || len(orphans) == 0 {
+		return result, nil
+	}
+
+	for _, orphan := range orphans {
+		if strings.HasPrefix(orphan.Ref, missionRefPrefix) {
+			err = s.git.DeleteRef(ctx, orphan.Ref)
+		} else {
+			err = s.git.DeleteTag(ctx, orphan.Ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("deleting checkpoint %s: %w", orphan.Ref, err)
+		}
+	}
+	err = s.updateMetadata(func(m map[string]Metadata) {
+		for _, orphan := range orphans {
+			delete(m, orphan.Name)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkpointTags returns the checkpoints of the tags backend. Tags are grouped by
+// mission, and a group only counts once one of its tags points at the checkpoint
+// commit it names or has metadata, so release tags such as v1-2 are left alone.
+func (s *Service) checkpointTags(ctx context.Context) ([]Orphan, error) {
+	tags, err := s.git.ListTags(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+	metadata, err := s.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	byMission := make(map[string][]string)
+	for _, tag := range tags {
+		if m := checkpointTagPattern.FindStringSubmatch(tag); m != nil {
+			byMission[m[1]] = append(byMission[m[1]], tag)
+		}
+	}
+
+	var checkpoints []Orphan
+	for missionID, names := range byMission {
+		if !s.isCheckpointGroup(ctx, names, metadata) {
+			continue
+		}
+		for _, name := range names {
+			checkpoints = append(checkpoints, Orphan{MissionID: missionID, Name: name, Ref: name})
+		}
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Ref < checkpoints[j].Ref })
+	return checkpoints, nil
+}
+
+// isCheckpointGroup reports whether tags named like checkpoints of one mission were
+// created by m checkpoint
+func (s *Service) isCheckpointGroup(ctx context.Context, names []string, metadata map[string]Metadata) bool {
+	for _, name := range names {
+		if _, ok := metadata[name]; ok {
+			return true
+		}
+		commit, err := s.git.GetTagCommit(ctx, name)
+		if err != nil {
+			continue
+		}
+		message, err := s.git.GetCommitMessage(ctx, commit)
+		if err == nil && strings.TrimSpace(message) == "checkpoint: "+name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkpointRefs returns the checkpoints of the refs backend, refs/mission/<id>/<n>
+// and refs/mission/<id>/baseline, leaving the stashes of paused missions alone
+func (s *Service) checkpointRefs(ctx context.Context) ([]Orphan, error) {
+	refs, err := s.git.ListRefs(ctx, missionRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing refs: %w", err)
+	}
+	var checkpoints []Orphan
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, mission.PausedStashRefPrefix) {
+			continue
+		}
+		missionID, suffix, ok := strings.Cut(strings.TrimPrefix(ref, missionRefPrefix), "/")
+		if !ok || strings.Contains(suffix, "/") {
+			continue
+		}
+		name := missionID + "-" + suffix
+		if !checkpointTagPattern.MatchString(name) {
+			continue
+		}
+		checkpoints = append(checkpoints, Orphan{MissionID: missionID, Name: name, Ref: ref})
+	}
+	return checkpoints, nil
+}
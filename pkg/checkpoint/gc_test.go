@@ -0,0 +1,98 @@
+package checkpoint
+
+import (
+	"testing"
+	"time"
+
+	internalgit "github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GC(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		head, err := gitClient.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		require.NoError(t, gitClient.CreateTag(t.Context(), "v1-2", head))
+		require.NoError(t, gitClient.UpdateRef(t.Context(), "refs/mission/paused/old-1", head))
+
+		// A mission abandoned together with its .mission directory
+		createMissionFile(t, fs, "old-1", []string{"old.txt"})
+		require.NoError(t, afero.WriteFile(fs, "old.txt", []byte("old"), 0644))
+		svc := newTestService(t, fs, gitClient, backend)
+		_, err = svc.Create(t.Context(), "old-1")
+		require.NoError(t, err)
+		require.NoError(t, fs.RemoveAll(".mission"))
+
+		createMissionFile(t, fs, "live-1", []string{"live.txt"})
+		require.NoError(t, afero.WriteFile(fs, "live.txt", []byte("live"), 0644))
+		_, err = svc.Create(t.Context(), "live-1")
+		require.NoError(t, err)
+
+		result, err := svc.GC(t.Context(), GCOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"old-1"}, result.Missions)
+		var names []string
+		for _, orphan := range result.Orphans {
+			names = append(names, orphan.Name)
+			assert.NotEmpty(t, orphan.Commit)
+		}
+		assert.ElementsMatch(t, []string{"old-1-1", "old-1-baseline"}, names)
+		assert.True(t, checkpointExists(svc, "old-1-1"), "a dry run removes nothing")
+
+		result, err = svc.GC(t.Context(), GCOptions{})
+		require.NoError(t, err)
+		assert.Len(t, result.Orphans, 2)
+		assert.False(t, checkpointExists(svc, "old-1-1"))
+		assert.False(t, checkpointExists(svc, "old-1-baseline"))
+		assert.True(t, checkpointExists(svc, "live-1-1"))
+		assert.True(t, checkpointExists(svc, "live-1-baseline"))
+
+		tags, err := gitClient.ListTags(t.Context(), "v1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v1-2"}, tags, "tags not made by checkpoints are kept")
+		_, err = gitClient.GetTagCommit(t.Context(), "refs/mission/paused/old-1")
+		assert.NoError(t, err, "paused stashes are kept")
+
+		metadata, err := svc.loadMetadata()
+		require.NoError(t, err)
+		assert.Contains(t, metadata, "live-1-1")
+
+		result, err = svc.GC(t.Context(), GCOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, result.Orphans)
+	})
+}
+
+func TestService_StaleMissions(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	gitClient := internalgit.NewMemGitClient(repo, fs)
+
+	createMissionFile(t, fs, "old-1", []string{"old.txt"})
+	svc := newTestService(t, fs, gitClient, BackendRefs)
+	stale, err := svc.StaleMissions(t.Context(), time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, stale, "no checkpoint metadata")
+
+	// A mission replaced without being archived leaves its checkpoint metadata behind
+	require.NoError(t, afero.WriteFile(fs, "old.txt", []byte("old"), 0644))
+	_, err = svc.Create(t.Context(), "old-1")
+	require.NoError(t, err)
+	createMissionFile(t, fs, "live-1", []string{"live.txt"})
+	require.NoError(t, afero.WriteFile(fs, "live.txt", []byte("live"), 0644))
+	_, err = svc.Create(t.Context(), "live-1")
+	require.NoError(t, err)
+
+	stale, err = svc.StaleMissions(t.Context(), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old-1"}, stale)
+
+	_, err = svc.GC(t.Context(), GCOptions{})
+	require.NoError(t, err)
+	stale, err = svc.StaleMissions(t.Context(), time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, stale, "gc removes the metadata with the checkpoints")
+}
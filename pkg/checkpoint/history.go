@@ -0,0 +1,151 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+)
+
+// maxListedCommits caps the foreign commits named in a HistoryError message
+const maxListedCommits = 3
+
+// HistoryError is returned by Create and Consolidate when HEAD or the branch moved outside
+// the mission since its baseline. Squashing the checkpoints would then fold unrelated
+// commits into the mission commit, or reset a different branch.
+type HistoryError struct {
+	MissionID string
+	// Latest is the most recent checkpoint of the mission
+	Latest string
+	// Branch is the branch of the baseline and CurrentBranch the one checked out now;
+	// they only differ when the branch changed. An empty name is a detached HEAD.
+	Branch        string
+	CurrentBranch string
+	// Foreign lists the commits between the baseline and HEAD that are not checkpoints
+	// of the mission, newest first
+	Foreign []git.CommitInfo
+	// Diverged is set when HEAD no longer contains the baseline, as after a rebase or reset
+	Diverged bool
+}
+
+func (e *HistoryError) Error() string {
+	var problems, options []string
+	if e.Branch != e.CurrentBranch {
+		problems = append(problems, fmt.Sprintf("branch changed from %s to %s since the baseline of %s",
+			branchName(e.Branch), branchName(e.CurrentBranch), e.MissionID))
+		if e.Branch != "" {
+			options = append(options, fmt.Sprintf("switch back with `git switch %s`", e.Branch))
+		}
+	}
+	if e.Diverged {
+		problems = append(problems, fmt.Sprintf("HEAD no longer contains the baseline of %s (rebased or reset?)", e.MissionID))
+	}
+	if len(e.Foreign) > 0 {
+		var listed []string
+		for _, commit := range e.Foreign[:min(len(e.Foreign), maxListedCommits)] {
+			listed = append(listed, fmt.Sprintf("%s %q", shortHash(commit.Hash), commit.Subject()))
+		}
+		if len(e.Foreign) > maxListedCommits {
+			listed = append(listed, fmt.Sprintf("%d more", len(e.Foreign)-maxListedCommits))
+		}
+		problems = append(problems, fmt.Sprintf("%d commits since the baseline of %s are not its checkpoints: %s",
+			len(e.Foreign), e.MissionID, strings.Join(listed, ", ")))
+	}
+	if (e.Diverged || len(e.Foreign) > 0) && e.Latest != "" {
+		options = append(options, fmt.Sprintf("keep those commits on another branch and return to the checkpoints with `git reset --keep %s`", e.Latest))
+	}
+	options = append(options, "run `m checkpoint clear` to keep the history as it is and start new checkpoints from HEAD")
+	return strings.Join(problems, "; ") + ". To recover, " + strings.Join(options, ", or ")
+}
+
+// verifyHistory checks that HEAD and the branch are where the mission's checkpoints left
+// them. Missions without a baseline, or without git, have nothing to check. Stores that
+// keep checkpoints off the branch only need the branch to be unchanged.
+func (s *Service) verifyHistory(ctx context.Context, missionID string) error {
+	if s.git == nil {
+		return nil
+	}
+	baselineName := missionID + "-baseline"
+	baseline, err := s.store.resolve(ctx, s.store.ref(baselineName))
+	if err != nil {
+		return nil
+	}
+	metadata, err := s.loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	herr := &HistoryError{MissionID: missionID}
+	if branch := metadata[baselineName].Branch; branch != "" {
+		current, err := s.git.CurrentBranch(ctx)
+		if err != nil {
+			return fmt.Errorf("checking branch: %w", err)
+		}
+		herr.Branch, herr.CurrentBranch = branch, current
+	}
+
+	if s.store.onBranch() {
+		if err := s.findForeignCommits(ctx, missionID, baseline, herr); err != nil {
+			return err
+		}
+	}
+
+	if herr.Branch == herr.CurrentBranch && !herr.Diverged && len(herr.Foreign) == 0 {
+		return nil
+	}
+	return herr
+}
+
+// findForeignCommits records in herr whether HEAD still contains the baseline and which
+// commits after it are not checkpoints of the mission
+func (s *Service) findForeignCommits(ctx context.Context, missionID, baseline string, herr *HistoryError) error {
+	names, err := s.store.names(ctx, missionID)
+	if err != nil {
+		return fmt.Errorf("listing checkpoints: %w", err)
+	}
+	checkpoints := make(map[string]bool)
+	latest := 0
+	for _, name := range names {
+		hash, err := s.store.resolve(ctx, s.store.ref(name))
+		if err != nil {
+			continue
+		}
+		checkpoints[hash] = true
+		if num, err := strconv.Atoi(strings.TrimPrefix(name, missionID+"-")); err == nil && num > latest {
+			latest = num
+			herr.Latest = name
+		}
+	}
+
+	lost, err := s.git.CommitsBetween(ctx, "HEAD", baseline)
+	if err != nil {
+		return fmt.Errorf("checking history since the baseline: %w", err)
+	}
+	herr.Diverged = len(lost) > 0
+
+	commits, err := s.git.CommitsBetween(ctx, baseline, "HEAD")
+	if err != nil {
+		return fmt.Errorf("checking history since the baseline: %w", err)
+	}
+	for _, commit := range commits {
+		if !checkpoints[commit.Hash] {
+			herr.Foreign = append(herr.Foreign, commit)
+		}
+	}
+	return nil
+}
+
+// branchName describes a branch for messages
+func branchName(branch string) string {
+	if branch == "" {
+		return "a detached HEAD"
+	}
+	return branch
+}
+
+// shortHash abbreviates a commit hash as m checkpoint list does
+func shortHash(hash string) string {
+	return hash[:min(len(hash), 8)]
+}
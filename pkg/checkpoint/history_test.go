@@ -0,0 +1,116 @@
+package checkpoint
+
+import (
+	"testing"
+
+	internalgit "github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startHistoryMission creates two checkpoints of a mission scoped to a.txt
+func startHistoryMission(t *testing.T, backend string) (afero.Fs, *git.Repository, internalgit.GitClient, *Service) {
+	fs, repo := setupTestRepo(t)
+	createMissionFile(t, fs, "test-history", []string{"a.txt"})
+	gitClient := internalgit.NewMemGitClient(repo, fs)
+	svc := newTestService(t, fs, gitClient, backend)
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a1"), 0644))
+	_, err := svc.Create(t.Context(), "test-history")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a2"), 0644))
+	_, err = svc.Create(t.Context(), "test-history")
+	require.NoError(t, err)
+	return fs, repo, gitClient, svc
+}
+
+func TestService_VerifyHistory_Unchanged(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, _, _, svc := startHistoryMission(t, backend)
+
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a3"), 0644))
+		_, err := svc.Create(t.Context(), "test-history")
+		require.NoError(t, err)
+		_, err = svc.Consolidate(t.Context(), "test-history", "feat: history")
+		require.NoError(t, err)
+	})
+}
+
+func TestService_VerifyHistory_ForeignCommit(t *testing.T) {
+	fs, _, gitClient, svc := startHistoryMission(t, BackendTags)
+
+	require.NoError(t, afero.WriteFile(fs, "other.txt", []byte("unrelated"), 0644))
+	require.NoError(t, gitClient.Add(t.Context(), []string{"other.txt"}))
+	foreign, err := gitClient.Commit(t.Context(), "fix: unrelated work")
+	require.NoError(t, err)
+
+	_, err = svc.Create(t.Context(), "test-history")
+	var herr *HistoryError
+	require.ErrorAs(t, err, &herr)
+	assert.False(t, herr.Diverged)
+	require.Len(t, herr.Foreign, 1)
+	assert.Equal(t, foreign, herr.Foreign[0].Hash)
+	assert.Equal(t, "test-history-2", herr.Latest)
+	assert.ErrorContains(t, err, `1 commits since the baseline of test-history are not its checkpoints: `+foreign[:8]+` "fix: unrelated work"`)
+	assert.ErrorContains(t, err, "git reset --keep test-history-2")
+	assert.False(t, checkpointExists(svc, "test-history-3"), "no checkpoint is created")
+
+	_, err = svc.Consolidate(t.Context(), "test-history", "feat: history")
+	require.ErrorAs(t, err, &herr)
+	head, err := gitClient.GetTagCommit(t.Context(), "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, foreign, head, "the foreign commit is not squashed away")
+}
+
+func TestService_VerifyHistory_ResetBeforeBaseline(t *testing.T) {
+	_, _, gitClient, svc := startHistoryMission(t, BackendTags)
+
+	baseline, err := gitClient.GetTagCommit(t.Context(), "test-history-baseline")
+	require.NoError(t, err)
+	parent, err := gitClient.GetCommitParent(t.Context(), baseline)
+	require.NoError(t, err)
+	require.NoError(t, gitClient.SoftReset(t.Context(), parent))
+
+	_, err = svc.Consolidate(t.Context(), "test-history", "feat: history")
+	var herr *HistoryError
+	require.ErrorAs(t, err, &herr)
+	assert.True(t, herr.Diverged)
+	assert.Empty(t, herr.Foreign)
+}
+
+func TestService_VerifyHistory_BranchChanged(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		_, repo, _, svc := startHistoryMission(t, backend)
+
+		head, err := repo.Head()
+		require.NoError(t, err)
+		branch := plumbing.NewBranchReferenceName("elsewhere")
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(branch, head.Hash())))
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)))
+
+		_, err = svc.Create(t.Context(), "test-history")
+		var herr *HistoryError
+		require.ErrorAs(t, err, &herr)
+		assert.Equal(t, "master", herr.Branch)
+		assert.Equal(t, "elsewhere", herr.CurrentBranch)
+		assert.ErrorContains(t, err, "git switch master")
+
+		_, err = svc.Consolidate(t.Context(), "test-history", "feat: history")
+		require.ErrorAs(t, err, &herr)
+	})
+}
+
+func TestService_VerifyHistory_RefsIgnoreCommits(t *testing.T) {
+	fs, _, gitClient, svc := startHistoryMission(t, BackendRefs)
+
+	require.NoError(t, afero.WriteFile(fs, "other.txt", []byte("unrelated"), 0644))
+	require.NoError(t, gitClient.Add(t.Context(), []string{"other.txt"}))
+	_, err := gitClient.Commit(t.Context(), "fix: unrelated work")
+	require.NoError(t, err)
+
+	_, err = svc.Create(t.Context(), "test-history")
+	assert.NoError(t, err, "refs checkpoints never squash, so other commits are harmless")
+}
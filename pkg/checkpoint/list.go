@@ -0,0 +1,88 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+)
+
+// Checkpoint describes a checkpoint and what changed since the previous one
+type Checkpoint struct {
+	Name      string    `json:"name"`
+	Number    int       `json:"number"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	Label     string    `json:"label,omitempty"`
+	// Since is the previous checkpoint, or for the first checkpoint the commit it was created on
+	Since     string         `json:"since"`
+	Files     []git.FileStat `json:"files"`
+	Additions int            `json:"additions"`
+	Deletions int            `json:"deletions"`
+}
+
+// List returns the numbered checkpoints of a mission in creation order with the
+// file changes of each relative to the previous checkpoint
+func (s *Service) List(ctx context.Context, missionID string) ([]Checkpoint, error) {
+	tags, err := s.store.names(ctx, missionID)
+	if err != nil {
+		return nil, fmt.Errorf("listing checkpoints: %w", err)
+	}
+	metadata, err := s.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoints []Checkpoint
+	for _, tag := range tags {
+		num, err := strconv.Atoi(strings.TrimPrefix(tag, missionID+"-"))
+		if err != nil || num < 1 {
+			continue
+		}
+		hash, err := s.store.resolve(ctx, s.store.ref(tag))
+		if err != nil {
+			return nil, fmt.Errorf("resolving checkpoint %s: %w", tag, err)
+		}
+		checkpoints = append(checkpoints, Checkpoint{Name: tag, Number: num, Hash: hash})
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Number < checkpoints[j].Number })
+
+	for i := range checkpoints {
+		cp := &checkpoints[i]
+		if md, ok := metadata[cp.Name]; ok {
+			cp.Label = md.Label
+			cp.CreatedAt = md.CreatedAt
+		} else if at, err := s.store.createdAt(ctx, cp.Hash); err == nil {
+			cp.CreatedAt = at
+		}
+
+		since, sinceHash := s.previousCheckpoint(ctx, checkpoints, i)
+		cp.Since = since
+		if sinceHash == cp.Hash {
+			cp.Files = []git.FileStat{}
+			continue
+		}
+		if cp.Files, err = s.store.diffStat(ctx, sinceHash, cp.Hash); err != nil {
+			return nil, fmt.Errorf("diffing checkpoint %s: %w", cp.Name, err)
+		}
+		for _, file := range cp.Files {
+			cp.Additions += file.Additions
+			cp.Deletions += file.Deletions
+		}
+	}
+	return checkpoints, nil
+}
+
+// previousCheckpoint returns the name and hash the checkpoint at index i is compared
+// against. The first checkpoint is compared with what the store says it was created on.
+func (s *Service) previousCheckpoint(ctx context.Context, checkpoints []Checkpoint, i int) (string, string) {
+	if i > 0 {
+		return checkpoints[i-1].Name, checkpoints[i-1].Hash
+	}
+	since := s.store.since(ctx, checkpoints[i].Hash)
+	return since, since
+}
@@ -0,0 +1,75 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	internalgit "github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_List(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-list"
+		createMissionFile(t, fs, missionID, []string{"a.txt", "b.txt"})
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		checkpoints, err := svc.List(t.Context(), missionID)
+		require.NoError(t, err)
+		assert.Empty(t, checkpoints)
+		base, err := repo.Head()
+		require.NoError(t, err)
+
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("one\ntwo\n"), 0644))
+		_, err = svc.CreateWithLabel(t.Context(), missionID, "add a")
+		require.NoError(t, err)
+
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("one\n2\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "b.txt", []byte("b\n"), 0644))
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Nothing changed: the checkpoint tags the previous commit
+		_, err = svc.CreateWithLabel(t.Context(), missionID, "  no-op  ")
+		require.NoError(t, err)
+
+		checkpoints, err = svc.List(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Len(t, checkpoints, 3, "the baseline tag is not listed")
+
+		first := checkpoints[0]
+		assert.Equal(t, "test-list-1", first.Name)
+		assert.Equal(t, "add a", first.Label)
+		assert.False(t, first.CreatedAt.IsZero())
+		assert.Equal(t, base.Hash().String(), first.Since, "the first checkpoint compares against its parent")
+		require.Len(t, first.Files, 1)
+		assert.Equal(t, internalgit.FileStat{Path: "a.txt", Change: internalgit.ChangeAdded, Additions: 2}, first.Files[0])
+
+		second := checkpoints[1]
+		assert.Equal(t, "test-list-1", second.Since)
+		assert.Empty(t, second.Label)
+		assert.Equal(t, []internalgit.FileStat{
+			{Path: "a.txt", Change: internalgit.ChangeModified, Additions: 1, Deletions: 1},
+			{Path: "b.txt", Change: internalgit.ChangeAdded, Additions: 1},
+		}, second.Files)
+		assert.Equal(t, 2, second.Additions)
+		assert.Equal(t, 1, second.Deletions)
+
+		third := checkpoints[2]
+		assert.Equal(t, "no-op", third.Label)
+		assert.Equal(t, second.Hash, third.Hash)
+		assert.Empty(t, third.Files)
+
+		// Clearing the checkpoints drops their metadata
+		_, err = svc.Clear(t.Context(), missionID)
+		require.NoError(t, err)
+		exists, err := afero.Exists(fs, filepath.Join(".mission", MetadataFileName))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,75 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/mission"
+	"github.com/spf13/afero"
+)
+
+// MetadataFileName holds checkpoint labels and creation times, keyed by checkpoint name.
+// It lives in the shared mission root so it survives pausing and switching missions.
+const MetadataFileName = "checkpoints.json"
+
+// Metadata is what git does not record about a checkpoint. A checkpoint without
+// changes tags an existing commit, so the commit time is not its creation time.
+type Metadata struct {
+	Label     string    `json:"label,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Absent lists the scope paths that did not exist when the baseline was taken, so a
+	// full restore can delete the files the mission created. Only set on the baseline.
+	Absent []string `json:"absent,omitempty"`
+	// Branch is the branch checked out when the baseline was taken. Only set on the baseline.
+	Branch string `json:"branch,omitempty"`
+}
+
+// metadataPath returns the location of the checkpoint metadata file
+func (s *Service) metadataPath() string {
+	return filepath.Join(mission.NewBaseService(s.fs, s.missionDir).RootDir(), MetadataFileName)
+}
+
+// loadMetadata reads checkpoint metadata; a missing file means no metadata
+func (s *Service) loadMetadata() (map[string]Metadata, error) {
+	metadata := make(map[string]Metadata)
+	data, err := afero.ReadFile(s.fs, s.metadataPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return metadata, nil
+		}
+		return nil, fmt.Errorf("reading checkpoint metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", MetadataFileName, err)
+	}
+	return metadata, nil
+}
+
+// updateMetadata applies update to the stored metadata and saves it, removing the
+// file once no checkpoint has metadata
+func (s *Service) updateMetadata(update func(map[string]Metadata)) error {
+	metadata, err := s.loadMetadata()
+	if err != nil {
+		return err
+	}
+	update(metadata)
+
+	if len(metadata) == 0 {
+		if err := s.fs.Remove(s.metadataPath()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing checkpoint metadata: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint metadata: %w", err)
+	}
+	if err := afero.WriteFile(s.fs, s.metadataPath(), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing checkpoint metadata: %w", err)
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package checkpoint
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dnatag/mission-toolkit/pkg/git"
 	"github.com/dnatag/mission-toolkit/pkg/mission"
@@ -17,196 +20,431 @@ type Service struct {
 	missionDir    string
 	missionReader *mission.Reader
 	git           git.GitClient
+	store         store
 }
 
 // NewService creates a new checkpoint service using CmdGitClient (production)
 func NewService(fs afero.Fs, missionDir string) (*Service, error) {
-	missionPath := fmt.Sprintf("%s/mission.md", missionDir)
-	return &Service{
-		fs:            fs,
-		missionDir:    missionDir,
-		missionReader: mission.NewReader(fs, missionPath),
-		git:           git.NewCmdGitClient("."),
-	}, nil
+	return NewServiceWithBackend(fs, missionDir, git.NewCmdGitClient("."), BackendTags)
 }
 
 // NewServiceWithGit creates a new checkpoint service with a specific GitClient (testing)
 func NewServiceWithGit(fs afero.Fs, missionDir string, gitClient git.GitClient) *Service {
-	missionPath := fmt.Sprintf("%s/mission.md", missionDir)
-	return &Service{
-		fs:            fs,
-		missionDir:    missionDir,
-		missionReader: mission.NewReader(fs, missionPath),
-		git:           gitClient,
-	}
+	s, _ := NewServiceWithBackend(fs, missionDir, gitClient, BackendTags)
+	return s
 }
 
-// Create creates a new checkpoint for the current mission
-func (s *Service) Create(missionID string) (string, error) {
-	stagableFiles, err := s.getStagableScope()
+// NewServiceWithBackend creates a new checkpoint service storing checkpoints in the
+// named backend. An empty backend means BackendTags, or BackendFS when gitClient is nil
+// because there is no repository.
+func NewServiceWithBackend(fs afero.Fs, missionDir string, gitClient git.GitClient, backend string) (*Service, error) {
+	s := &Service{
+		fs:         fs,
+		missionDir: missionDir,
+		git:        gitClient,
+	}
+	s.missionReader = mission.NewReader(fs, s.missionPath())
+	st, err := newStore(s, backend)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	s.store = st
+	return s, nil
+}
 
-	num, err := s.getNextCheckpointNumber(missionID)
-	if err != nil {
-		return "", fmt.Errorf("getting next checkpoint number: %w", err)
-	}
+// missionPath returns the path of the mission file in the mission directory
+func (s *Service) missionPath() string {
+	return fmt.Sprintf("%s/mission.md", s.missionDir)
+}
 
-	checkpointName := fmt.Sprintf("%s-%d", missionID, num)
+// Create creates a new checkpoint for the current mission
+func (s *Service) Create(ctx context.Context, missionID string) (string, error) {
+	return s.CreateWithLabel(ctx, missionID, "")
+}
 
-	if err := s.git.Add(stagableFiles); err != nil {
-		return "", fmt.Errorf("staging files: %w", err)
+// CreateWithLabel creates a new checkpoint for the current mission and records its
+// creation time and an optional label describing it
+func (s *Service) CreateWithLabel(ctx context.Context, missionID, label string) (string, error) {
+	if missionID == "" {
+		return "", fmt.Errorf("mission ID is required")
 	}
 
-	commitHash, err := s.git.CommitNoVerify(fmt.Sprintf("checkpoint: %s", checkpointName))
+	num, err := s.getNextCheckpointNumber(ctx, missionID)
 	if err != nil {
-		if !errors.Is(err, git.ErrNoChanges) {
-			return "", fmt.Errorf("creating checkpoint commit: %w", err)
+		return "", fmt.Errorf("getting next checkpoint number: %w", err)
+	}
+
+	// The baseline remembers which scope paths do not exist yet, before the snapshot,
+	// and the branch later checkpoints must stay on
+	var absent []string
+	var branch string
+	if num == 1 {
+		if absent, err = s.absentScopePaths(); err != nil {
+			return "", err
 		}
-		// No changes, tag current HEAD
-		if commitHash, err = s.git.GetTagCommit("HEAD"); err != nil {
-			return "", fmt.Errorf("getting HEAD hash: %w", err)
+		if s.git != nil {
+			if branch, err = s.git.CurrentBranch(ctx); err != nil {
+				return "", err
+			}
 		}
+	} else if err := s.verifyHistory(ctx, missionID); err != nil {
+		return "", err
 	}
 
-	if err := s.git.CreateTag(checkpointName, commitHash); err != nil {
-		return "", fmt.Errorf("creating checkpoint tag: %w", err)
+	checkpointName := fmt.Sprintf("%s-%d", missionID, num)
+	commitHash, err := s.store.create(ctx, missionID, num)
+	if err != nil {
+		return "", err
 	}
 
 	// Create baseline tag on first checkpoint for easy diff viewing
 	if num == 1 {
-		if err := s.createBaselineTag(missionID, commitHash); err != nil {
+		if err := s.createBaselineTag(ctx, missionID, commitHash); err != nil {
 			return "", fmt.Errorf("creating baseline tag: %w", err)
 		}
 	}
 
+	now := time.Now().UTC()
+	err = s.updateMetadata(func(m map[string]Metadata) {
+		m[checkpointName] = Metadata{Label: strings.TrimSpace(label), CreatedAt: now}
+		if num == 1 {
+			m[missionID+"-baseline"] = Metadata{CreatedAt: now, Absent: absent, Branch: branch}
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
 	return checkpointName, nil
 }
 
 // createBaselineTag creates a baseline tag for viewing cumulative mission changes
-func (s *Service) createBaselineTag(missionID, commitHash string) error {
+func (s *Service) createBaselineTag(ctx context.Context, missionID, commitHash string) error {
 	baselineTag := fmt.Sprintf("%s-baseline", missionID)
-	return s.git.CreateTag(baselineTag, commitHash)
+	return s.store.mark(ctx, baselineTag, commitHash)
+}
+
+// absentScopePaths returns the scope paths that do not exist on disk. Only literal
+// entries can name files that do not exist yet.
+func (s *Service) absentScopePaths() ([]string, error) {
+	m, err := s.missionReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+	files, err := mission.NewScope(s.fs, m.GetScope()).Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("resolving mission scope: %w", err)
+	}
+	var absent []string
+	for _, file := range files {
+		exists, err := afero.Exists(s.fs, file)
+		if err != nil {
+			return nil, fmt.Errorf("checking file existence %s: %w", file, err)
+		}
+		if !exists {
+			absent = append(absent, file)
+		}
+	}
+	return absent, nil
 }
 
 // Restore reverts working directory to specified checkpoint
-func (s *Service) Restore(checkpointName string) error {
-	scope, err := s.getScope()
+func (s *Service) Restore(ctx context.Context, checkpointName string) error {
+	ref := s.store.ref(checkpointName)
+	if _, err := s.store.resolve(ctx, ref); err != nil {
+		return fmt.Errorf("checkpoint %s not found: %w", checkpointName, err)
+	}
+	scope, err := s.getScope(ctx, ref)
 	if err != nil {
 		return err
 	}
-	return s.git.Restore(checkpointName, scope)
+	return s.store.restore(ctx, ref, scope)
 }
 
-// Clear removes all checkpoint tags for the specified mission
-func (s *Service) Clear(missionID string) (int, error) {
-	tags, err := s.git.ListTags(missionID + "-")
+// Clear removes all checkpoints, including the baseline, for the specified mission
+func (s *Service) Clear(ctx context.Context, missionID string) (int, error) {
+	tags, err := s.store.names(ctx, missionID)
 	if err != nil {
-		return 0, fmt.Errorf("listing tags: %w", err)
+		return 0, fmt.Errorf("listing checkpoints: %w", err)
 	}
 
 	for i, tag := range tags {
-		if err := s.git.DeleteTag(tag); err != nil {
-			return i, fmt.Errorf("deleting tag %s: %w", tag, err)
+		if err := s.store.remove(ctx, tag); err != nil {
+			return i, fmt.Errorf("deleting checkpoint %s: %w", tag, err)
+		}
+	}
+
+	if len(tags) > 0 {
+		err = s.updateMetadata(func(m map[string]Metadata) {
+			for _, tag := range tags {
+				delete(m, tag)
+			}
+		})
+		if err != nil {
+			return len(tags), err
 		}
 	}
 
 	return len(tags), nil
 }
 
+// RestoreAllOptions controls how RestoreAll treats files created during the mission
+type RestoreAllOptions struct {
+	// KeepNew leaves the scope files that did not exist at baseline in place
+	KeepNew bool
+}
+
+// RestoreAllResult describes what a full restore changed
+type RestoreAllResult struct {
+	// Cleared is the number of checkpoints deleted, including the baseline
+	Cleared int
+	// Removed lists the scope files that did not exist at baseline and were deleted
+	Removed []string
+	// Untracked lists files created during the mission that were left in place and
+	// need manual cleanup
+	Untracked []string
+	// BaseBranch is the branch switched back to when the mission ran on MissionBranch,
+	// and DeletedBranch whether the mission branch could be deleted
+	MissionBranch string
+	BaseBranch    string
+	DeletedBranch bool
+}
+
 // RestoreAll reverts the working directory to the baseline commit (before any checkpoints)
-// and deletes all checkpoint tags. Returns the number of checkpoints cleared and a list of
+// and deletes all checkpoints. Returns the number of checkpoints cleared and a list of
 // untracked files that need manual cleanup. This is used by the --all flag to completely
 // undo all mission changes and clean up checkpoint history.
-func (s *Service) RestoreAll(missionID string) (int, []string, error) {
-	baselineTag := fmt.Sprintf("%s-baseline", missionID)
-	baselineHash, err := s.git.GetTagCommit(baselineTag)
+func (s *Service) RestoreAll(ctx context.Context, missionID string) (int, []string, error) {
+	result, err := s.RestoreAllWithOptions(ctx, missionID, RestoreAllOptions{})
 	if err != nil {
-		return 0, nil, fmt.Errorf("getting baseline commit: %w", err)
+		return 0, nil, err
 	}
+	return result.Cleared, result.Untracked, nil
+}
 
-	scope, err := s.getScope()
+// RestoreAllWithOptions is RestoreAll that also reports the files it removed. Scope
+// paths recorded as absent by the baseline are deleted unless opts.KeepNew is set;
+// other untracked files are never touched. A mission started on its own branch is
+// switched back to the branch it was started from.
+func (s *Service) RestoreAllWithOptions(ctx context.Context, missionID string, opts RestoreAllOptions) (*RestoreAllResult, error) {
+	baselineName := fmt.Sprintf("%s-baseline", missionID)
+	baselineRef := s.store.ref(baselineName)
+	baselineHash, err := s.store.resolve(ctx, baselineRef)
 	if err != nil {
-		return 0, nil, fmt.Errorf("reading mission scope: %w", err)
+		return nil, fmt.Errorf("getting baseline commit: %w", err)
 	}
 
-	if err := s.git.Restore(baselineTag, scope); err != nil {
-		return 0, nil, fmt.Errorf("restoring files to baseline: %w", err)
+	scope, err := s.getScope(ctx, baselineRef)
+	if err != nil {
+		return nil, fmt.Errorf("reading mission scope: %w", err)
 	}
 
-	if err := s.git.SoftReset(baselineHash); err != nil {
-		return 0, nil, fmt.Errorf("resetting HEAD to baseline: %w", err)
+	if err := s.store.restore(ctx, baselineRef, scope); err != nil {
+		return nil, fmt.Errorf("restoring files to baseline: %w", err)
 	}
 
-	count, err := s.Clear(missionID)
-	if err != nil {
-		return 0, nil, fmt.Errorf("clearing checkpoint tags: %w", err)
+	if s.store.onBranch() {
+		if err := s.git.SoftReset(ctx, baselineHash); err != nil {
+			return nil, fmt.Errorf("resetting HEAD to baseline: %w", err)
+		}
+	}
+
+	result := &RestoreAllResult{}
+	if !opts.KeepNew {
+		metadata, err := s.loadMetadata()
+		if err != nil {
+			return nil, err
+		}
+		added, err := s.addedScopeFiles(ctx, baselineRef)
+		if err != nil {
+			return nil, fmt.Errorf("listing files created during the mission: %w", err)
+		}
+		created := append(slices.Clone(metadata[baselineName].Absent), added...)
+		if result.Removed, err = s.removeCreated(ctx, created); err != nil {
+			return nil, fmt.Errorf("removing files created during the mission: %w", err)
+		}
+	}
+
+	// Check for untracked files after restore, before the baseline is cleared
+	if result.Untracked, err = s.store.created(ctx, baselineHash); err != nil {
+		return nil, fmt.Errorf("checking for untracked files: %w", err)
+	}
+
+	if result.Cleared, err = s.Clear(ctx, missionID); err != nil {
+		return nil, fmt.Errorf("clearing checkpoints: %w", err)
 	}
 
-	// Check for untracked files after restore
-	untrackedFiles, err := s.git.GetUntrackedFiles()
+	if err := s.leaveMissionBranch(ctx, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// addedScopeFiles returns the files matching a glob or directory entry of the scope
+// that the baseline does not have. Literal entries are covered by the absent paths the
+// baseline records, while pattern entries only match files once they exist.
+func (s *Service) addedScopeFiles(ctx context.Context, baselineRef string) ([]string, error) {
+	m, err := s.missionReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+	scope := mission.NewScope(s.fs, m.GetScope())
+	if !scope.HasPatterns() {
+		return nil, nil
+	}
+	files, err := scope.Resolve()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	diffs, err := s.store.diff(ctx, baselineRef, "", files)
 	if err != nil {
-		return count, nil, fmt.Errorf("checking for untracked files: %w", err)
+		return nil, err
+	}
+	var added []string
+	for _, d := range diffs {
+		if d.Change == git.ChangeAdded {
+			added = append(added, d.Path)
+		}
 	}
+	return added, nil
+}
 
-	return count, untrackedFiles, nil
+// removeCreated deletes the files among absent that exist now. On a branch store the
+// checkpoint commits staged them, so their index entries are dropped too.
+func (s *Service) removeCreated(ctx context.Context, absent []string) ([]string, error) {
+	var removed []string
+	for _, file := range absent {
+		info, err := s.fs.Stat(file)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if err := s.fs.Remove(file); err != nil {
+			return removed, err
+		}
+		removed = append(removed, file)
+	}
+	if len(removed) == 0 || !s.store.onBranch() {
+		return removed, nil
+	}
+
+	var staged []string
+	for _, file := range removed {
+		if tracked, err := s.git.IsTracked(ctx, file); err == nil && tracked {
+			staged = append(staged, file)
+		}
+	}
+	if err := s.git.Add(ctx, staged); err != nil {
+		return removed, fmt.Errorf("unstaging removed files: %w", err)
+	}
+	return removed, nil
 }
 
 // ConsolidateResult contains the result of a consolidate operation
 type ConsolidateResult struct {
 	CommitHash    string
 	UnstagedFiles []string
+	// MergedInto is the branch the mission branch was merged into, and MergeHash its
+	// HEAD afterwards, when ConsolidateOptions.Merge was set
+	MergedInto string
+	MergeHash  string
+}
+
+// ConsolidateOptions controls what happens after the mission commit is created
+type ConsolidateOptions struct {
+	// Merge is MergeFastForward or MergeSquash to merge the mission branch back into the
+	// branch the mission was started from, or empty to stay on the mission branch
+	Merge string
+	// BaseCheckout is the checkout that has the base branch checked out when the mission
+	// runs in its own worktree. The merge then happens there instead of switching branches.
+	BaseCheckout git.GitClient
 }
 
 // Consolidate creates a final commit with all changes from the mission and clears checkpoints.
-func (s *Service) Consolidate(missionID, message string) (*ConsolidateResult, error) {
-	targetHash, err := s.squashCheckpoints(missionID)
-	if err != nil {
+func (s *Service) Consolidate(ctx context.Context, missionID, message string) (*ConsolidateResult, error) {
+	return s.ConsolidateWithOptions(ctx, missionID, message, ConsolidateOptions{})
+}
+
+// ConsolidateWithOptions is Consolidate that can merge a mission started on its own
+// branch back into its base branch once the mission commit exists.
+func (s *Service) ConsolidateWithOptions(ctx context.Context, missionID, message string, opts ConsolidateOptions) (*ConsolidateResult, error) {
+	if s.git == nil {
+		return nil, fmt.Errorf("committing checkpoints needs a git repository")
+	}
+	if err := s.verifyHistory(ctx, missionID); err != nil {
 		return nil, err
 	}
+	var m *mission.Mission
+	if opts.Merge != "" {
+		var err error
+		if m, err = s.mergeableMission(ctx, opts); err != nil {
+			return nil, err
+		}
+	}
 
-	if targetHash != "" {
-		if err := s.git.SoftReset(targetHash); err != nil {
-			return nil, fmt.Errorf("soft reset to %s failed: %w", targetHash, err)
+	if s.store.onBranch() {
+		targetHash, err := s.squashCheckpoints(ctx, missionID)
+		if err != nil {
+			return nil, err
+		}
+
+		if targetHash != "" {
+			if err := s.git.SoftReset(ctx, targetHash); err != nil {
+				return nil, fmt.Errorf("soft reset to %s failed: %w", targetHash, err)
+			}
 		}
 	}
 
-	stagableFiles, err := s.getStagableScope()
+	stagableFiles, err := s.getStagableScope(ctx, "HEAD")
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.git.Add(stagableFiles); err != nil {
+	if err := s.git.Add(ctx, stagableFiles); err != nil {
 		return nil, fmt.Errorf("staging final files: %w", err)
 	}
 
-	finalCommitHash, err := s.git.Commit(message)
+	// The trailers let m mission archive and the history find the commit of the mission
+	committed, err := s.missionReader.Read()
+	if err != nil || committed.ID != missionID {
+		committed = &mission.Mission{ID: missionID}
+	}
+	message = git.AppendTrailers(message, mission.CommitTrailers(committed)...)
+
+	finalCommitHash, err := s.git.Commit(ctx, message)
 	if err != nil {
 		return nil, fmt.Errorf("creating final commit: %w", err)
 	}
 
-	if _, err := s.Clear(missionID); err != nil {
+	if _, err := s.Clear(ctx, missionID); err != nil {
 		fmt.Printf("Warning: failed to clear all checkpoints: %v\n", err)
 	}
 
-	unstaged, _ := s.git.GetUnstagedFiles()
+	unstaged, _ := s.git.GetUnstagedFiles(ctx)
 
-	return &ConsolidateResult{
+	result := &ConsolidateResult{
 		CommitHash:    finalCommitHash,
 		UnstagedFiles: unstaged,
-	}, nil
+	}
+	if m != nil {
+		if result.MergeHash, err = s.mergeBack(ctx, m, opts, message); err != nil {
+			return nil, fmt.Errorf("committed %s on %s but merging it into %s failed: %w",
+				shortHash(finalCommitHash), m.Branch, m.BaseBranch, err)
+		}
+		result.MergedInto = m.BaseBranch
+	}
+	return result, nil
 }
 
 // squashCheckpoints finds the initial checkpoint and determines the target commit for squashing.
 // Returns the target hash to reset to, or empty string if no squashing is needed.
-func (s *Service) squashCheckpoints(missionID string) (string, error) {
-	initialCommitHash, err := s.git.GetTagCommit(fmt.Sprintf("%s-1", missionID))
+func (s *Service) squashCheckpoints(ctx context.Context, missionID string) (string, error) {
+	initialCommitHash, err := s.git.GetTagCommit(ctx, fmt.Sprintf("%s-1", missionID))
 	if err != nil {
 		return "", nil // No initial checkpoint found, no squashing needed
 	}
 
-	msg, err := s.git.GetCommitMessage(initialCommitHash)
+	msg, err := s.git.GetCommitMessage(ctx, initialCommitHash)
 	if err != nil {
 		return "", nil // Can't read commit message, skip squashing
 	}
@@ -214,7 +452,7 @@ func (s *Service) squashCheckpoints(missionID string) (string, error) {
 	targetHash := initialCommitHash
 	if strings.HasPrefix(msg, "checkpoint:") {
 		// If we created it, reset to its parent to squash it
-		if parentHash, err := s.git.GetCommitParent(initialCommitHash); err == nil && parentHash != "" {
+		if parentHash, err := s.git.GetCommitParent(ctx, initialCommitHash); err == nil && parentHash != "" {
 			targetHash = parentHash
 		}
 	}
@@ -225,14 +463,15 @@ func (s *Service) squashCheckpoints(missionID string) (string, error) {
 }
 
 // getStagableScope reads mission scope and filters to stagable files.
-// It combines getScope() and filterStagableFiles() to reduce duplication.
-func (s *Service) getStagableScope() ([]string, error) {
-	scope, err := s.getScope()
+// It combines getScope() and filterStagableFiles() to reduce duplication. Staging is
+// always against git, whichever store holds the checkpoints.
+func (s *Service) getStagableScope(ctx context.Context, base string) ([]string, error) {
+	scope, err := s.resolveScope(ctx, base, gitSnapshots{s: s})
 	if err != nil {
 		return nil, err
 	}
 
-	stagableFiles, err := s.filterStagableFiles(scope)
+	stagableFiles, err := s.filterStagableFiles(ctx, scope)
 	if err != nil {
 		return nil, fmt.Errorf("filtering stagable files: %w", err)
 	}
@@ -240,21 +479,48 @@ func (s *Service) getStagableScope() ([]string, error) {
 	return stagableFiles, nil
 }
 
-// getScope reads mission and returns scope files
-func (s *Service) getScope() ([]string, error) {
+// getScope reads mission and returns scope files with glob and directory entries expanded.
+// Pattern entries only see files on disk, so files that differ from base and match the
+// scope (such as deleted files) are added to keep deletions stagable and restorable.
+func (s *Service) getScope(ctx context.Context, base string) ([]string, error) {
+	return s.resolveScope(ctx, base, s.store)
+}
+
+// resolveScope is getScope with the files deleted since base taken from snaps
+func (s *Service) resolveScope(ctx context.Context, base string, snaps snapshots) ([]string, error) {
 	m, err := s.missionReader.Read()
 	if err != nil {
 		return nil, fmt.Errorf("reading mission: %w", err)
 	}
-	scope := m.GetScope()
-	if len(scope) == 0 {
+	entries := m.GetScope()
+	if len(entries) == 0 {
 		return nil, fmt.Errorf("no files in mission scope")
 	}
-	return scope, nil
+
+	scope := mission.NewScope(s.fs, entries)
+	files, err := scope.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("resolving mission scope: %w", err)
+	}
+	if !scope.HasPatterns() {
+		return files, nil
+	}
+
+	deleted, err := snaps.deleted(ctx, base)
+	if err != nil {
+		return nil, fmt.Errorf("listing changed files: %w", err)
+	}
+	for _, file := range deleted {
+		if scope.Match(file) && !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
 }
 
 // filterStagableFiles returns files that exist OR are tracked (for deletion)
-func (s *Service) filterStagableFiles(files []string) ([]string, error) {
+func (s *Service) filterStagableFiles(ctx context.Context, files []string) ([]string, error) {
 	var stagable []string
 	for _, file := range files {
 		exists, err := afero.Exists(s.fs, file)
@@ -268,7 +534,7 @@ func (s *Service) filterStagableFiles(files []string) ([]string, error) {
 		}
 
 		// If file doesn't exist, check if it's tracked (deleted)
-		if tracked, err := s.git.IsTracked(file); err != nil {
+		if tracked, err := s.git.IsTracked(ctx, file); err != nil {
 			return nil, fmt.Errorf("checking if file is tracked %s: %w", file, err)
 		} else if tracked {
 			stagable = append(stagable, file)
@@ -278,10 +544,10 @@ func (s *Service) filterStagableFiles(files []string) ([]string, error) {
 }
 
 // getNextCheckpointNumber finds the next available checkpoint number
-func (s *Service) getNextCheckpointNumber(missionID string) (int, error) {
-	tags, err := s.git.ListTags(missionID + "-")
+func (s *Service) getNextCheckpointNumber(ctx context.Context, missionID string) (int, error) {
+	tags, err := s.store.names(ctx, missionID)
 	if err != nil {
-		return 0, fmt.Errorf("listing tags: %w", err)
+		return 0, fmt.Errorf("listing checkpoints: %w", err)
 	}
 
 	maxNum := 0
@@ -1,6 +1,7 @@
 package checkpoint
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -44,6 +46,35 @@ func setupTestRepo(t *testing.T) (afero.Fs, *git.Repository) {
 	return afero.NewMemMapFs(), repo
 }
 
+// forEachBackend runs test once for every git checkpoint backend
+func forEachBackend(t *testing.T, test func(t *testing.T, backend string)) {
+	for _, backend := range []string{BackendTags, BackendRefs} {
+		t.Run(backend, func(t *testing.T) { test(t, backend) })
+	}
+}
+
+// newTestService creates a checkpoint service for the mission in .mission using backend
+func newTestService(t *testing.T, fs afero.Fs, gitClient internalgit.GitClient, backend string) *Service {
+	svc, err := NewServiceWithBackend(fs, ".mission", gitClient, backend)
+	require.NoError(t, err)
+	return svc
+}
+
+// checkpointExists reports whether the checkpoint name resolves in the service's backend
+func checkpointExists(svc *Service, name string) bool {
+	_, err := svc.store.resolve(context.Background(), svc.store.ref(name))
+	return err == nil
+}
+
+// checkpointCommit resolves a checkpoint name to its commit
+func checkpointCommit(t *testing.T, repo *git.Repository, svc *Service, name string) *object.Commit {
+	hash, err := svc.git.GetTagCommit(t.Context(), svc.store.ref(name))
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	require.NoError(t, err)
+	return commit
+}
+
 func createMissionFile(t *testing.T, fs afero.Fs, missionID string, scopeFiles []string) {
 	missionDir := ".mission"
 	err := fs.MkdirAll(missionDir, 0755)
@@ -71,483 +102,546 @@ status: active
 }
 
 func TestService_Create(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	scopeFile := "test.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-mission"
+		scopeFile := "test.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	// Create scope file in afero FS
-	err := afero.WriteFile(fs, scopeFile, []byte("test content"), 0644)
-	require.NoError(t, err)
+		// Create scope file in afero FS
+		err := afero.WriteFile(fs, scopeFile, []byte("test content"), 0644)
+		require.NoError(t, err)
 
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Create checkpoint
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
-	require.Equal(t, fmt.Sprintf("%s-1", missionID), name)
+		// Create checkpoint
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%s-1", missionID), name)
 
-	// Verify tag exists
-	_, err = repo.Tag(name)
-	require.NoError(t, err)
+		// Verify tag exists
+		require.True(t, checkpointExists(svc, name))
+	})
 }
 
 func TestService_Create_CreatesBaselineTagOnFirstCheckpoint(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	scopeFile := "test.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-mission"
+		scopeFile := "test.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	err := afero.WriteFile(fs, scopeFile, []byte("test content"), 0644)
-	require.NoError(t, err)
+		err := afero.WriteFile(fs, scopeFile, []byte("test content"), 0644)
+		require.NoError(t, err)
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
-	require.Equal(t, fmt.Sprintf("%s-1", missionID), name)
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%s-1", missionID), name)
 
-	baselineTag := fmt.Sprintf("%s-baseline", missionID)
-	_, err = repo.Tag(baselineTag)
-	require.NoError(t, err)
+		baselineTag := fmt.Sprintf("%s-baseline", missionID)
+		require.True(t, checkpointExists(svc, baselineTag))
+	})
 }
 
 func TestService_Create_DoesNotCreateBaselineTagOnSubsequentCheckpoints(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	scopeFile := "test.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-mission"
+		scopeFile := "test.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	err := afero.WriteFile(fs, scopeFile, []byte("test content"), 0644)
-	require.NoError(t, err)
+		err := afero.WriteFile(fs, scopeFile, []byte("test content"), 0644)
+		require.NoError(t, err)
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	err = afero.WriteFile(fs, scopeFile, []byte("updated content"), 0644)
-	require.NoError(t, err)
+		err = afero.WriteFile(fs, scopeFile, []byte("updated content"), 0644)
+		require.NoError(t, err)
 
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
-	require.Equal(t, fmt.Sprintf("%s-2", missionID), name)
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%s-2", missionID), name)
 
-	tags, err := repo.Tags()
-	require.NoError(t, err)
+		names, err := svc.store.names(t.Context(), missionID)
+		require.NoError(t, err)
 
-	baselineCount := 0
-	baselineTag := fmt.Sprintf("%s-baseline", missionID)
-	err = tags.ForEach(func(ref *plumbing.Reference) error {
-		if ref.Name().Short() == baselineTag {
-			baselineCount++
+		baselineCount := 0
+		baselineTag := fmt.Sprintf("%s-baseline", missionID)
+		for _, name := range names {
+			if name == baselineTag {
+				baselineCount++
+			}
 		}
-		return nil
+		require.Equal(t, 1, baselineCount, "baseline tag should only be created once")
 	})
-	require.NoError(t, err)
-	require.Equal(t, 1, baselineCount, "baseline tag should only be created once")
 }
 
 func TestService_Create_OnlyScopeFiles(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-scope"
-	scopeFile := "scope.txt"
-	otherFile := "other.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	// Create files in afero FS
-	err := afero.WriteFile(fs, scopeFile, []byte("scope content"), 0644)
-	require.NoError(t, err)
-	err = afero.WriteFile(fs, otherFile, []byte("other content"), 0644)
-	require.NoError(t, err)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-scope"
+		scopeFile := "scope.txt"
+		otherFile := "other.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		// Create files in afero FS
+		err := afero.WriteFile(fs, scopeFile, []byte("scope content"), 0644)
+		require.NoError(t, err)
+		err = afero.WriteFile(fs, otherFile, []byte("other content"), 0644)
+		require.NoError(t, err)
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create checkpoint
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Verify commit content
+		commit := checkpointCommit(t, repo, svc, name)
+
+		// Check scope file is in commit
+		_, err = commit.File(scopeFile)
+		require.NoError(t, err)
+
+		// Check other file is NOT in commit
+		_, err = commit.File(otherFile)
+		require.Error(t, err) // Should be file not found error
+	})
+}
 
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+func TestService_Create_GlobScope(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	// Create checkpoint
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
+		missionID := "test-glob"
+		createMissionFile(t, fs, missionID, []string{"pkg/**/*.go", "!pkg/**/*_test.go"})
 
-	// Verify commit content
-	tagRef, err := repo.Tag(name)
-	require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, "pkg/a.go", []byte("package pkg"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "pkg/sub/b.go", []byte("package sub"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "pkg/a_test.go", []byte("package pkg"), 0644))
 
-	// Resolve annotated tag to commit
-	tagObj, err := repo.TagObject(tagRef.Hash())
-	require.NoError(t, err)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	commit, err := repo.CommitObject(tagObj.Target)
-	require.NoError(t, err)
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	// Check scope file is in commit
-	_, err = commit.File(scopeFile)
-	require.NoError(t, err)
+		commit := checkpointCommit(t, repo, svc, name)
 
-	// Check other file is NOT in commit
-	_, err = commit.File(otherFile)
-	require.Error(t, err) // Should be file not found error
+		_, err = commit.File("pkg/a.go")
+		require.NoError(t, err)
+		_, err = commit.File("pkg/sub/b.go")
+		require.NoError(t, err)
+		_, err = commit.File("pkg/a_test.go")
+		require.Error(t, err, "negated entry must not be staged")
+	})
 }
 
-func TestService_Create_UntrackedFiles(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+func TestService_Restore_GlobScopeRecoversDeletedFile(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-untracked"
-	scopeFile := "scope.txt"
-	untrackedFile := "untracked.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-glob-restore"
+		createMissionFile(t, fs, missionID, []string{"pkg/"})
 
-	// Create files in afero FS
-	err := afero.WriteFile(fs, scopeFile, []byte("scope content"), 0644)
-	require.NoError(t, err)
-	err = afero.WriteFile(fs, untrackedFile, []byte("untracked content"), 0644)
-	require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, "pkg/a.go", []byte("v1"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "pkg/b.go", []byte("v1"), 0644))
 
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Create checkpoint
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	// Verify commit content
-	tagRef, err := repo.Tag(name)
-	require.NoError(t, err)
-	tagObj, err := repo.TagObject(tagRef.Hash())
-	require.NoError(t, err)
-	commit, err := repo.CommitObject(tagObj.Target)
-	require.NoError(t, err)
+		// Modify one file and delete the other; the deleted file no longer matches on disk
+		require.NoError(t, afero.WriteFile(fs, "pkg/a.go", []byte("v2"), 0644))
+		require.NoError(t, fs.Remove("pkg/b.go"))
 
-	// Check scope file is in commit
-	_, err = commit.File(scopeFile)
-	require.NoError(t, err)
+		require.NoError(t, svc.Restore(t.Context(), name))
 
-	// Check untracked file is NOT in commit
-	_, err = commit.File(untrackedFile)
-	require.Error(t, err)
+		content, err := afero.ReadFile(fs, "pkg/a.go")
+		require.NoError(t, err)
+		require.Equal(t, "v1", string(content))
+		content, err = afero.ReadFile(fs, "pkg/b.go")
+		require.NoError(t, err)
+		require.Equal(t, "v1", string(content))
+	})
 }
 
-func TestService_Create_GitIgnoredFiles(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-ignored"
-	scopeFile := "ignored.log" // Assume .log is ignored
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	// Create .gitignore
-	wt, _ := repo.Worktree()
-	f, _ := wt.Filesystem.Create(".gitignore")
-	_, err := f.Write([]byte("*.log\n"))
-	require.NoError(t, err)
-	err = f.Close()
-	require.NoError(t, err)
-	_, err = wt.Add(".gitignore")
-	require.NoError(t, err)
-	_, err = wt.Commit("Add gitignore", &git.CommitOptions{
-		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+func TestService_Create_UntrackedFiles(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-untracked"
+		scopeFile := "scope.txt"
+		untrackedFile := "untracked.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		// Create files in afero FS
+		err := afero.WriteFile(fs, scopeFile, []byte("scope content"), 0644)
+		require.NoError(t, err)
+		err = afero.WriteFile(fs, untrackedFile, []byte("untracked content"), 0644)
+		require.NoError(t, err)
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create checkpoint
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Verify commit content
+		commit := checkpointCommit(t, repo, svc, name)
+
+		// Check scope file is in commit
+		_, err = commit.File(scopeFile)
+		require.NoError(t, err)
+
+		// Check untracked file is NOT in commit
+		_, err = commit.File(untrackedFile)
+		require.Error(t, err)
 	})
-	require.NoError(t, err)
-
-	// Create ignored file in afero FS
-	err = afero.WriteFile(fs, scopeFile, []byte("ignored content"), 0644)
-	require.NoError(t, err)
-
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Create checkpoint - should succeed even if ignored because it's in scope
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
-
-	// Verify commit content
-	tagRef, err := repo.Tag(name)
-	require.NoError(t, err)
-	tagObj, err := repo.TagObject(tagRef.Hash())
-	require.NoError(t, err)
-	commit, err := repo.CommitObject(tagObj.Target)
-	require.NoError(t, err)
+}
 
-	// Check ignored file IS in commit because it was in scope
-	_, err = commit.File(scopeFile)
-	require.NoError(t, err)
+func TestService_Create_GitIgnoredFiles(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-ignored"
+		scopeFile := "ignored.log" // Assume .log is ignored
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		// Create .gitignore
+		wt, _ := repo.Worktree()
+		f, _ := wt.Filesystem.Create(".gitignore")
+		_, err := f.Write([]byte("*.log\n"))
+		require.NoError(t, err)
+		err = f.Close()
+		require.NoError(t, err)
+		_, err = wt.Add(".gitignore")
+		require.NoError(t, err)
+		_, err = wt.Commit("Add gitignore", &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+		})
+		require.NoError(t, err)
+
+		// Create ignored file in afero FS
+		err = afero.WriteFile(fs, scopeFile, []byte("ignored content"), 0644)
+		require.NoError(t, err)
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create checkpoint - should succeed even if ignored because it's in scope
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Verify commit content
+		commit := checkpointCommit(t, repo, svc, name)
+
+		// Check ignored file IS in commit because it was in scope
+		_, err = commit.File(scopeFile)
+		require.NoError(t, err)
+	})
 }
 
 func TestService_Restore(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-restore"
-	scopeFile := "restore.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-restore"
+		scopeFile := "restore.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	// Initial state v1
-	err := afero.WriteFile(fs, scopeFile, []byte("v1"), 0644)
-	require.NoError(t, err)
+		// Initial state v1
+		err := afero.WriteFile(fs, scopeFile, []byte("v1"), 0644)
+		require.NoError(t, err)
 
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Create checkpoint v1
-	name1, err := svc.Create(missionID)
-	require.NoError(t, err)
+		// Create checkpoint v1
+		name1, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	// Modify to v2
-	err = afero.WriteFile(fs, scopeFile, []byte("v2"), 0644)
-	require.NoError(t, err)
+		// Modify to v2
+		err = afero.WriteFile(fs, scopeFile, []byte("v2"), 0644)
+		require.NoError(t, err)
 
-	// Restore v1
-	err = svc.Restore(name1)
-	require.NoError(t, err)
+		// Restore v1
+		err = svc.Restore(t.Context(), name1)
+		require.NoError(t, err)
 
-	// Verify content in afero fs is v1
-	content, err := afero.ReadFile(fs, scopeFile)
-	require.NoError(t, err)
-	require.Equal(t, "v1", string(content))
+		// Verify content in afero fs is v1
+		content, err := afero.ReadFile(fs, scopeFile)
+		require.NoError(t, err)
+		require.Equal(t, "v1", string(content))
+	})
 }
 
 func TestService_Restore_UntrackedFiles(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-restore-untracked"
-	scopeFile := "restore.txt"
-	untrackedFile := "untracked.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	// Initial state
-	err := afero.WriteFile(fs, scopeFile, []byte("v1"), 0644)
-	require.NoError(t, err)
-
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Create checkpoint
-	name1, err := svc.Create(missionID)
-	require.NoError(t, err)
-
-	// Create untracked file
-	err = afero.WriteFile(fs, untrackedFile, []byte("untracked"), 0644)
-	require.NoError(t, err)
-
-	// Restore
-	err = svc.Restore(name1)
-	require.NoError(t, err)
-
-	// Verify untracked file still exists and is untouched
-	content, err := afero.ReadFile(fs, untrackedFile)
-	require.NoError(t, err)
-	require.Equal(t, "untracked", string(content))
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-restore-untracked"
+		scopeFile := "restore.txt"
+		untrackedFile := "untracked.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		// Initial state
+		err := afero.WriteFile(fs, scopeFile, []byte("v1"), 0644)
+		require.NoError(t, err)
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create checkpoint
+		name1, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Create untracked file
+		err = afero.WriteFile(fs, untrackedFile, []byte("untracked"), 0644)
+		require.NoError(t, err)
+
+		// Restore
+		err = svc.Restore(t.Context(), name1)
+		require.NoError(t, err)
+
+		// Verify untracked file still exists and is untouched
+		content, err := afero.ReadFile(fs, untrackedFile)
+		require.NoError(t, err)
+		require.Equal(t, "untracked", string(content))
+	})
 }
 
 func TestService_Clear(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-clear"
-	scopeFile := "clear.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-clear"
+		scopeFile := "clear.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	err := afero.WriteFile(fs, scopeFile, []byte("content"), 0644)
-	require.NoError(t, err)
+		err := afero.WriteFile(fs, scopeFile, []byte("content"), 0644)
+		require.NoError(t, err)
 
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Create checkpoints
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
+		// Create checkpoints
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	err = afero.WriteFile(fs, scopeFile, []byte("content2"), 0644)
-	require.NoError(t, err)
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
+		err = afero.WriteFile(fs, scopeFile, []byte("content2"), 0644)
+		require.NoError(t, err)
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	// Clear
-	count, err := svc.Clear(missionID)
-	require.NoError(t, err)
-	require.Equal(t, 3, count) // 2 checkpoints + 1 baseline tag
+		// Clear
+		count, err := svc.Clear(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, 3, count) // 2 checkpoints + 1 baseline tag
 
-	// Verify tags gone
-	tags, _ := gitClient.ListTags(missionID)
-	require.Empty(t, tags)
+		// Verify tags gone
+		tags, _ := svc.store.names(t.Context(), missionID)
+		require.Empty(t, tags)
+	})
 }
 
 func TestService_Consolidate(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-consolidate"
-	scopeFile1 := "file1.txt"
-	scopeFile2 := "file2.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile1, scopeFile2})
-
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// --- Checkpoint 1 ---
-	err := afero.WriteFile(fs, scopeFile1, []byte("v1"), 0644)
-	require.NoError(t, err)
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
-
-	// --- Checkpoint 2 ---
-	err = afero.WriteFile(fs, scopeFile2, []byte("v1"), 0644)
-	require.NoError(t, err)
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
-
-	// --- Final change (no checkpoint) ---
-	err = afero.WriteFile(fs, scopeFile1, []byte("v2"), 0644)
-	require.NoError(t, err)
-
-	// Consolidate
-	commitMsg := "Final commit"
-	result, err := svc.Consolidate(missionID, commitMsg)
-	require.NoError(t, err)
-	require.NotNil(t, result)
-
-	// Verify final commit
-	commit, err := repo.CommitObject(plumbing.NewHash(result.CommitHash))
-	require.NoError(t, err)
-	require.Equal(t, commitMsg, commit.Message)
-
-	// Verify file contents in final commit
-	f1, err := commit.File(scopeFile1)
-	require.NoError(t, err)
-	content1, _ := f1.Contents()
-	require.Equal(t, "v2", content1)
-
-	f2, err := commit.File(scopeFile2)
-	require.NoError(t, err)
-	content2, _ := f2.Contents()
-	require.Equal(t, "v1", content2)
-
-	// Verify checkpoints are cleared
-	tags, err := gitClient.ListTags(missionID)
-	require.NoError(t, err)
-	require.Empty(t, tags)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-consolidate"
+		scopeFile1 := "file1.txt"
+		scopeFile2 := "file2.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile1, scopeFile2})
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// --- Checkpoint 1 ---
+		err := afero.WriteFile(fs, scopeFile1, []byte("v1"), 0644)
+		require.NoError(t, err)
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// --- Checkpoint 2 ---
+		err = afero.WriteFile(fs, scopeFile2, []byte("v1"), 0644)
+		require.NoError(t, err)
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// --- Final change (no checkpoint) ---
+		err = afero.WriteFile(fs, scopeFile1, []byte("v2"), 0644)
+		require.NoError(t, err)
+
+		// Consolidate
+		commitMsg := "Final commit"
+		result, err := svc.Consolidate(t.Context(), missionID, commitMsg)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+
+		// Verify final commit
+		commit, err := repo.CommitObject(plumbing.NewHash(result.CommitHash))
+		require.NoError(t, err)
+		require.Equal(t, commitMsg+"\n\nMission-Id: test-consolidate\nMission-Track: 1\nMission-Type: WET", commit.Message)
+
+		// Verify file contents in final commit
+		f1, err := commit.File(scopeFile1)
+		require.NoError(t, err)
+		content1, _ := f1.Contents()
+		require.Equal(t, "v2", content1)
+
+		f2, err := commit.File(scopeFile2)
+		require.NoError(t, err)
+		content2, _ := f2.Contents()
+		require.Equal(t, "v1", content2)
+
+		// Verify checkpoints are cleared
+		tags, err := svc.store.names(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Empty(t, tags)
+	})
 }
 
 func TestService_Consolidate_NoChanges(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-consolidate-no-changes"
-	scopeFile := "file.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Create file and commit it initially (so it's tracked)
-	err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
-	require.NoError(t, err)
-	wt, _ := repo.Worktree()
-	f, _ := wt.Filesystem.Create(scopeFile)
-	f.Write([]byte("initial"))
-	f.Close()
-	wt.Add(scopeFile)
-	wt.Commit("Initial state", &git.CommitOptions{
-		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-consolidate-no-changes"
+		scopeFile := "file.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create file and commit it initially (so it's tracked)
+		err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
+		require.NoError(t, err)
+		wt, _ := repo.Worktree()
+		f, _ := wt.Filesystem.Create(scopeFile)
+		f.Write([]byte("initial"))
+		f.Close()
+		wt.Add(scopeFile)
+		wt.Commit("Initial state", &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+		})
+
+		// Try to consolidate without any changes
+		_, err = svc.Consolidate(t.Context(), missionID, "Final commit")
+		require.ErrorContains(t, err, "creating final commit")
 	})
-
-	// Try to consolidate without any changes
-	_, err = svc.Consolidate(missionID, "Final commit")
-	require.ErrorContains(t, err, "creating final commit")
 }
 
 func TestService_Consolidate_WithUntrackedFile(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-consolidate-untracked"
-	scopeFile := "scope.txt"
-	untrackedFile := "untracked.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Modify scope file
-	err := afero.WriteFile(fs, scopeFile, []byte("v1"), 0644)
-	require.NoError(t, err)
-
-	// Create untracked file in worktree fs
-	wt, _ := repo.Worktree()
-	f, _ := wt.Filesystem.Create(untrackedFile)
-	f.Write([]byte("untracked"))
-	f.Close()
-
-	// Consolidate
-	result, err := svc.Consolidate(missionID, "Final commit")
-	require.NoError(t, err)
-	require.NotNil(t, result)
-
-	// Verify final commit
-	commit, err := repo.CommitObject(plumbing.NewHash(result.CommitHash))
-	require.NoError(t, err)
-
-	// Check scope file is in commit
-	_, err = commit.File(scopeFile)
-	require.NoError(t, err)
-
-	// Check untracked file is NOT in commit
-	_, err = commit.File(untrackedFile)
-	require.Error(t, err)
-
-	// Verify unstaged files includes the untracked file
-	require.Contains(t, result.UnstagedFiles, untrackedFile)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-consolidate-untracked"
+		scopeFile := "scope.txt"
+		untrackedFile := "untracked.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Modify scope file
+		err := afero.WriteFile(fs, scopeFile, []byte("v1"), 0644)
+		require.NoError(t, err)
+
+		// Create untracked file in worktree fs
+		wt, _ := repo.Worktree()
+		f, _ := wt.Filesystem.Create(untrackedFile)
+		f.Write([]byte("untracked"))
+		f.Close()
+
+		// Consolidate
+		result, err := svc.Consolidate(t.Context(), missionID, "Final commit")
+		require.NoError(t, err)
+		require.NotNil(t, result)
+
+		// Verify final commit
+		commit, err := repo.CommitObject(plumbing.NewHash(result.CommitHash))
+		require.NoError(t, err)
+
+		// Check scope file is in commit
+		_, err = commit.File(scopeFile)
+		require.NoError(t, err)
+
+		// Check untracked file is NOT in commit
+		_, err = commit.File(untrackedFile)
+		require.Error(t, err)
+
+		// Verify unstaged files includes the untracked file
+		require.Contains(t, result.UnstagedFiles, untrackedFile)
+	})
 }
 
 func TestService_Consolidate_WithFileDeletion(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-consolidate-deletion"
-	scopeFile := "file.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	// Use MemGitClient
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Create file and commit it initially
-	err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
-	require.NoError(t, err)
-	wt, _ := repo.Worktree()
-	f, _ := wt.Filesystem.Create(scopeFile)
-	f.Write([]byte("initial"))
-	f.Close()
-	wt.Add(scopeFile)
-	wt.Commit("Initial state", &git.CommitOptions{
-		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-consolidate-deletion"
+		scopeFile := "file.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		// Use MemGitClient
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create file and commit it initially
+		err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
+		require.NoError(t, err)
+		wt, _ := repo.Worktree()
+		f, _ := wt.Filesystem.Create(scopeFile)
+		f.Write([]byte("initial"))
+		f.Close()
+		wt.Add(scopeFile)
+		wt.Commit("Initial state", &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+		})
+
+		// Delete file
+		err = fs.Remove(scopeFile)
+		require.NoError(t, err)
+
+		// Consolidate
+		result, err := svc.Consolidate(t.Context(), missionID, "Final commit")
+		require.NoError(t, err)
+		require.NotNil(t, result)
+
+		// Verify final commit
+		commit, err := repo.CommitObject(plumbing.NewHash(result.CommitHash))
+		require.NoError(t, err)
+
+		// Check file is NOT in commit
+		_, err = commit.File(scopeFile)
+		require.Error(t, err)
 	})
-
-	// Delete file
-	err = fs.Remove(scopeFile)
-	require.NoError(t, err)
-
-	// Consolidate
-	result, err := svc.Consolidate(missionID, "Final commit")
-	require.NoError(t, err)
-	require.NotNil(t, result)
-
-	// Verify final commit
-	commit, err := repo.CommitObject(plumbing.NewHash(result.CommitHash))
-	require.NoError(t, err)
-
-	// Check file is NOT in commit
-	_, err = commit.File(scopeFile)
-	require.Error(t, err)
 }
 
 // Edge case tests for checkpoint service
@@ -555,302 +649,386 @@ func TestService_Consolidate_WithFileDeletion(t *testing.T) {
 // TestService_Create_InvalidMissionIDFormat verifies that Create fails gracefully
 // when the mission ID is empty or invalid.
 func TestService_Create_InvalidMissionIDFormat(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := ""
-	createMissionFile(t, fs, missionID, []string{"test.txt"})
+		missionID := ""
+		createMissionFile(t, fs, missionID, []string{"test.txt"})
 
-	// Create test file in git worktree
-	wt, _ := repo.Worktree()
-	f, _ := wt.Filesystem.Create("test.txt")
-	f.Write([]byte("content"))
-	f.Close()
+		// Create test file in git worktree
+		wt, _ := repo.Worktree()
+		f, _ := wt.Filesystem.Create("test.txt")
+		f.Write([]byte("content"))
+		f.Close()
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Test with empty mission ID - should fail
-	_, err := svc.Create(missionID)
-	require.Error(t, err)
+		// Test with empty mission ID - should fail
+		_, err := svc.Create(t.Context(), missionID)
+		require.Error(t, err)
+	})
 }
 
 // TestService_Create_MultipleCheckpoints verifies that multiple checkpoints
 // can be created for the same mission with incrementing numbers.
 func TestService_Create_MultipleCheckpoints(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	scopeFile := "test.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-mission"
+		scopeFile := "test.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
-	require.NoError(t, err)
+		err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
+		require.NoError(t, err)
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Create first checkpoint successfully
-	name1, err := svc.Create(missionID)
-	require.NoError(t, err)
-	require.Equal(t, fmt.Sprintf("%s-1", missionID), name1)
+		// Create first checkpoint successfully
+		name1, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%s-1", missionID), name1)
 
-	// Verify tag exists
-	_, err = repo.Tag(name1)
-	require.NoError(t, err)
+		// Verify tag exists
+		require.True(t, checkpointExists(svc, name1))
+	})
 }
 
 // TestService_Restore_NonExistentCheckpoint verifies that Restore fails with
 // an appropriate error when attempting to restore a checkpoint that doesn't exist.
 func TestService_Restore_NonExistentCheckpoint(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	createMissionFile(t, fs, missionID, []string{"test.txt"})
+		missionID := "test-mission"
+		createMissionFile(t, fs, missionID, []string{"test.txt"})
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Try to restore non-existent checkpoint
-	err := svc.Restore("non-existent-checkpoint")
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "tag not found")
+		// Try to restore non-existent checkpoint
+		err := svc.Restore(t.Context(), "non-existent-checkpoint")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "checkpoint non-existent-checkpoint not found")
+	})
 }
 
 func TestService_Restore_GitOperationsFail(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	scopeFile := "test.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-mission"
+		scopeFile := "test.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
-	require.NoError(t, err)
+		err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
+		require.NoError(t, err)
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Create checkpoint
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
+		// Create checkpoint
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	// Modify file
-	err = afero.WriteFile(fs, scopeFile, []byte("modified"), 0644)
-	require.NoError(t, err)
+		// Modify file
+		err = afero.WriteFile(fs, scopeFile, []byte("modified"), 0644)
+		require.NoError(t, err)
 
-	// Restore should work
-	err = svc.Restore(name)
-	require.NoError(t, err)
+		// Restore should work
+		err = svc.Restore(t.Context(), name)
+		require.NoError(t, err)
 
-	// Verify file was restored
-	content, err := afero.ReadFile(fs, scopeFile)
-	require.NoError(t, err)
-	require.Equal(t, "initial", string(content))
+		// Verify file was restored
+		content, err := afero.ReadFile(fs, scopeFile)
+		require.NoError(t, err)
+		require.Equal(t, "initial", string(content))
+	})
 }
 
 func TestService_Clear_NoCheckpointsToClean(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	missionID := "test-mission"
+		missionID := "test-mission"
 
-	// Clear when no checkpoints exist
-	count, err := svc.Clear(missionID)
-	require.NoError(t, err)
-	require.Equal(t, 0, count)
+		// Clear when no checkpoints exist
+		count, err := svc.Clear(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	})
 }
 
 func TestService_Clear_RemovesAllCheckpoints(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	scopeFile := "test.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-mission"
+		scopeFile := "test.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	err := afero.WriteFile(fs, scopeFile, []byte("content"), 0644)
-	require.NoError(t, err)
+		err := afero.WriteFile(fs, scopeFile, []byte("content"), 0644)
+		require.NoError(t, err)
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Create checkpoint (creates baseline tag)
-	name, err := svc.Create(missionID)
-	require.NoError(t, err)
+		// Create checkpoint (creates baseline tag)
+		name, err := svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
 
-	// Verify baseline tag exists
-	baselineTag := fmt.Sprintf("%s-baseline", missionID)
-	_, err = repo.Tag(baselineTag)
-	require.NoError(t, err)
+		// Verify baseline tag exists
+		baselineTag := fmt.Sprintf("%s-baseline", missionID)
+		require.True(t, checkpointExists(svc, baselineTag))
 
-	// Clear should work and remove checkpoint (count includes baseline)
-	count, err := svc.Clear(missionID)
-	require.NoError(t, err)
-	require.GreaterOrEqual(t, count, 1)
+		// Clear should work and remove checkpoint (count includes baseline)
+		count, err := svc.Clear(t.Context(), missionID)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, count, 1)
 
-	// Verify checkpoint tag is removed
-	_, err = repo.Tag(name)
-	require.Error(t, err)
+		// Verify checkpoint tag is removed
+		require.False(t, checkpointExists(svc, name))
+	})
 }
 
 func TestService_Consolidate_EmptyScope(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	createMissionFile(t, fs, missionID, []string{}) // Empty scope
+		missionID := "test-mission"
+		createMissionFile(t, fs, missionID, []string{}) // Empty scope
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Consolidate with empty scope should fail
-	_, err := svc.Consolidate(missionID, "Empty commit")
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "no files in mission scope")
+		// Consolidate with empty scope should fail
+		_, err := svc.Consolidate(t.Context(), missionID, "Empty commit")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no files in mission scope")
+	})
 }
 
 func TestService_Consolidate_UnstagedFiles(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-mission"
-	scopeFile := "test.txt"
-	unstagedFile := "unstaged.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Create scope file in both filesystems
-	err := afero.WriteFile(fs, scopeFile, []byte("content"), 0644)
-	require.NoError(t, err)
-
-	wt, _ := repo.Worktree()
-	f, _ := wt.Filesystem.Create(scopeFile)
-	f.Write([]byte("content"))
-	f.Close()
-
-	// Create unstaged file (not in scope) in git worktree only
-	f2, _ := wt.Filesystem.Create(unstagedFile)
-	f2.Write([]byte("unstaged"))
-	f2.Close()
-
-	// Consolidate
-	result, err := svc.Consolidate(missionID, "Commit with unstaged")
-	require.NoError(t, err)
-	require.NotNil(t, result)
-
-	// Verify unstaged file is reported
-	require.Contains(t, result.UnstagedFiles, unstagedFile)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-mission"
+		scopeFile := "test.txt"
+		unstagedFile := "unstaged.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create scope file in both filesystems
+		err := afero.WriteFile(fs, scopeFile, []byte("content"), 0644)
+		require.NoError(t, err)
+
+		wt, _ := repo.Worktree()
+		f, _ := wt.Filesystem.Create(scopeFile)
+		f.Write([]byte("content"))
+		f.Close()
+
+		// Create unstaged file (not in scope) in git worktree only
+		f2, _ := wt.Filesystem.Create(unstagedFile)
+		f2.Write([]byte("unstaged"))
+		f2.Close()
+
+		// Consolidate
+		result, err := svc.Consolidate(t.Context(), missionID, "Commit with unstaged")
+		require.NoError(t, err)
+		require.NotNil(t, result)
+
+		// Verify unstaged file is reported
+		require.Contains(t, result.UnstagedFiles, unstagedFile)
+	})
 }
 
 func TestService_Consolidate_CommitCreationFails(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-mission"
-	createMissionFile(t, fs, missionID, []string{"test.txt"})
+		missionID := "test-mission"
+		createMissionFile(t, fs, missionID, []string{"test.txt"})
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Try to consolidate with empty commit message (should fail)
-	_, err := svc.Consolidate(missionID, "")
-	require.Error(t, err)
+		// Try to consolidate with empty commit message (should fail)
+		_, err := svc.Consolidate(t.Context(), missionID, "")
+		require.Error(t, err)
+	})
 }
 
 func TestService_RestoreAll(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-restore-all"
-	scopeFile := "restore-all.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
-	require.NoError(t, err)
-
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Create first checkpoint (baseline)
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
-
-	// Modify file and create second checkpoint
-	err = afero.WriteFile(fs, scopeFile, []byte("modified"), 0644)
-	require.NoError(t, err)
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
-
-	// Verify file is modified
-	content, err := afero.ReadFile(fs, scopeFile)
-	require.NoError(t, err)
-	require.Equal(t, "modified", string(content))
-
-	// RestoreAll should revert to baseline
-	count, untrackedFiles, err := svc.RestoreAll(missionID)
-	require.NoError(t, err)
-	require.Equal(t, 3, count) // 2 checkpoints + 1 baseline tag
-	require.Empty(t, untrackedFiles)
-
-	// Verify file reverted to initial state
-	content, err = afero.ReadFile(fs, scopeFile)
-	require.NoError(t, err)
-	require.Equal(t, "initial", string(content))
-
-	// Verify all tags deleted
-	tags, _ := gitClient.ListTags(missionID)
-	require.Empty(t, tags)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-restore-all"
+		scopeFile := "restore-all.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
+		require.NoError(t, err)
+
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create first checkpoint (baseline)
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Modify file and create second checkpoint
+		err = afero.WriteFile(fs, scopeFile, []byte("modified"), 0644)
+		require.NoError(t, err)
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Verify file is modified
+		content, err := afero.ReadFile(fs, scopeFile)
+		require.NoError(t, err)
+		require.Equal(t, "modified", string(content))
+
+		// RestoreAll should revert to baseline
+		count, untrackedFiles, err := svc.RestoreAll(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, 3, count) // 2 checkpoints + 1 baseline tag
+		require.Empty(t, untrackedFiles)
+
+		// Verify file reverted to initial state
+		content, err = afero.ReadFile(fs, scopeFile)
+		require.NoError(t, err)
+		require.Equal(t, "initial", string(content))
+
+		// Verify all tags deleted
+		tags, _ := svc.store.names(t.Context(), missionID)
+		require.Empty(t, tags)
+	})
 }
 
 func TestService_RestoreAll_WithUntrackedFiles(t *testing.T) {
-	fs, repo := setupTestRepo(t)
-
-	missionID := "test-restore-untracked"
-	scopeFile := "tracked.txt"
-	untrackedFile := "untracked.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
-
-	err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
-	require.NoError(t, err)
-
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
-
-	// Create baseline checkpoint
-	_, err = svc.Create(missionID)
-	require.NoError(t, err)
-
-	// Create untracked file in git worktree (not in git)
-	wt, err := repo.Worktree()
-	require.NoError(t, err)
-	f, err := wt.Filesystem.Create(untrackedFile)
-	require.NoError(t, err)
-	_, err = f.Write([]byte("untracked content"))
-	require.NoError(t, err)
-	err = f.Close()
-	require.NoError(t, err)
-
-	// RestoreAll should succeed and report untracked file
-	count, untrackedFiles, err := svc.RestoreAll(missionID)
-	require.NoError(t, err)
-	require.Equal(t, 2, count) // 1 checkpoint + 1 baseline tag
-	require.Len(t, untrackedFiles, 1)
-	require.Contains(t, untrackedFiles, untrackedFile)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
+
+		missionID := "test-restore-untracked"
+		scopeFile := "tracked.txt"
+		untrackedFile := "untracked.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
+
+		err := afero.WriteFile(fs, scopeFile, []byte("initial"), 0644)
+		require.NoError(t, err)
+
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
+
+		// Create baseline checkpoint
+		_, err = svc.Create(t.Context(), missionID)
+		require.NoError(t, err)
+
+		// Create untracked file in the git worktree and the service filesystem (not in git)
+		wt, err := repo.Worktree()
+		require.NoError(t, err)
+		f, err := wt.Filesystem.Create(untrackedFile)
+		require.NoError(t, err)
+		_, err = f.Write([]byte("untracked content"))
+		require.NoError(t, err)
+		err = f.Close()
+		require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, untrackedFile, []byte("untracked content"), 0644))
+
+		// RestoreAll should succeed and report untracked file
+		count, untrackedFiles, err := svc.RestoreAll(t.Context(), missionID)
+		require.NoError(t, err)
+		require.Equal(t, 2, count) // 1 checkpoint + 1 baseline tag
+		require.Len(t, untrackedFiles, 1)
+		require.Contains(t, untrackedFiles, untrackedFile)
+
+		// Verify untracked file still exists in worktree
+		_, err = wt.Filesystem.Stat(untrackedFile)
+		require.NoError(t, err)
+	})
+}
 
-	// Verify untracked file still exists in worktree
-	_, err = wt.Filesystem.Stat(untrackedFile)
-	require.NoError(t, err)
+func TestService_RestoreAll_RemovesCreatedFiles(t *testing.T) {
+	scopes := []struct {
+		name  string
+		scope []string
+		dir   string
+	}{
+		{name: "literal", scope: []string{"existing.txt", "new.txt"}},
+		{name: "directory", scope: []string{"pkg/"}, dir: "pkg/"},
+		{name: "glob", scope: []string{"pkg/**/*.txt"}, dir: "pkg/"},
+	}
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, sc := range scopes {
+			for _, keepNew := range []bool{false, true} {
+				t.Run(fmt.Sprintf("%s scope keep new %v", sc.name, keepNew), func(t *testing.T) {
+					fs, repo := setupTestRepo(t)
+					missionID := "test-restore-new"
+					existing, created := sc.dir+"existing.txt", sc.dir+"new.txt"
+					createMissionFile(t, fs, missionID, sc.scope)
+					require.NoError(t, fs.MkdirAll("pkg", 0755))
+					require.NoError(t, afero.WriteFile(fs, existing, []byte("initial"), 0644))
+
+					gitClient := internalgit.NewMemGitClient(repo, fs)
+					svc := newTestService(t, fs, gitClient, backend)
+					_, err := svc.Create(t.Context(), missionID)
+					require.NoError(t, err)
+
+					require.NoError(t, afero.WriteFile(fs, created, []byte("created by the mission"), 0644))
+					_, err = svc.Create(t.Context(), missionID)
+					require.NoError(t, err)
+					// Untracked work that was never in scope
+					require.NoError(t, afero.WriteFile(fs, "notes.txt", []byte("unrelated"), 0644))
+
+					result, err := svc.RestoreAllWithOptions(t.Context(), missionID, RestoreAllOptions{KeepNew: keepNew})
+					require.NoError(t, err)
+					assert.Equal(t, 3, result.Cleared)
+
+					exists, err := afero.Exists(fs, created)
+					require.NoError(t, err)
+					assert.Equal(t, keepNew, exists)
+					if keepNew {
+						assert.Empty(t, result.Removed)
+					} else {
+						assert.Equal(t, []string{created}, result.Removed)
+						tracked, err := gitClient.IsTracked(t.Context(), created)
+						require.NoError(t, err)
+						assert.False(t, tracked, "removed files are not left staged")
+					}
+					for _, kept := range []string{existing, "notes.txt"} {
+						exists, err = afero.Exists(fs, kept)
+						require.NoError(t, err)
+						assert.True(t, exists, "%s existed before the mission and is left alone", kept)
+					}
+
+					metadata, err := svc.loadMetadata()
+					require.NoError(t, err)
+					assert.Empty(t, metadata)
+				})
+			}
+		}
+	})
 }
 
 func TestService_RestoreAll_NoBaseline(t *testing.T) {
-	fs, repo := setupTestRepo(t)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		fs, repo := setupTestRepo(t)
 
-	missionID := "test-no-baseline"
-	scopeFile := "test.txt"
-	createMissionFile(t, fs, missionID, []string{scopeFile})
+		missionID := "test-no-baseline"
+		scopeFile := "test.txt"
+		createMissionFile(t, fs, missionID, []string{scopeFile})
 
-	gitClient := internalgit.NewMemGitClient(repo, fs)
-	svc := NewServiceWithGit(fs, ".mission", gitClient)
+		gitClient := internalgit.NewMemGitClient(repo, fs)
+		svc := newTestService(t, fs, gitClient, backend)
 
-	// Try to restore without creating any checkpoints
-	_, _, err := svc.RestoreAll(missionID)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "getting baseline commit")
+		// Try to restore without creating any checkpoints
+		_, _, err := svc.RestoreAll(t.Context(), missionID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "getting baseline commit")
+	})
 }
@@ -0,0 +1,303 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+)
+
+// Checkpoint backends accepted by NewServiceWithBackend
+const (
+	// BackendTags commits checkpoints on the current branch and tags them <id>-N
+	BackendTags = "tags"
+	// BackendRefs writes checkpoint snapshots to refs/mission/<id>/<n> without moving HEAD
+	BackendRefs = "refs"
+	// BackendFS keeps content-addressed snapshots under .mission/snapshots and needs no git
+	BackendFS = "fs"
+)
+
+// missionRefPrefix is the namespace of the refs backend
+const missionRefPrefix = "refs/mission/"
+
+// store keeps the snapshots behind checkpoints. Checkpoints are named <id>-N and
+// <id>-baseline whichever store holds them.
+type store interface {
+	// ref returns the git ref of a checkpoint name
+	ref(name string) string
+	// names lists the checkpoint names of a mission
+	names(ctx context.Context, missionID string) ([]string, error)
+	// create records the mission scope as checkpoint <id>-num and returns its commit
+	create(ctx context.Context, missionID string, num int) (string, error)
+	// mark points checkpoint name at a commit
+	mark(ctx context.Context, name, commitHash string) error
+	// remove deletes checkpoint name
+	remove(ctx context.Context, name string) error
+	// onBranch reports whether checkpoints are commits on the current branch, which
+	// must be squashed or reset away when the mission ends
+	onBranch() bool
+
+	snapshots
+}
+
+// snapshots reads and restores the states checkpoints point at. A revision is a ref
+// returned by store.ref or a hash returned by resolve.
+type snapshots interface {
+	// resolve returns the hash of the snapshot at rev
+	resolve(ctx context.Context, rev string) (string, error)
+	// createdAt returns when the snapshot at hash was taken, if the store records it
+	createdAt(ctx context.Context, hash string) (time.Time, error)
+	// since returns the hash the first checkpoint of a mission is compared against
+	since(ctx context.Context, hash string) string
+	// deleted lists the files in rev that are missing from the working tree
+	deleted(ctx context.Context, rev string) ([]string, error)
+	// restore writes the versions of files in rev to the working tree, leaving files
+	// that rev does not have alone
+	restore(ctx context.Context, rev string, files []string) error
+	// diff returns the changes between two revisions; an empty to is the working tree
+	diff(ctx context.Context, from, to string, paths []string) ([]git.FileDiff, error)
+	// diffStat returns the per-file line counts of the changes between two revisions
+	diffStat(ctx context.Context, from, to string) ([]git.FileStat, error)
+	// changes lists the files changed in the working tree since rev
+	changes(ctx context.Context, rev string) ([]git.FileChange, error)
+	// created lists the files in the working tree that did not exist in the baseline
+	// and need cleaning up by hand
+	created(ctx context.Context, baseline string) ([]string, error)
+}
+
+// newStore returns the store for a backend name, defaulting to tags, or to fs when
+// there is no git client
+func newStore(s *Service, backend string) (store, error) {
+	if backend == "" {
+		backend = BackendTags
+		if s.git == nil {
+			backend = BackendFS
+		}
+	}
+	if s.git == nil && backend != BackendFS {
+		return nil, fmt.Errorf("checkpoint backend %s needs a git repository (use %s)", backend, BackendFS)
+	}
+
+	switch backend {
+	case BackendTags:
+		return &tagStore{gitSnapshots{s: s, branch: true}}, nil
+	case BackendRefs:
+		return &refStore{gitSnapshots{s: s}}, nil
+	case BackendFS:
+		return &fsStore{s: s}, nil
+	}
+	return nil, fmt.Errorf("unknown checkpoint backend %q (use %s, %s or %s)", backend, BackendTags, BackendRefs, BackendFS)
+}
+
+// gitSnapshots reads snapshots that are git commits
+type gitSnapshots struct {
+	s *Service
+	// branch is set when the snapshots are commits on the current branch
+	branch bool
+}
+
+func (g gitSnapshots) onBranch() bool {
+	return g.branch
+}
+
+func (g gitSnapshots) resolve(ctx context.Context, rev string) (string, error) {
+	return g.s.git.GetTagCommit(ctx, rev)
+}
+
+func (g gitSnapshots) createdAt(ctx context.Context, hash string) (time.Time, error) {
+	return g.s.git.GetCommitTime(ctx, hash)
+}
+
+// since compares a checkpoint commit with the commit it was created on, unless it only
+// tagged an existing commit because nothing had changed
+func (g gitSnapshots) since(ctx context.Context, hash string) string {
+	if msg, err := g.s.git.GetCommitMessage(ctx, hash); err == nil && strings.HasPrefix(msg, "checkpoint:") {
+		if parent, err := g.s.git.GetCommitParent(ctx, hash); err == nil && parent != "" {
+			return parent
+		}
+	}
+	return hash
+}
+
+func (g gitSnapshots) deleted(ctx context.Context, rev string) ([]string, error) {
+	changes, err := g.s.git.GetChangedFiles(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for _, change := range changes {
+		if change.Change == git.ChangeDeleted {
+			deleted = append(deleted, change.Path)
+		}
+	}
+	return deleted, nil
+}
+
+func (g gitSnapshots) restore(ctx context.Context, rev string, files []string) error {
+	return g.s.git.Restore(ctx, rev, files)
+}
+
+func (g gitSnapshots) diff(ctx context.Context, from, to string, paths []string) ([]git.FileDiff, error) {
+	return g.s.git.Diff(ctx, from, to, paths)
+}
+
+func (g gitSnapshots) diffStat(ctx context.Context, from, to string) ([]git.FileStat, error) {
+	return g.s.git.DiffStat(ctx, from, to)
+}
+
+func (g gitSnapshots) changes(ctx context.Context, rev string) ([]git.FileChange, error) {
+	return g.s.git.GetChangedFiles(ctx, rev)
+}
+
+// created returns the untracked files that did not exist at baseline. Snapshots kept
+// outside the branch may hold files git does not track, which were there before the
+// mission and need no cleanup.
+func (g gitSnapshots) created(ctx context.Context, baseline string) ([]string, error) {
+	untracked, err := g.s.git.GetUntrackedFiles(ctx)
+	if err != nil || len(untracked) == 0 || g.branch {
+		return untracked, err
+	}
+
+	diffs, err := g.s.git.Diff(ctx, baseline, "", untracked)
+	if err != nil {
+		return nil, err
+	}
+	added := make(map[string]bool)
+	for _, d := range diffs {
+		if d.Change == git.ChangeAdded {
+			added[d.Path] = true
+		}
+	}
+	var created []string
+	for _, file := range untracked {
+		if added[file] {
+			created = append(created, file)
+		}
+	}
+	return created, nil
+}
+
+// tagStore commits the scope on the current branch and tags each checkpoint
+type tagStore struct {
+	gitSnapshots
+}
+
+func (t *tagStore) ref(name string) string {
+	return name
+}
+
+func (t *tagStore) names(ctx context.Context, missionID string) ([]string, error) {
+	return t.s.git.ListTags(ctx, missionID+"-")
+}
+
+func (t *tagStore) create(ctx context.Context, missionID string, num int) (string, error) {
+	stagableFiles, err := t.s.getStagableScope(ctx, "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s-%d", missionID, num)
+	if err := t.s.git.Add(ctx, stagableFiles); err != nil {
+		return "", fmt.Errorf("staging files: %w", err)
+	}
+
+	commitHash, err := t.s.git.CommitNoVerify(ctx, fmt.Sprintf("checkpoint: %s", name))
+	if err != nil {
+		if !errors.Is(err, git.ErrNoChanges) {
+			return "", fmt.Errorf("creating checkpoint commit: %w", err)
+		}
+		// No changes, tag current HEAD
+		if commitHash, err = t.s.git.GetTagCommit(ctx, "HEAD"); err != nil {
+			return "", fmt.Errorf("getting HEAD hash: %w", err)
+		}
+	}
+
+	if err := t.s.git.CreateTag(ctx, name, commitHash); err != nil {
+		return "", fmt.Errorf("creating checkpoint tag: %w", err)
+	}
+	return commitHash, nil
+}
+
+func (t *tagStore) mark(ctx context.Context, name, commitHash string) error {
+	return t.s.git.CreateTag(ctx, name, commitHash)
+}
+
+func (t *tagStore) remove(ctx context.Context, name string) error {
+	return t.s.git.DeleteTag(ctx, name)
+}
+
+// refStore snapshots the scope through a temporary index into refs/mission/<id>/<n>.
+// Each snapshot builds on the previous checkpoint, so the refs form a chain like the
+// tag backend's commits, but HEAD, the index and the tag namespace are never touched.
+type refStore struct {
+	gitSnapshots
+}
+
+func (r *refStore) ref(name string) string {
+	idx := strings.LastIndex(name, "-")
+	if idx <= 0 {
+		return missionRefPrefix + name
+	}
+	return missionRefPrefix + name[:idx] + "/" + name[idx+1:]
+}
+
+func (r *refStore) names(ctx context.Context, missionID string) ([]string, error) {
+	prefix := missionRefPrefix + missionID + "/"
+	refs, err := r.s.git.ListRefs(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if suffix := strings.TrimPrefix(ref, prefix); !strings.Contains(suffix, "/") {
+			names = append(names, missionID+"-"+suffix)
+		}
+	}
+	return names, nil
+}
+
+func (r *refStore) create(ctx context.Context, missionID string, num int) (string, error) {
+	parent := "HEAD"
+	if num > 1 {
+		previous := r.ref(fmt.Sprintf("%s-%d", missionID, num-1))
+		if _, err := r.s.git.GetTagCommit(ctx, previous); err == nil {
+			parent = previous
+		}
+	}
+
+	// Unlike staging, the snapshot needs every scope file, including ones that only
+	// the previous snapshot knew about and that have since been deleted
+	files, err := r.s.getScope(ctx, parent)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s-%d", missionID, num)
+	commitHash, err := r.s.git.SnapshotFiles(ctx, r.ref(name), parent, files, fmt.Sprintf("checkpoint: %s", name))
+	if err == nil {
+		return commitHash, nil
+	}
+	if !errors.Is(err, git.ErrNoChanges) {
+		return "", fmt.Errorf("creating checkpoint snapshot: %w", err)
+	}
+
+	// No changes, point at the previous checkpoint or HEAD
+	if commitHash, err = r.s.git.GetTagCommit(ctx, parent); err != nil {
+		return "", fmt.Errorf("resolving %s: %w", parent, err)
+	}
+	if err := r.mark(ctx, name, commitHash); err != nil {
+		return "", fmt.Errorf("creating checkpoint ref: %w", err)
+	}
+	return commitHash, nil
+}
+
+func (r *refStore) mark(ctx context.Context, name, commitHash string) error {
+	return r.s.git.UpdateRef(ctx, r.ref(name), commitHash)
+}
+
+func (r *refStore) remove(ctx context.Context, name string) error {
+	return r.s.git.DeleteRef(ctx, r.ref(name))
+}
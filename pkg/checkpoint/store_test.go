@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"testing"
+
+	internalgit "github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceWithBackend_Unknown(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	_, err := NewServiceWithBackend(fs, ".mission", internalgit.NewMemGitClient(repo, fs), "stash")
+	assert.ErrorContains(t, err, `unknown checkpoint backend "stash"`)
+}
+
+func TestRefStore_LeavesHeadAndTagsAlone(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	missionID := "test-refs"
+	createMissionFile(t, fs, missionID, []string{"a.txt", "b.txt"})
+	gitClient := internalgit.NewMemGitClient(repo, fs)
+	svc := newTestService(t, fs, gitClient, BackendRefs)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a1"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "b.txt", []byte("b1"), 0644))
+	_, err = svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+	require.NoError(t, fs.Remove("b.txt"))
+	name, err := svc.Create(t.Context(), missionID)
+	require.NoError(t, err)
+
+	refs, err := gitClient.ListRefs(t.Context(), "refs/mission/"+missionID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refs/mission/test-refs/1", "refs/mission/test-refs/2", "refs/mission/test-refs/baseline"}, refs)
+	tags, err := gitClient.ListTags(t.Context(), missionID)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+	after, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash(), after.Hash(), "HEAD never moves")
+
+	// The second snapshot builds on the first and records the deletion
+	commit := checkpointCommit(t, repo, svc, name)
+	assert.Equal(t, checkpointCommit(t, repo, svc, missionID+"-1").Hash, commit.ParentHashes[0])
+	_, err = commit.File("b.txt")
+	assert.Error(t, err)
+
+	require.NoError(t, svc.Restore(t.Context(), missionID+"-1"))
+	content, err := afero.ReadFile(fs, "b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "b1", string(content))
+
+	count, err := svc.Clear(t.Context(), missionID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	refs, err = gitClient.ListRefs(t.Context(), "refs/mission/"+missionID)
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+}
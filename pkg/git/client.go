@@ -1,27 +1,155 @@
 package git
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
 
 var ErrNoChanges = errors.New("no changes to commit")
 
-// GitClient defines the interface for git operations
+// ChangeType describes how a working tree file differs from a base commit
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeModified ChangeType = "modified"
+	ChangeDeleted  ChangeType = "deleted"
+)
+
+// FileChange is a working tree file that differs from a base commit
+type FileChange struct {
+	Path   string     `json:"path"`
+	Change ChangeType `json:"change"`
+}
+
+// FileStat is the line count change of a file between two commits
+type FileStat struct {
+	Path      string     `json:"path"`
+	Change    ChangeType `json:"change"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	Binary    bool       `json:"binary,omitempty"`
+}
+
+// FileDiff is the change of one file together with its unified patch
+type FileDiff struct {
+	FileStat
+	Patch string `json:"-"`
+}
+
+// CommitInfo is a commit together with the files it changed relative to its first parent
+type CommitInfo struct {
+	Hash    string   `json:"hash"`
+	Message string   `json:"message"`
+	Files   []string `json:"files"`
+}
+
+// Subject returns the first line of the commit message
+func (c CommitInfo) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return subject
+}
+
+// Worktree is a working tree of the repository as listed by git worktree list
+type Worktree struct {
+	Path string `json:"path"`
+	Head string `json:"head"`
+	// Branch is the short name of the checked out branch, empty when detached
+	Branch string `json:"branch,omitempty"`
+	// Prunable is set when the worktree directory no longer exists
+	Prunable bool `json:"prunable,omitempty"`
+}
+
+// GitClient defines the interface for git operations. Cancelling ctx stops the git
+// command a method is running; failures wrap the errors in errors.go where git reports
+// one of them.
 type GitClient interface {
-	Add(files []string) error
-	Commit(message string) (string, error)
+	Add(ctx context.Context, files []string) error
+	Commit(ctx context.Context, message string) (string, error)
 	// CommitNoVerify creates a commit without running git hooks (pre-commit, commit-msg, etc.).
 	// This is used for internal checkpoint commits to avoid hook interference.
-	CommitNoVerify(message string) (string, error)
-	CreateTag(name string, commitHash string) error
-	Restore(checkpointName string, files []string) error
-	ListTags(prefix string) ([]string, error)
-	DeleteTag(name string) error
-	GetTagCommit(tagName string) (string, error)
-	SoftReset(commitHash string) error
-	GetCommitMessage(commitHash string) (string, error)
-	IsTracked(path string) (bool, error)
-	GetCommitParent(commitHash string) (string, error)
-	GetUnstagedFiles() ([]string, error)
+	CommitNoVerify(ctx context.Context, message string) (string, error)
+	CreateTag(ctx context.Context, name string, commitHash string) error
+	// Restore writes the versions of files in the checkpoint to the working tree. Files
+	// the checkpoint does not have are left alone.
+	Restore(ctx context.Context, checkpointName string, files []string) error
+	ListTags(ctx context.Context, prefix string) ([]string, error)
+	DeleteTag(ctx context.Context, name string) error
+	// GetTagCommit resolves a tag, ref or revision to a commit hash. HEAD fails with
+	// ErrNoCommits before the first commit.
+	GetTagCommit(ctx context.Context, tagName string) (string, error)
+	SoftReset(ctx context.Context, commitHash string) error
+	GetCommitMessage(ctx context.Context, commitHash string) (string, error)
+	// GetCommitTime returns the committer time of a commit-ish
+	GetCommitTime(ctx context.Context, commitHash string) (time.Time, error)
+	IsTracked(ctx context.Context, path string) (bool, error)
+	GetCommitParent(ctx context.Context, commitHash string) (string, error)
+	GetUnstagedFiles(ctx context.Context) ([]string, error)
 	// GetUntrackedFiles returns a list of files that exist in the working directory
 	// but are not tracked by git (status "??"). These files need manual cleanup.
-	GetUntrackedFiles() ([]string, error)
+	GetUntrackedFiles(ctx context.Context) ([]string, error)
+	// GetChangedFiles returns working tree files that differ from the given base
+	// commit-ish (tag, hash or HEAD). Untracked files are reported as added.
+	GetChangedFiles(ctx context.Context, base string) ([]FileChange, error)
+	// StashChanges records the working tree state of files as a commit on top of HEAD,
+	// points ref at it and reverts those files to HEAD, like git stash push without
+	// touching the stash list. Returns ErrNoChanges if the files match HEAD.
+	StashChanges(ctx context.Context, ref string, files []string, message string) (string, error)
+	// ApplyStash reapplies the changes recorded by StashChanges to the working tree with
+	// a three-way merge against the commit they were based on. Files that could not be
+	// merged cleanly are left with conflict markers and returned. ref may also name any
+	// other commit, such as a checkpoint tag, whose changes should be replayed.
+	ApplyStash(ctx context.Context, ref string) ([]string, error)
+	// SnapshotFiles records the working tree state of files as a commit on top of the
+	// tree of parent and points ref at it, without touching HEAD, the index or the
+	// working tree. Files missing from the working tree are left out of the snapshot.
+	// Returns ErrNoChanges if the snapshot would match parent.
+	SnapshotFiles(ctx context.Context, ref, parent string, files []string, message string) (string, error)
+	// UpdateRef points ref at a commit, creating the ref if needed
+	UpdateRef(ctx context.Context, ref, commitHash string) error
+	// ListRefs returns the full names of the refs below prefix, such as refs/mission/<id>, sorted
+	ListRefs(ctx context.Context, prefix string) ([]string, error)
+	// DeleteRef removes a ref such as one created by StashChanges
+	DeleteRef(ctx context.Context, ref string) error
+	// CommitsBetween returns the commits reachable from to but not from from, newest
+	// first, like git log from..to, with the files each one changed.
+	CommitsBetween(ctx context.Context, from, to string) ([]CommitInfo, error)
+	// FindCommitsByTrailer returns the commits reachable from HEAD whose message has a
+	// trailer with the given key and value, newest first, with the files each one
+	// changed. Keys compare case-insensitively; an empty value matches any value.
+	FindCommitsByTrailer(ctx context.Context, key, value string) ([]CommitInfo, error)
+	// DiffStat returns per-file line counts of the changes between two commit-ishes,
+	// sorted by path
+	DiffStat(ctx context.Context, from, to string) ([]FileStat, error)
+	// Diff returns the per-file changes and unified patches between from and to, sorted
+	// by path. An empty to compares from against the working tree, reporting untracked
+	// files as added. paths limits the diff to those files or directories when given.
+	Diff(ctx context.Context, from, to string, paths []string) ([]FileDiff, error)
+	// CurrentBranch returns the short name of the checked out branch, or "" when HEAD is detached
+	CurrentBranch(ctx context.Context) (string, error)
+	// CreateBranch creates a branch pointing at startPoint without checking it out
+	CreateBranch(ctx context.Context, name, startPoint string) error
+	// SwitchBranch checks out a branch, carrying local changes over like git switch. It
+	// fails without changing anything when local changes would be overwritten.
+	SwitchBranch(ctx context.Context, name string) error
+	// DeleteBranch removes a branch that is fully merged into HEAD
+	DeleteBranch(ctx context.Context, name string) error
+	// MergeFastForward advances the current branch to branch, failing when that is not a
+	// fast-forward. Returns the new HEAD commit hash.
+	MergeFastForward(ctx context.Context, branch string) (string, error)
+	// MergeSquash applies the changes of branch since it forked from HEAD as a single new
+	// commit on the current branch, like git merge --squash followed by git commit.
+	// Returns ErrNoChanges if branch has nothing to merge.
+	MergeSquash(ctx context.Context, branch, message string) (string, error)
+	// AddWorktree creates branch at startPoint and checks it out in a new worktree at path
+	AddWorktree(ctx context.Context, path, branch, startPoint string) error
+	// RemoveWorktree deletes a linked worktree and its directory. Without force a
+	// worktree with modified or untracked files is refused.
+	RemoveWorktree(ctx context.Context, path string, force bool) error
+	// ListWorktrees returns the main worktree followed by the linked ones
+	ListWorktrees(ctx context.Context) ([]Worktree, error)
+	// PruneWorktrees forgets worktrees whose directories were deleted
+	PruneWorktrees(ctx context.Context) error
 }
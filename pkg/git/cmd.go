@@ -1,99 +1,174 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// DefaultTimeout bounds a single git command unless a client is given another timeout
+const DefaultTimeout = 5 * time.Minute
+
+// waitDelay is how long a killed git command or hook may keep its output open, as
+// processes it started can, before it is abandoned
+const waitDelay = time.Second
+
 // CmdGitClient implements GitClient using the git CLI
 type CmdGitClient struct {
 	workDir string
+	// timeout bounds each git command; zero or less means no limit
+	timeout time.Duration
 }
 
-// NewCmdGitClient creates a new CmdGitClient
+// NewCmdGitClient creates a new CmdGitClient whose commands time out after DefaultTimeout
 func NewCmdGitClient(workDir string) *CmdGitClient {
-	return &CmdGitClient{workDir: workDir}
+	return &CmdGitClient{workDir: workDir, timeout: DefaultTimeout}
 }
 
-func (c *CmdGitClient) run(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
+// checkRepository fails with ErrNotRepository unless the working directory is inside
+// a git work tree, which includes when git is not installed
+func (c *CmdGitClient) checkRepository(ctx context.Context) error {
+	out, err := c.run(ctx, "rev-parse", "--is-inside-work-tree")
+	if errors.Is(err, ErrTimeout) || ctx.Err() != nil {
+		return err
+	}
+	if err != nil || strings.TrimSpace(out) != "true" {
+		return ErrNotRepository
+	}
+	return nil
+}
+
+func (c *CmdGitClient) run(ctx context.Context, args ...string) (string, error) {
+	return c.runEnv(ctx, nil, args...)
+}
+
+// runEnv runs git with additional environment variables such as GIT_INDEX_FILE. A
+// failure is returned as a *CommandError together with the output.
+func (c *CmdGitClient) runEnv(ctx context.Context, env []string, args ...string) (string, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = c.workDir
+	// Untranslated messages can be classified into errors
+	cmd.Env = append(append(os.Environ(), "LANGUAGE=C"), env...)
+	cmd.WaitDelay = waitDelay
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return string(output), err
+		return string(output), newCommandError(ctx, args, string(output), err)
 	}
 	return string(output), nil
 }
 
-func (c *CmdGitClient) Add(files []string) error {
+// withTimeout bounds ctx by the timeout of the client
+func (c *CmdGitClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (c *CmdGitClient) Add(ctx context.Context, files []string) error {
 	if len(files) == 0 {
 		return nil
 	}
 	args := append([]string{"add"}, files...)
-	_, err := c.run(args...)
+	_, err := c.run(ctx, args...)
 	return err
 }
 
-func (c *CmdGitClient) Commit(message string) (string, error) {
-	output, err := c.run("commit", "-m", message)
+func (c *CmdGitClient) Commit(ctx context.Context, message string) (string, error) {
+	output, err := c.run(ctx, "commit", "-m", message)
 	if err != nil {
-		if strings.Contains(output, "nothing to commit") {
+		if nothingToCommit(output) {
 			return "", ErrNoChanges
 		}
-		return "", fmt.Errorf("git commit failed: %s", output)
+		return "", err
 	}
 
 	// Get the commit hash
-	out, err := c.run("rev-parse", "HEAD")
+	out, err := c.run(ctx, "rev-parse", "HEAD")
 	return strings.TrimSpace(out), err
 }
 
 // CommitNoVerify creates a commit without running git hooks.
 // This is used for internal checkpoint commits to prevent pre-commit hooks
 // from modifying files and causing checkpoint creation failures.
-func (c *CmdGitClient) CommitNoVerify(message string) (string, error) {
-	output, err := c.run("commit", "--no-verify", "-m", message)
+func (c *CmdGitClient) CommitNoVerify(ctx context.Context, message string) (string, error) {
+	output, err := c.run(ctx, "commit", "--no-verify", "-m", message)
 	if err != nil {
-		if strings.Contains(output, "nothing to commit") {
+		if nothingToCommit(output) {
 			return "", ErrNoChanges
 		}
-		return "", fmt.Errorf("git commit failed: %s", output)
+		return "", err
 	}
 
 	// Get the commit hash
-	out, err := c.run("rev-parse", "HEAD")
+	out, err := c.run(ctx, "rev-parse", "HEAD")
 	return strings.TrimSpace(out), err
 }
 
-func (c *CmdGitClient) CreateTag(name string, commitHash string) error {
+// nothingToCommit reports whether git commit failed because nothing was staged. Git
+// words this differently when the working tree has unstaged or untracked changes.
+func nothingToCommit(output string) bool {
+	for _, reason := range []string{"nothing to commit", "nothing added to commit", "no changes added to commit"} {
+		if strings.Contains(output, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CmdGitClient) CreateTag(ctx context.Context, name string, commitHash string) error {
 	args := []string{"tag", name}
 	if commitHash != "" {
 		args = append(args, strings.TrimSpace(commitHash))
 	}
-	_, err := c.run(args...)
+	_, err := c.run(ctx, args...)
 	return err
 }
 
-func (c *CmdGitClient) Restore(checkpointName string, files []string) error {
+func (c *CmdGitClient) Restore(ctx context.Context, checkpointName string, files []string) error {
 	if len(files) == 0 {
 		return nil
 	}
 
 	// Check if tag exists
-	if _, err := c.run("rev-parse", checkpointName); err != nil {
+	if _, err := c.run(ctx, "rev-parse", checkpointName); err != nil {
+		if errors.Is(err, ErrTimeout) || ctx.Err() != nil {
+			return err
+		}
 		return fmt.Errorf("checkpoint not found: %s", checkpointName)
 	}
 
+	// Only files known to the checkpoint can be checked out; files created after
+	// it would make git fail with "pathspec did not match".
+	listArgs := append([]string{"ls-tree", "-r", "-z", "--name-only", checkpointName, "--"}, files...)
+	out, err := c.run(ctx, listArgs...)
+	if err != nil {
+		return fmt.Errorf("listing checkpoint files: %w", err)
+	}
+	known := splitNUL(out)
+	if len(known) == 0 {
+		return nil
+	}
+
 	// Checkout files from the checkpoint
 	args := []string{"checkout", checkpointName, "--"}
-	args = append(args, files...)
-	_, err := c.run(args...)
+	args = append(args, known...)
+	_, err = c.run(ctx, args...)
 	return err
 }
 
-func (c *CmdGitClient) ListTags(prefix string) ([]string, error) {
-	out, err := c.run("tag", "-l", prefix+"*")
+func (c *CmdGitClient) ListTags(ctx context.Context, prefix string) ([]string, error) {
+	out, err := c.run(ctx, "tag", "-l", prefix+"*")
 	if err != nil {
 		return nil, err
 	}
@@ -106,38 +181,46 @@ func (c *CmdGitClient) ListTags(prefix string) ([]string, error) {
 	return tags, nil
 }
 
-func (c *CmdGitClient) DeleteTag(name string) error {
-	_, err := c.run("tag", "-d", name)
+func (c *CmdGitClient) DeleteTag(ctx context.Context, name string) error {
+	_, err := c.run(ctx, "tag", "-d", name)
 	return err
 }
 
-func (c *CmdGitClient) GetTagCommit(tagName string) (string, error) {
+func (c *CmdGitClient) GetTagCommit(ctx context.Context, tagName string) (string, error) {
 	ref := tagName
 	if !strings.Contains(tagName, "^{") {
 		ref = tagName + "^{commit}"
 	}
 
-	out, err := c.run("rev-parse", ref)
+	out, err := c.run(ctx, "rev-parse", ref)
 	return strings.TrimSpace(out), err
 }
 
-func (c *CmdGitClient) SoftReset(commitHash string) error {
-	_, err := c.run("reset", "--soft", commitHash)
+func (c *CmdGitClient) SoftReset(ctx context.Context, commitHash string) error {
+	_, err := c.run(ctx, "reset", "--soft", commitHash)
 	return err
 }
 
-func (c *CmdGitClient) GetCommitMessage(commitHash string) (string, error) {
-	out, err := c.run("log", "-1", "--pretty=%B", commitHash)
+func (c *CmdGitClient) GetCommitMessage(ctx context.Context, commitHash string) (string, error) {
+	out, err := c.run(ctx, "log", "-1", "--pretty=%B", commitHash)
 	return strings.TrimSpace(out), err
 }
 
-func (c *CmdGitClient) IsTracked(path string) (bool, error) {
+func (c *CmdGitClient) GetCommitTime(ctx context.Context, commitHash string) (time.Time, error) {
+	out, err := c.run(ctx, "show", "-s", "--format=%cI", commitHash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(out))
+}
+
+func (c *CmdGitClient) IsTracked(ctx context.Context, path string) (bool, error) {
 	// git ls-files --error-unmatch <file> returns 0 if tracked, 1 if not
-	_, err := c.run("ls-files", "--error-unmatch", path)
+	_, err := c.run(ctx, "ls-files", "--error-unmatch", path)
 	if err != nil {
 		// If exit code is 1, it's not tracked.
 		// We treat this as false, not an error.
-		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
+		if exitCode(err) == 1 {
 			return false, nil
 		}
 		return false, err
@@ -145,26 +228,35 @@ func (c *CmdGitClient) IsTracked(path string) (bool, error) {
 	return true, nil
 }
 
-func (c *CmdGitClient) GetCommitParent(commitHash string) (string, error) {
-	out, err := c.run("rev-parse", commitHash+"^")
+func (c *CmdGitClient) CurrentBranch(ctx context.Context) (string, error) {
+	// git symbolic-ref --quiet exits with 1 and no output when HEAD is detached
+	out, err := c.run(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		if exitCode(err) == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading current branch: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (c *CmdGitClient) GetCommitParent(ctx context.Context, commitHash string) (string, error) {
+	out, err := c.run(ctx, "rev-parse", commitHash+"^")
 	return strings.TrimSpace(out), err
 }
 
-func (c *CmdGitClient) GetUnstagedFiles() ([]string, error) {
-	out, err := c.run("status", "--porcelain")
+func (c *CmdGitClient) GetUnstagedFiles(ctx context.Context) ([]string, error) {
+	entries, err := c.status(ctx)
 	if err != nil {
 		return nil, err
 	}
 	var files []string
-	for _, line := range strings.Split(out, "\n") {
-		if len(line) < 3 {
-			continue
-		}
+	for _, entry := range entries {
 		// Porcelain format: XY filename (X=index, Y=worktree)
 		// Unstaged: Y is not space and X is space or ?
-		x, y := line[0], line[1]
+		x, y := entry[0], entry[1]
 		if y != ' ' && (x == ' ' || x == '?') {
-			files = append(files, strings.TrimSpace(line[3:]))
+			files = append(files, entry[3:])
 		}
 	}
 	return files, nil
@@ -172,20 +264,614 @@ func (c *CmdGitClient) GetUnstagedFiles() ([]string, error) {
 
 // GetUntrackedFiles returns files that exist in the working directory but are not tracked by git.
 // These files have status "??" in git status --porcelain output.
-func (c *CmdGitClient) GetUntrackedFiles() ([]string, error) {
-	out, err := c.run("status", "--porcelain")
+func (c *CmdGitClient) GetUntrackedFiles(ctx context.Context) ([]string, error) {
+	entries, err := c.status(ctx)
 	if err != nil {
 		return nil, err
 	}
 	var files []string
-	for _, line := range strings.Split(out, "\n") {
-		if len(line) < 3 {
-			continue
-		}
+	for _, entry := range entries {
 		// Porcelain format: ?? filename means untracked
-		if line[0] == '?' && line[1] == '?' {
-			files = append(files, strings.TrimSpace(line[3:]))
+		if entry[0] == '?' && entry[1] == '?' {
+			files = append(files, entry[3:])
 		}
 	}
 	return files, nil
 }
+
+// status returns the "XY path" entries of git status --porcelain -z. With -z paths are
+// not quoted, and the original path of a rename or copy follows as its own field.
+func (c *CmdGitClient) status(ctx context.Context) ([]string, error) {
+	out, err := c.run(ctx, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var entries []string
+	fields := splitNUL(out)
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// GetChangedFiles returns files in the working tree (staged or not) that differ from base,
+// plus untracked files that are not ignored.
+func (c *CmdGitClient) GetChangedFiles(ctx context.Context, base string) ([]FileChange, error) {
+	out, err := c.run(ctx, "diff", "--name-status", "--no-renames", "-z", base)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := parseNameStatus(out)
+
+	out, err = c.run(ctx, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range splitNUL(out) {
+		changes = append(changes, FileChange{Path: path, Change: ChangeAdded})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// parseNameStatus parses git diff --name-status --no-renames -z output, where the
+// status and the path of each file are separate fields
+func parseNameStatus(out string) []FileChange {
+	var changes []FileChange
+	fields := splitNUL(out)
+	for i := 0; i+1 < len(fields); i += 2 {
+		change := ChangeModified
+		switch fields[i][0] {
+		case 'A':
+			change = ChangeAdded
+		case 'D':
+			change = ChangeDeleted
+		}
+		changes = append(changes, FileChange{Path: fields[i+1], Change: change})
+	}
+	return changes
+}
+
+// splitNUL splits the NUL-terminated fields of git -z output. Paths in them are
+// verbatim, unlike the C-quoted paths of line-based output.
+func splitNUL(out string) []string {
+	var fields []string
+	for _, field := range strings.Split(out, "\x00") {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// StashChanges snapshots the files on top of HEAD, then reverts them in the working tree
+func (c *CmdGitClient) StashChanges(ctx context.Context, ref string, files []string, message string) (string, error) {
+	if len(files) == 0 {
+		return "", ErrNoChanges
+	}
+
+	head, err := c.GetTagCommit(ctx, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("resolving HEAD: %w", err)
+	}
+	commit, err := c.SnapshotFiles(ctx, ref, head, files, message)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.resetFiles(ctx, head, files); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// SnapshotFiles builds the snapshot in a temporary index so the real index, HEAD and
+// the stash list are untouched
+func (c *CmdGitClient) SnapshotFiles(ctx context.Context, ref, parent string, files []string, message string) (string, error) {
+	if len(files) == 0 {
+		return "", ErrNoChanges
+	}
+
+	parentCommit, err := c.GetTagCommit(ctx, parent)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", parent, err)
+	}
+
+	indexFile, err := os.CreateTemp("", "mission-index-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary index: %w", err)
+	}
+	indexFile.Close()
+	os.Remove(indexFile.Name())
+	defer os.Remove(indexFile.Name())
+	env := []string{"GIT_INDEX_FILE=" + indexFile.Name()}
+
+	if _, err := c.runEnv(ctx, env, "read-tree", parentCommit); err != nil {
+		return "", err
+	}
+
+	// Missing files are removed explicitly: git add fails on paths that are neither
+	// in the working tree nor in the index
+	var present, missing []string
+	for _, file := range files {
+		if _, err := os.Lstat(filepath.Join(c.workDir, file)); err == nil {
+			present = append(present, file)
+		} else {
+			missing = append(missing, file)
+		}
+	}
+	if len(present) > 0 {
+		addArgs := append([]string{"add", "-A", "--"}, present...)
+		if _, err := c.runEnv(ctx, env, addArgs...); err != nil {
+			return "", err
+		}
+	}
+	if len(missing) > 0 {
+		rmArgs := append([]string{"rm", "--cached", "-q", "--ignore-unmatch", "--"}, missing...)
+		if _, err := c.runEnv(ctx, env, rmArgs...); err != nil {
+			return "", err
+		}
+	}
+
+	tree, err := c.runEnv(ctx, env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("git write-tree failed: %w", err)
+	}
+	parentTree, err := c.GetTagCommit(ctx, parentCommit+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("resolving %s tree: %w", parent, err)
+	}
+	if strings.TrimSpace(tree) == parentTree {
+		return "", ErrNoChanges
+	}
+
+	commit, err := c.run(ctx, "commit-tree", strings.TrimSpace(tree), "-p", parentCommit, "-m", message)
+	if err != nil {
+		return "", fmt.Errorf("git commit-tree failed: %w", err)
+	}
+	commit = strings.TrimSpace(commit)
+	if err := c.UpdateRef(ctx, ref, commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+func (c *CmdGitClient) UpdateRef(ctx context.Context, ref, commitHash string) error {
+	if _, err := c.run(ctx, "update-ref", ref, commitHash); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CmdGitClient) ListRefs(ctx context.Context, prefix string) ([]string, error) {
+	out, err := c.run(ctx, "for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			refs = append(refs, line)
+		}
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+// resetFiles reverts files in the index and working tree to commit, deleting files
+// that do not exist in it.
+func (c *CmdGitClient) resetFiles(ctx context.Context, commit string, files []string) error {
+	listArgs := append([]string{"ls-tree", "-r", "-z", "--name-only", commit, "--"}, files...)
+	out, err := c.run(ctx, listArgs...)
+	if err != nil {
+		return fmt.Errorf("listing commit files: %w", err)
+	}
+	known := make(map[string]bool)
+	for _, path := range splitNUL(out) {
+		known[path] = true
+	}
+
+	var tracked, added []string
+	for _, file := range files {
+		if known[file] {
+			tracked = append(tracked, file)
+		} else {
+			added = append(added, file)
+		}
+	}
+
+	if len(tracked) > 0 {
+		args := append([]string{"checkout", commit, "--"}, tracked...)
+		if _, err := c.run(ctx, args...); err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		args := append([]string{"rm", "--cached", "-q", "--ignore-unmatch", "--"}, added...)
+		if _, err := c.run(ctx, args...); err != nil {
+			return err
+		}
+		for _, file := range added {
+			if err := os.Remove(filepath.Join(c.workDir, file)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing %s: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyStash merges each stashed file with git merge-file so the index is left alone
+func (c *CmdGitClient) ApplyStash(ctx context.Context, ref string) ([]string, error) {
+	commit, err := c.GetTagCommit(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("stash %s not found", ref)
+	}
+	parent, err := c.GetCommitParent(ctx, commit)
+	if err != nil {
+		return nil, fmt.Errorf("resolving stash parent: %w", err)
+	}
+
+	out, err := c.run(ctx, "diff", "--name-status", "--no-renames", "-z", parent, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for _, change := range parseNameStatus(out) {
+		path := filepath.Join(c.workDir, change.Path)
+		ours := fileVersion{}
+		if content, err := os.ReadFile(path); err == nil {
+			ours = fileVersion{content: content, exists: true}
+		}
+		base := c.blob(ctx, parent, change.Path)
+		theirs := c.blob(ctx, commit, change.Path)
+
+		result, conflict, err := mergeStashedFile(ours, base, theirs, func(ours, base, theirs []byte) ([]byte, bool, error) {
+			return c.mergeFile(ctx, ours, base, theirs)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("merging %s: %w", change.Path, err)
+		}
+		if conflict {
+			conflicts = append(conflicts, change.Path)
+		}
+		if result.equal(ours) {
+			continue
+		}
+		if !result.exists {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("removing %s: %w", change.Path, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("creating directory for %s: %w", change.Path, err)
+		}
+		if err := os.WriteFile(path, result.content, 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", change.Path, err)
+		}
+	}
+	return conflicts, nil
+}
+
+// blob returns the content of path at commit
+func (c *CmdGitClient) blob(ctx context.Context, commit, path string) fileVersion {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "blob", commit+":"+path)
+	cmd.Dir = c.workDir
+	content, err := cmd.Output()
+	if err != nil {
+		return fileVersion{}
+	}
+	return fileVersion{content: content, exists: true}
+}
+
+// mergeFile runs git merge-file on temporary copies of the three versions
+func (c *CmdGitClient) mergeFile(ctx context.Context, ours, base, theirs []byte) ([]byte, bool, error) {
+	dir, err := os.MkdirTemp("", "mission-merge-*")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(dir)
+
+	paths := make([]string, 3)
+	for i, content := range [][]byte{ours, base, theirs} {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%d", i))
+		if err := os.WriteFile(paths[i], content, 0644); err != nil {
+			return nil, false, err
+		}
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "merge-file", "-p", "-L", "working tree", "-L", "base", "-L", "stashed", paths[0], paths[1], paths[2])
+	merged, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && ctx.Err() == nil && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return merged, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("git merge-file failed: %w", newCommandError(ctx, cmd.Args[1:], "", err))
+	}
+	return merged, false, nil
+}
+
+// CommitsBetween runs git log from..to, separating records and messages with control
+// characters so multi-line messages parse unambiguously
+func (c *CmdGitClient) CommitsBetween(ctx context.Context, from, to string) ([]CommitInfo, error) {
+	out, err := c.run(ctx, "log", "--no-renames", "--name-only", "-z", "--format=%x1e%H%x1f%B%x1f", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(out), nil
+}
+
+// FindCommitsByTrailer lets git log --grep preselect candidates and keeps those that
+// have the trailer in their trailer block
+func (c *CmdGitClient) FindCommitsByTrailer(ctx context.Context, key, value string) ([]CommitInfo, error) {
+	pattern := "^" + regexp.QuoteMeta(key) + ":"
+	if value != "" {
+		pattern += "[[:space:]]*" + regexp.QuoteMeta(value) + "[[:space:]]*$"
+	}
+	out, err := c.run(ctx, "log", "--no-renames", "--name-only", "-z", "--format=%x1e%H%x1f%B%x1f",
+		"--regexp-ignore-case", "--extended-regexp", "--grep", pattern, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitInfo
+	for _, commit := range parseLog(out) {
+		if hasTrailer(commit.Message, key, value) {
+			commits = append(commits, commit)
+		}
+	}
+	return commits, nil
+}
+
+// parseLog parses git log -z output in the format used by CommitsBetween. The file
+// list follows the format after a NUL and a newline, one NUL-terminated path per file.
+func parseLog(out string) []CommitInfo {
+	var commits []CommitInfo
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(record, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		commit := CommitInfo{Hash: strings.TrimSpace(fields[0]), Message: strings.TrimSpace(fields[1])}
+		files := strings.TrimPrefix(strings.TrimPrefix(fields[2], "\x00"), "\n")
+		commit.Files = splitNUL(files)
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// DiffStat combines git diff --numstat line counts with --name-status change types
+func (c *CmdGitClient) DiffStat(ctx context.Context, from, to string) ([]FileStat, error) {
+	stats, err := c.diffStat(ctx, nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Path < stats[j].Path })
+	return stats, nil
+}
+
+// diffStat returns the file stats of git diff args in git's output order
+func (c *CmdGitClient) diffStat(ctx context.Context, env []string, args ...string) ([]FileStat, error) {
+	out, err := c.runEnv(ctx, env, append([]string{"diff", "--name-status", "--no-renames", "-z"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	changes := parseNameStatus(out)
+
+	out, err = c.runEnv(ctx, env, append([]string{"diff", "--numstat", "--no-renames", "-z"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]FileStat)
+	for _, field := range splitNUL(out) {
+		parts := strings.SplitN(field, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		stat := FileStat{Binary: parts[0] == "-"}
+		stat.Additions, _ = strconv.Atoi(parts[0])
+		stat.Deletions, _ = strconv.Atoi(parts[1])
+		counts[parts[2]] = stat
+	}
+
+	stats := make([]FileStat, 0, len(changes))
+	for _, change := range changes {
+		stat := counts[change.Path]
+		stat.Path, stat.Change = change.Path, change.Change
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// Diff compares two commits directly. The working tree is compared through a temporary
+// index holding all of it, so untracked files are included and the real index is untouched.
+func (c *CmdGitClient) Diff(ctx context.Context, from, to string, paths []string) ([]FileDiff, error) {
+	args := []string{from, to}
+	var env []string
+	if to == "" {
+		head, err := c.GetTagCommit(ctx, "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("resolving HEAD: %w", err)
+		}
+		indexFile, err := os.CreateTemp("", "mission-index-*")
+		if err != nil {
+			return nil, fmt.Errorf("creating temporary index: %w", err)
+		}
+		indexFile.Close()
+		os.Remove(indexFile.Name())
+		defer os.Remove(indexFile.Name())
+		env = []string{"GIT_INDEX_FILE=" + indexFile.Name()}
+
+		if _, err := c.runEnv(ctx, env, "read-tree", head); err != nil {
+			return nil, err
+		}
+		if _, err := c.runEnv(ctx, env, "add", "-A"); err != nil {
+			return nil, err
+		}
+		args = []string{"--cached", from}
+	}
+	args = append(append(args, "--"), paths...)
+
+	stats, err := c.diffStat(ctx, env, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.runEnv(ctx, env, append([]string{"diff", "--no-renames", "--no-color", "--no-ext-diff"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every output format lists files in the same order, one patch section per file
+	patches := splitPatch(out)
+	diffs := make([]FileDiff, len(stats))
+	for i, stat := range stats {
+		diffs[i].FileStat = stat
+		if len(patches) == len(stats) {
+			diffs[i].Patch = patches[i]
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+// splitPatch splits git diff output into one section per file
+func splitPatch(out string) []string {
+	var sections []string
+	start := -1
+	for offset := 0; offset < len(out); {
+		end := strings.IndexByte(out[offset:], '\n')
+		if end < 0 {
+			end = len(out)
+		} else {
+			end += offset + 1
+		}
+		if strings.HasPrefix(out[offset:], "diff --git ") {
+			if start >= 0 {
+				sections = append(sections, out[start:offset])
+			}
+			start = offset
+		}
+		offset = end
+	}
+	if start >= 0 {
+		sections = append(sections, out[start:])
+	}
+	return sections
+}
+
+func (c *CmdGitClient) DeleteRef(ctx context.Context, ref string) error {
+	if _, err := c.run(ctx, "update-ref", "-d", ref); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CmdGitClient) CreateBranch(ctx context.Context, name, startPoint string) error {
+	if _, err := c.run(ctx, "branch", name, startPoint); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CmdGitClient) SwitchBranch(ctx context.Context, name string) error {
+	if _, err := c.run(ctx, "switch", name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CmdGitClient) DeleteBranch(ctx context.Context, name string) error {
+	// -d refuses to delete a branch with commits that are not merged into HEAD
+	if _, err := c.run(ctx, "branch", "-d", name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CmdGitClient) MergeFastForward(ctx context.Context, branch string) (string, error) {
+	if _, err := c.run(ctx, "merge", "--ff-only", branch); err != nil {
+		return "", err
+	}
+	out, err := c.run(ctx, "rev-parse", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+func (c *CmdGitClient) MergeSquash(ctx context.Context, branch, message string) (string, error) {
+	if _, err := c.run(ctx, "merge", "--squash", branch); err != nil {
+		return "", err
+	}
+	return c.Commit(ctx, message)
+}
+
+func (c *CmdGitClient) AddWorktree(ctx context.Context, path, branch, startPoint string) error {
+	if _, err := c.run(ctx, "worktree", "add", "-b", branch, path, startPoint); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CmdGitClient) RemoveWorktree(ctx context.Context, path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	if _, err := c.run(ctx, append(args, path)...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CmdGitClient) ListWorktrees(ctx context.Context) ([]Worktree, error) {
+	out, err := c.run(ctx, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktrees(out), nil
+}
+
+// parseWorktrees parses git worktree list --porcelain: one attribute per line,
+// worktrees separated by blank lines
+func parseWorktrees(out string) []Worktree {
+	var worktrees []Worktree
+	for _, block := range strings.Split(strings.TrimSpace(out), "\n\n") {
+		var wt Worktree
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				wt.Path = value
+			case "HEAD":
+				wt.Head = value
+			case "branch":
+				wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "prunable":
+				wt.Prunable = true
+			}
+		}
+		if wt.Path != "" {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees
+}
+
+func (c *CmdGitClient) PruneWorktrees(ctx context.Context) error {
+	if _, err := c.run(ctx, "worktree", "prune"); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,695 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onDiskClients are the GitClient implementations that work against a repository on
+// disk. Every conformance test runs against each of them.
+var onDiskClients = map[string]func(t *testing.T, dir string) GitClient{
+	ClientCmd: func(t *testing.T, dir string) GitClient {
+		return NewCmdGitClient(dir)
+	},
+	ClientGoGit: func(t *testing.T, dir string) GitClient {
+		client, err := NewGoGitClient(dir)
+		require.NoError(t, err)
+		return client
+	},
+}
+
+// conformanceRepo is a repository on disk with one commit of README.md and a
+// .gitignore that ignores *.log
+type conformanceRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newConformanceRepo(t *testing.T) *conformanceRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	r := &conformanceRepo{t: t, dir: t.TempDir()}
+	r.git("init", "--quiet", "--initial-branch=main")
+	r.git("config", "user.name", "Test")
+	r.git("config", "user.email", "test@example.com")
+	r.write("README.md", "# Test\n")
+	r.write(".gitignore", "*.log\n")
+	r.git("add", ".")
+	r.git("commit", "--quiet", "-m", "Initial commit")
+	return r
+}
+
+// git runs the git CLI in the repository and returns its output
+func (r *conformanceRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, "git %v: %s", args, out)
+	return string(out)
+}
+
+func (r *conformanceRepo) write(path, content string) {
+	r.t.Helper()
+	path = filepath.Join(r.dir, path)
+	require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(r.t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func (r *conformanceRepo) read(path string) string {
+	r.t.Helper()
+	content, err := os.ReadFile(filepath.Join(r.dir, path))
+	require.NoError(r.t, err)
+	return string(content)
+}
+
+// hook installs an executable hook running script
+func (r *conformanceRepo) hook(name, script string) {
+	r.t.Helper()
+	require.NoError(r.t, os.WriteFile(filepath.Join(r.dir, ".git", "hooks", name), []byte("#!/bin/sh\n"+script+"\n"), 0755))
+}
+
+// runConformance runs test against a fresh repository for every on-disk client
+func runConformance(t *testing.T, test func(t *testing.T, r *conformanceRepo, client GitClient)) {
+	for name, newClient := range onDiskClients {
+		t.Run(name, func(t *testing.T) {
+			r := newConformanceRepo(t)
+			test(t, r, newClient(t, r.dir))
+		})
+	}
+}
+
+func TestConformance_CommitNoChanges(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		_, err := client.Commit(t.Context(), "empty")
+		assert.ErrorIs(t, err, ErrNoChanges)
+
+		// Untracked and unstaged changes are not committed
+		r.write("new.txt", "new\n")
+		_, err = client.Commit(t.Context(), "untracked only")
+		assert.ErrorIs(t, err, ErrNoChanges)
+		r.write("README.md", "# Changed\n")
+		_, err = client.CommitNoVerify(t.Context(), "unstaged only")
+		assert.ErrorIs(t, err, ErrNoChanges)
+
+		require.NoError(t, client.Add(t.Context(), []string{"README.md"}))
+		hash, err := client.Commit(t.Context(), "Update readme")
+		require.NoError(t, err)
+		head, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, head, hash)
+		message, err := client.GetCommitMessage(t.Context(), hash)
+		require.NoError(t, err)
+		assert.Equal(t, "Update readme", message)
+		assert.Contains(t, r.git("log", "-1", "--format=%an <%ae>"), "Test <test@example.com>")
+
+		untracked, err := client.GetUntrackedFiles(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"new.txt"}, untracked)
+	})
+}
+
+func TestConformance_CommitHooks(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		r.hook("pre-commit", "echo rejected >&2; exit 1")
+		r.write("a.txt", "a\n")
+		require.NoError(t, client.Add(t.Context(), []string{"a.txt"}))
+
+		_, err := client.Commit(t.Context(), "blocked")
+		assert.ErrorContains(t, err, "rejected")
+
+		hash, err := client.CommitNoVerify(t.Context(), "not blocked")
+		require.NoError(t, err)
+		message, err := client.GetCommitMessage(t.Context(), hash)
+		require.NoError(t, err)
+		assert.Equal(t, "not blocked", message)
+
+		// commit-msg may rewrite the message
+		r.hook("pre-commit", "exit 0")
+		r.hook("commit-msg", `echo "Signed-off-by: Hook" >> "$1"`)
+		r.write("b.txt", "b\n")
+		require.NoError(t, client.Add(t.Context(), []string{"b.txt"}))
+		hash, err = client.Commit(t.Context(), "with hook")
+		require.NoError(t, err)
+		message, err = client.GetCommitMessage(t.Context(), hash)
+		require.NoError(t, err)
+		assert.Equal(t, "with hook\nSigned-off-by: Hook", message)
+	})
+}
+
+func TestConformance_Status(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		r.write("README.md", "# Changed\n")
+		r.write("docs/guide/intro.md", "intro\n")
+		r.write("docs/notes.txt", "notes\n")
+		r.write("debug.log", "ignored\n")
+		r.write("docs/trace.log", "ignored\n")
+		r.write("staged.txt", "staged\n")
+		require.NoError(t, client.Add(t.Context(), []string{"staged.txt"}))
+
+		untracked, err := client.GetUntrackedFiles(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs/guide/intro.md", "docs/notes.txt"}, untracked, "files in untracked directories are listed one by one")
+
+		unstaged, err := client.GetUnstagedFiles(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md", "docs/guide/intro.md", "docs/notes.txt"}, unstaged)
+
+		changes, err := client.GetChangedFiles(t.Context(), "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{
+			{Path: "README.md", Change: ChangeModified},
+			{Path: "docs/guide/intro.md", Change: ChangeAdded},
+			{Path: "docs/notes.txt", Change: ChangeAdded},
+			{Path: "staged.txt", Change: ChangeAdded},
+		}, changes)
+
+		for path, want := range map[string]bool{"README.md": true, "staged.txt": true, "docs/notes.txt": false} {
+			tracked, err := client.IsTracked(t.Context(), path)
+			require.NoError(t, err)
+			assert.Equal(t, want, tracked, path)
+		}
+	})
+}
+
+func TestConformance_NonASCIIPaths(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		base, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		r.write("docs/naïve notes.md", "notes\n")
+		require.NoError(t, client.Add(t.Context(), []string{"docs/naïve notes.md"}))
+		commit, err := client.Commit(t.Context(), "Add notes")
+		require.NoError(t, err)
+
+		r.write("café.txt", "café\n")
+		r.write("docs/naïve notes.md", "more notes\n")
+
+		untracked, err := client.GetUntrackedFiles(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"café.txt"}, untracked)
+		unstaged, err := client.GetUnstagedFiles(t.Context())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"café.txt", "docs/naïve notes.md"}, unstaged)
+		changes, err := client.GetChangedFiles(t.Context(), base)
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{
+			{Path: "café.txt", Change: ChangeAdded},
+			{Path: "docs/naïve notes.md", Change: ChangeAdded},
+		}, changes)
+
+		stats, err := client.DiffStat(t.Context(), base, commit)
+		require.NoError(t, err)
+		assert.Equal(t, []FileStat{{Path: "docs/naïve notes.md", Change: ChangeAdded, Additions: 1}}, stats)
+		commits, err := client.CommitsBetween(t.Context(), base, commit)
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+		assert.Equal(t, []string{"docs/naïve notes.md"}, commits[0].Files)
+
+		require.NoError(t, client.Restore(t.Context(), commit, []string{"docs/naïve notes.md", "café.txt"}))
+		assert.Equal(t, "notes\n", r.read("docs/naïve notes.md"))
+		assert.Equal(t, "café\n", r.read("café.txt"))
+	})
+}
+
+func TestConformance_TagsAndRefs(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		head, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+
+		require.NoError(t, client.CreateTag(t.Context(), "m-2", head))
+		require.NoError(t, client.CreateTag(t.Context(), "m-1", head))
+		require.NoError(t, client.CreateTag(t.Context(), "other", head))
+		tags, err := client.ListTags(t.Context(), "m-")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m-1", "m-2"}, tags)
+		commit, err := client.GetTagCommit(t.Context(), "m-1")
+		require.NoError(t, err)
+		assert.Equal(t, head, commit)
+		assert.ErrorIs(t, client.CreateTag(t.Context(), "m-1", head), ErrTagExists, "tags are not moved")
+
+		require.NoError(t, client.DeleteTag(t.Context(), "m-2"))
+		tags, err = client.ListTags(t.Context(), "m-")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m-1"}, tags)
+		_, err = client.GetTagCommit(t.Context(), "m-2")
+		assert.Error(t, err)
+
+		require.NoError(t, client.UpdateRef(t.Context(), "refs/mission/x/b", head))
+		require.NoError(t, client.UpdateRef(t.Context(), "refs/mission/x/a", head))
+		refs, err := client.ListRefs(t.Context(), "refs/mission/x")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"refs/mission/x/a", "refs/mission/x/b"}, refs)
+		require.NoError(t, client.DeleteRef(t.Context(), "refs/mission/x/a"))
+		refs, err = client.ListRefs(t.Context(), "refs/mission")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"refs/mission/x/b"}, refs)
+
+		branch, err := client.CurrentBranch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "main", branch)
+	})
+}
+
+func TestConformance_RestoreAndSoftReset(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		base, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		require.NoError(t, client.CreateTag(t.Context(), "checkpoint", base))
+
+		r.write("README.md", "# Changed\n")
+		r.write("new.txt", "new\n")
+		require.NoError(t, client.Restore(t.Context(), "checkpoint", []string{"README.md", "new.txt"}))
+		assert.Equal(t, "# Test\n", r.read("README.md"))
+		assert.Equal(t, "new\n", r.read("new.txt"), "files the checkpoint lacks are left alone")
+		assert.Error(t, client.Restore(t.Context(), "missing", []string{"README.md"}))
+
+		// A checkpoint that differs from HEAD is restored to the index as well
+		r.write("README.md", "# Checkpoint\n")
+		require.NoError(t, client.Add(t.Context(), []string{"README.md"}))
+		checkpoint, err := client.Commit(t.Context(), "Checkpoint")
+		require.NoError(t, err)
+		require.NoError(t, client.SoftReset(t.Context(), base))
+		require.NoError(t, client.Add(t.Context(), []string{"README.md"}))
+		r.write("README.md", "# Test\n")
+		require.NoError(t, client.Add(t.Context(), []string{"README.md"}))
+		require.NoError(t, client.Restore(t.Context(), checkpoint, []string{"README.md"}))
+		assert.Equal(t, "# Checkpoint\n", r.read("README.md"))
+		unstaged, err := client.GetUnstagedFiles(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"new.txt"}, unstaged)
+		changes, err := client.GetChangedFiles(t.Context(), "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{{Path: "README.md", Change: ChangeModified}, {Path: "new.txt", Change: ChangeAdded}}, changes)
+
+		require.NoError(t, client.Add(t.Context(), []string{"new.txt"}))
+		commit, err := client.Commit(t.Context(), "Add new")
+		require.NoError(t, err)
+		parent, err := client.GetCommitParent(t.Context(), commit)
+		require.NoError(t, err)
+		assert.Equal(t, base, parent)
+
+		require.NoError(t, client.SoftReset(t.Context(), base))
+		head, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, base, head)
+		assert.Equal(t, "new\n", r.read("new.txt"), "a soft reset keeps the working tree")
+		tracked, err := client.IsTracked(t.Context(), "new.txt")
+		require.NoError(t, err)
+		assert.True(t, tracked, "a soft reset keeps the index")
+		_, err = client.Commit(t.Context(), "Add new again")
+		assert.NoError(t, err)
+	})
+}
+
+func TestConformance_SnapshotFiles(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		head, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		r.write("staged.txt", "staged\n")
+		require.NoError(t, client.Add(t.Context(), []string{"staged.txt"}))
+		r.write("README.md", "# Snapshot\n")
+		r.write("new.txt", "new\n")
+
+		ref := "refs/mission/m/baseline"
+		snapshot, err := client.SnapshotFiles(t.Context(), ref, "HEAD", []string{"README.md", "new.txt", "missing.txt"}, "snapshot")
+		require.NoError(t, err)
+		commit, err := client.GetTagCommit(t.Context(), ref)
+		require.NoError(t, err)
+		assert.Equal(t, snapshot, commit)
+		parent, err := client.GetCommitParent(t.Context(), snapshot)
+		require.NoError(t, err)
+		assert.Equal(t, head, parent)
+		message, err := client.GetCommitMessage(t.Context(), snapshot)
+		require.NoError(t, err)
+		assert.Equal(t, "snapshot", message)
+		stats, err := client.DiffStat(t.Context(), head, snapshot)
+		require.NoError(t, err)
+		assert.Equal(t, []FileStat{
+			{Path: "README.md", Change: ChangeModified, Additions: 1, Deletions: 1},
+			{Path: "new.txt", Change: ChangeAdded, Additions: 1},
+		}, stats)
+		assert.Equal(t, "# Snapshot\n", r.git("show", snapshot+":README.md"))
+
+		// HEAD, the index and the working tree are untouched
+		assert.Equal(t, head+"\n", r.git("rev-parse", "HEAD"))
+		assert.Equal(t, "staged.txt\n", r.git("diff", "--cached", "--name-only"))
+		assert.Equal(t, "# Snapshot\n", r.read("README.md"))
+
+		_, err = client.SnapshotFiles(t.Context(), ref, snapshot, []string{"README.md", "new.txt"}, "unchanged")
+		assert.ErrorIs(t, err, ErrNoChanges)
+
+		// Files missing from the working tree are left out of the snapshot
+		require.NoError(t, os.Remove(filepath.Join(r.dir, "README.md")))
+		deleted, err := client.SnapshotFiles(t.Context(), ref, "HEAD", []string{"README.md"}, "deleted")
+		require.NoError(t, err)
+		stats, err = client.DiffStat(t.Context(), head, deleted)
+		require.NoError(t, err)
+		assert.Equal(t, []FileStat{{Path: "README.md", Change: ChangeDeleted, Deletions: 1}}, stats)
+	})
+}
+
+func TestConformance_StashAndApply(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		r.write("lines.txt", "1\n2\n3\n4\n5\n6\n7\n")
+		require.NoError(t, client.Add(t.Context(), []string{"lines.txt"}))
+		head, err := client.Commit(t.Context(), "Add lines")
+		require.NoError(t, err)
+
+		r.write("staged.txt", "staged\n")
+		require.NoError(t, client.Add(t.Context(), []string{"staged.txt"}))
+		r.write("README.md", "# Stashed\n")
+		r.write("lines.txt", "one\n2\n3\nfour\n5\n6\n7\n")
+		r.write("new.txt", "new\n")
+
+		ref := "refs/mission/paused/m"
+		files := []string{"README.md", "lines.txt", "new.txt"}
+		stash, err := client.StashChanges(t.Context(), ref, files, "pause m")
+		require.NoError(t, err)
+		commit, err := client.GetTagCommit(t.Context(), ref)
+		require.NoError(t, err)
+		assert.Equal(t, stash, commit)
+		parent, err := client.GetCommitParent(t.Context(), stash)
+		require.NoError(t, err)
+		assert.Equal(t, head, parent)
+		stats, err := client.DiffStat(t.Context(), head, stash)
+		require.NoError(t, err)
+		assert.Equal(t, []FileStat{
+			{Path: "README.md", Change: ChangeModified, Additions: 1, Deletions: 1},
+			{Path: "lines.txt", Change: ChangeModified, Additions: 2, Deletions: 2},
+			{Path: "new.txt", Change: ChangeAdded, Additions: 1},
+		}, stats, "only the stashed files are recorded")
+
+		// The stashed files are reverted; other staged changes and HEAD are kept
+		assert.Equal(t, "# Test\n", r.read("README.md"))
+		assert.Equal(t, "1\n2\n3\n4\n5\n6\n7\n", r.read("lines.txt"))
+		assert.NoFileExists(t, filepath.Join(r.dir, "new.txt"))
+		assert.Equal(t, "staged.txt\n", r.git("diff", "--cached", "--name-only"))
+		assert.Equal(t, head+"\n", r.git("rev-parse", "HEAD"))
+		_, err = client.StashChanges(t.Context(), ref, files, "nothing")
+		assert.ErrorIs(t, err, ErrNoChanges)
+
+		// Changes to other lines since the stash merge cleanly
+		r.write("lines.txt", "1\n2\n3\n4\n5\n6\nseven\n")
+		conflicts, err := client.ApplyStash(t.Context(), ref)
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+		assert.Equal(t, "# Stashed\n", r.read("README.md"))
+		assert.Equal(t, "one\n2\n3\nfour\n5\n6\nseven\n", r.read("lines.txt"))
+		assert.Equal(t, "new\n", r.read("new.txt"))
+
+		// Changes to the same lines conflict
+		r.write("README.md", "# Test\n")
+		r.write("lines.txt", "1\n2\n3\nFOUR\n5\n6\n7\n")
+		conflicts, err = client.ApplyStash(t.Context(), ref)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"lines.txt"}, conflicts)
+		assert.Equal(t, "# Stashed\n", r.read("README.md"))
+		merged := r.read("lines.txt")
+		assert.Contains(t, merged, "<<<<<<< working tree\n")
+		assert.Contains(t, merged, "FOUR\n")
+		assert.Contains(t, merged, "four\n")
+		assert.Contains(t, merged, ">>>>>>> stashed\n")
+	})
+}
+
+func TestConformance_DiffWorkingTree(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		r.write("gone.txt", "gone\n")
+		require.NoError(t, client.Add(t.Context(), []string{"gone.txt"}))
+		base, err := client.Commit(t.Context(), "Add gone")
+		require.NoError(t, err)
+
+		r.write("README.md", "# Changed\nMore\n")
+		r.write("docs/new.md", "new\n")
+		r.write("debug.log", "ignored\n")
+		require.NoError(t, os.Remove(filepath.Join(r.dir, "gone.txt")))
+
+		diffs, err := client.Diff(t.Context(), base, "", nil)
+		require.NoError(t, err)
+		stats := make([]FileStat, len(diffs))
+		for i, diff := range diffs {
+			stats[i] = diff.FileStat
+		}
+		assert.Equal(t, []FileStat{
+			{Path: "README.md", Change: ChangeModified, Additions: 2, Deletions: 1},
+			{Path: "docs/new.md", Change: ChangeAdded, Additions: 1},
+			{Path: "gone.txt", Change: ChangeDeleted, Deletions: 1},
+		}, stats, "untracked files are added and ignored ones left out")
+		require.Len(t, diffs, 3)
+		assert.Contains(t, diffs[0].Patch, "+# Changed\n")
+		assert.Contains(t, diffs[0].Patch, "-# Test\n")
+		assert.Contains(t, diffs[1].Patch, "+new\n")
+
+		diffs, err = client.Diff(t.Context(), base, "", []string{"docs"})
+		require.NoError(t, err)
+		require.Len(t, diffs, 1)
+		assert.Equal(t, "docs/new.md", diffs[0].Path)
+
+		// The index is untouched
+		assert.Empty(t, r.git("diff", "--cached", "--name-only"))
+		untracked, err := client.GetUntrackedFiles(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs/new.md"}, untracked)
+	})
+}
+
+func TestConformance_BranchesAndMerges(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		base, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		require.NoError(t, client.CreateBranch(t.Context(), "feature", "HEAD"))
+		branch, err := client.CurrentBranch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "main", branch, "creating a branch does not check it out")
+
+		// Local changes are carried over to the branch
+		r.write("README.md", "# Local\n")
+		require.NoError(t, client.SwitchBranch(t.Context(), "feature"))
+		branch, err = client.CurrentBranch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "feature", branch)
+		assert.Equal(t, "# Local\n", r.read("README.md"))
+
+		r.write("a.txt", "a\n")
+		require.NoError(t, client.Add(t.Context(), []string{"a.txt"}))
+		feature, err := client.Commit(t.Context(), "Add a")
+		require.NoError(t, err)
+		require.NoError(t, client.SwitchBranch(t.Context(), "main"))
+		assert.NoFileExists(t, filepath.Join(r.dir, "a.txt"))
+		assert.Equal(t, "# Local\n", r.read("README.md"))
+		assert.Error(t, client.DeleteBranch(t.Context(), "feature"), "unmerged branches are kept")
+
+		head, err := client.MergeFastForward(t.Context(), "feature")
+		require.NoError(t, err)
+		assert.Equal(t, feature, head)
+		assert.Equal(t, "a\n", r.read("a.txt"))
+		require.NoError(t, client.DeleteBranch(t.Context(), "feature"))
+		assert.Error(t, client.SwitchBranch(t.Context(), "feature"))
+
+		// Diverged branches cannot be fast-forwarded but can be squashed
+		require.NoError(t, client.CreateBranch(t.Context(), "topic", base))
+		require.NoError(t, client.SwitchBranch(t.Context(), "topic"))
+		r.write("b.txt", "b\n")
+		require.NoError(t, client.Add(t.Context(), []string{"b.txt"}))
+		_, err = client.Commit(t.Context(), "Add b")
+		require.NoError(t, err)
+		r.write("c.txt", "c\n")
+		require.NoError(t, client.Add(t.Context(), []string{"c.txt"}))
+		_, err = client.Commit(t.Context(), "Add c")
+		require.NoError(t, err)
+
+		// Switching fails without changes when local changes would be overwritten
+		r.write("b.txt", "local b\n")
+		require.NoError(t, client.Add(t.Context(), []string{"b.txt"}))
+		assert.Error(t, client.SwitchBranch(t.Context(), "main"))
+		branch, err = client.CurrentBranch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "topic", branch)
+		assert.Equal(t, "local b\n", r.read("b.txt"))
+		r.git("checkout", "--quiet", "HEAD", "--", "b.txt")
+
+		require.NoError(t, client.SwitchBranch(t.Context(), "main"))
+		_, err = client.MergeFastForward(t.Context(), "topic")
+		assert.Error(t, err)
+		squash, err := client.MergeSquash(t.Context(), "topic", "Squash topic")
+		require.NoError(t, err)
+		parent, err := client.GetCommitParent(t.Context(), squash)
+		require.NoError(t, err)
+		assert.Equal(t, feature, parent)
+		message, err := client.GetCommitMessage(t.Context(), squash)
+		require.NoError(t, err)
+		assert.Equal(t, "Squash topic", message)
+		commits, err := client.CommitsBetween(t.Context(), feature, squash)
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+		assert.ElementsMatch(t, []string{"b.txt", "c.txt"}, commits[0].Files)
+		assert.Equal(t, "c\n", r.read("c.txt"))
+		assert.Equal(t, "# Local\n", r.read("README.md"), "the squash keeps unrelated local changes")
+	})
+}
+
+func TestConformance_Worktrees(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		head, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "m1")
+		require.NoError(t, client.AddWorktree(t.Context(), path, "mission/m1", "HEAD"))
+		content, err := os.ReadFile(filepath.Join(path, "README.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Test\n", string(content))
+		assert.Error(t, client.AddWorktree(t.Context(), path, "mission/taken", "HEAD"), "the directory is taken")
+
+		worktrees, err := client.ListWorktrees(t.Context())
+		require.NoError(t, err)
+		require.Len(t, worktrees, 2)
+		assert.True(t, sameDir(r.dir, worktrees[0].Path))
+		assert.Equal(t, Worktree{Path: worktrees[0].Path, Head: head, Branch: "main"}, worktrees[0])
+		assert.True(t, sameDir(path, worktrees[1].Path))
+		assert.Equal(t, Worktree{Path: worktrees[1].Path, Head: head, Branch: "mission/m1"}, worktrees[1])
+
+		// The git CLI sees the same worktree, checked out cleanly
+		listed := parseWorktrees(r.git("worktree", "list", "--porcelain"))
+		require.Len(t, listed, 2)
+		assert.True(t, sameDir(path, listed[1].Path))
+		assert.Equal(t, "mission/m1", listed[1].Branch)
+		status := exec.Command("git", "status", "--porcelain")
+		status.Dir = path
+		out, err := status.CombinedOutput()
+		require.NoError(t, err, string(out))
+		assert.Empty(t, string(out))
+
+		require.NoError(t, os.WriteFile(filepath.Join(path, "new.txt"), []byte("new\n"), 0644))
+		assert.Error(t, client.RemoveWorktree(t.Context(), path, false), "untracked files are kept")
+		require.NoError(t, client.RemoveWorktree(t.Context(), path, true))
+		assert.NoDirExists(t, path)
+
+		// A worktree whose directory was deleted is prunable until pruned
+		gone := filepath.Join(t.TempDir(), "m2")
+		require.NoError(t, client.AddWorktree(t.Context(), gone, "mission/m2", "HEAD"))
+		require.NoError(t, os.RemoveAll(gone))
+		worktrees, err = client.ListWorktrees(t.Context())
+		require.NoError(t, err)
+		require.Len(t, worktrees, 2)
+		assert.True(t, worktrees[1].Prunable)
+		require.NoError(t, client.PruneWorktrees(t.Context()))
+		worktrees, err = client.ListWorktrees(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, worktrees, 1)
+		assert.Len(t, parseWorktrees(r.git("worktree", "list", "--porcelain")), 1)
+	})
+}
+
+func TestConformance_Errors(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		err := client.Add(t.Context(), []string{"missing.txt"})
+		assert.ErrorIs(t, err, ErrPathspec)
+
+		lock := filepath.Join(r.dir, ".git", "index.lock")
+		require.NoError(t, os.WriteFile(lock, nil, 0644))
+		r.write("a.txt", "a\n")
+		err = client.Add(t.Context(), []string{"a.txt"})
+		assert.ErrorIs(t, err, ErrIndexLocked)
+		require.NoError(t, os.Remove(lock))
+		require.NoError(t, client.Add(t.Context(), []string{"a.txt"}))
+	})
+}
+
+func TestConformance_Timeout(t *testing.T) {
+	for _, kind := range []string{ClientCmd, ClientGoGit} {
+		t.Run(kind, func(t *testing.T) {
+			r := newConformanceRepo(t)
+			client, err := NewClient(t.Context(), r.dir, ClientOptions{Kind: kind, Timeout: 200 * time.Millisecond})
+			require.NoError(t, err)
+
+			r.hook("pre-commit", "sleep 5")
+			r.write("a.txt", "a\n")
+			require.NoError(t, client.Add(t.Context(), []string{"a.txt"}))
+			start := time.Now()
+			_, err = client.Commit(t.Context(), "slow")
+			assert.ErrorIs(t, err, ErrTimeout)
+			assert.Less(t, time.Since(start), 4*time.Second)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			cancel()
+			_, err = client.Commit(ctx, "cancelled")
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
+
+func TestConformance_DoneContext(t *testing.T) {
+	runConformance(t, func(t *testing.T, r *conformanceRepo, client GitClient) {
+		base, err := client.GetTagCommit(t.Context(), "HEAD")
+		require.NoError(t, err)
+		r.write("README.md", "# Changed\n")
+		r.write("a.txt", "a\n")
+
+		cancelled, cancel := context.WithCancel(t.Context())
+		cancel()
+		expired, cancel := context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		for ctx, want := range map[context.Context]error{cancelled: context.Canceled, expired: ErrTimeout} {
+			assert.ErrorIs(t, client.Add(ctx, []string{"README.md", "a.txt"}), want)
+			_, err = client.Commit(ctx, "not committed")
+			assert.ErrorIs(t, err, want)
+			assert.ErrorIs(t, client.Restore(ctx, base, []string{"README.md"}), want)
+			assert.ErrorIs(t, client.SoftReset(ctx, base), want)
+			assert.ErrorIs(t, client.CreateTag(ctx, "not-tagged", base), want)
+			_, err = client.GetChangedFiles(ctx, base)
+			assert.ErrorIs(t, err, want)
+			_, err = client.Diff(ctx, base, "", nil)
+			assert.ErrorIs(t, err, want)
+		}
+
+		// Nothing was staged, committed, restored or tagged
+		assert.Equal(t, "# Changed\n", r.read("README.md"))
+		assert.Empty(t, r.git("diff", "--cached", "--name-only"))
+		assert.Equal(t, base+"\n", r.git("rev-parse", "HEAD"))
+		assert.Empty(t, r.git("tag", "-l"))
+	})
+}
+
+func TestConformance_NoCommits(t *testing.T) {
+	for name, newClient := range onDiskClients {
+		t.Run(name, func(t *testing.T) {
+			if _, err := exec.LookPath("git"); err != nil {
+				t.Skip("git is not installed")
+			}
+			t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+			r := &conformanceRepo{t: t, dir: t.TempDir()}
+			r.git("init", "--quiet", "--initial-branch=main")
+			client := newClient(t, r.dir)
+			_, err := client.GetTagCommit(t.Context(), "HEAD")
+			assert.ErrorIs(t, err, ErrNoCommits)
+			_, err = client.FindCommitsByTrailer(t.Context(), "Mission-Id", "m-1")
+			assert.ErrorIs(t, err, ErrNoCommits)
+		})
+	}
+}
+
+func TestNewClient_NotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+	dir := t.TempDir()
+	for _, kind := range []string{ClientCmd, ClientGoGit} {
+		_, err := NewClient(t.Context(), dir, ClientOptions{Kind: kind})
+		assert.ErrorIs(t, err, ErrNotRepository, kind)
+	}
+	_, err := NewClient(t.Context(), dir, ClientOptions{Kind: "svn"})
+	assert.ErrorContains(t, err, `unknown git client "svn"`)
+}
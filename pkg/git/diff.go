@@ -0,0 +1,149 @@
+package git
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// binarySniffLen is how much of a file is searched for NUL bytes, as git does
+const binarySniffLen = 8000
+
+// filePatch adapts two versions of a file to go-git's diff.FilePatch so it can be
+// rendered with the unified encoder
+type filePatch struct {
+	path     string
+	from, to fileVersion
+	chunks   []fdiff.Chunk
+}
+
+// newFilePatch computes the line changes turning from into to
+func newFilePatch(path string, from, to fileVersion) *filePatch {
+	p := &filePatch{path: path, from: from, to: to}
+	if p.IsBinary() {
+		return p
+	}
+	for _, d := range diff.Do(string(from.content), string(to.content)) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		p.chunks = append(p.chunks, patchChunk{content: d.Text, op: op})
+	}
+	return p
+}
+
+func (p *filePatch) IsBinary() bool {
+	return isBinary(p.from.content) || isBinary(p.to.content)
+}
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	var from, to fdiff.File
+	if p.from.exists {
+		from = patchFile{path: p.path, content: p.from.content}
+	}
+	if p.to.exists {
+		to = patchFile{path: p.path, content: p.to.content}
+	}
+	return from, to
+}
+
+func (p *filePatch) Chunks() []fdiff.Chunk {
+	return p.chunks
+}
+
+// DiffContent returns the change and unified patch turning one version of a file into
+// another. A nil version means the file does not exist on that side.
+func DiffContent(path string, from, to []byte) (FileDiff, error) {
+	return newFilePatch(path, fileVersion{content: from, exists: from != nil}, fileVersion{content: to, exists: to != nil}).fileDiff()
+}
+
+// fileDiff returns the stat and unified patch of the change
+func (p *filePatch) fileDiff() (FileDiff, error) {
+	d := FileDiff{FileStat: FileStat{Path: p.path, Change: ChangeModified, Binary: p.IsBinary()}}
+	switch {
+	case !p.from.exists:
+		d.Change = ChangeAdded
+	case !p.to.exists:
+		d.Change = ChangeDeleted
+	}
+	for _, chunk := range p.chunks {
+		switch chunk.Type() {
+		case fdiff.Add:
+			d.Additions += countLines(chunk.Content())
+		case fdiff.Delete:
+			d.Deletions += countLines(chunk.Content())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(singlePatch{p}); err != nil {
+		return FileDiff{}, err
+	}
+	d.Patch = buf.String()
+	return d, nil
+}
+
+// singlePatch is a patch of a single file
+type singlePatch []fdiff.FilePatch
+
+func (p singlePatch) FilePatches() []fdiff.FilePatch { return p }
+func (p singlePatch) Message() string                { return "" }
+
+// patchFile is one side of a filePatch
+type patchFile struct {
+	path    string
+	content []byte
+}
+
+func (f patchFile) Hash() plumbing.Hash {
+	return plumbing.ComputeHash(plumbing.BlobObject, f.content)
+}
+func (f patchFile) Mode() filemode.FileMode { return filemode.Regular }
+func (f patchFile) Path() string            { return f.path }
+
+// patchChunk is a run of equal, added or deleted lines
+type patchChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c patchChunk) Content() string       { return c.content }
+func (c patchChunk) Type() fdiff.Operation { return c.op }
+
+// isBinary reports whether content looks binary to git
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0
+}
+
+// countLines counts the lines in a chunk, including a final line without a newline
+func countLines(content string) int {
+	lines := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		lines++
+	}
+	return lines
+}
+
+// matchesPaths reports whether path is one of paths or lies below one of them, like a
+// git pathspec. An empty list matches every path.
+func matchesPaths(path string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		p = strings.TrimSuffix(p, "/")
+		if p == "" || p == "." || p == path || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Errors a GitClient returns, possibly wrapped, for failures callers can act on. The
+// CLI implementation classifies them from git output; use errors.Is to test for them.
+var (
+	ErrNotRepository = errors.New("not a git repository")
+	ErrTagExists     = errors.New("tag already exists")
+	ErrDetachedHead  = errors.New("HEAD is detached")
+	ErrPathspec      = errors.New("pathspec did not match any files")
+	ErrIndexLocked   = errors.New("index.lock is held by another git process")
+	ErrTimeout       = errors.New("git operation timed out")
+	ErrNoCommits     = errors.New("the repository has no commits yet")
+)
+
+// gitErrorPatterns maps git output to the error it reports. Git runs with LANGUAGE=C,
+// so the messages are not translated.
+var gitErrorPatterns = []struct {
+	pattern *regexp.Regexp
+	err     error
+}{
+	{regexp.MustCompile(`(?i)not a git repository`), ErrNotRepository},
+	{regexp.MustCompile(`tag '.*' already exists`), ErrTagExists},
+	{regexp.MustCompile(`index\.lock': File exists|Unable to create '.*\.lock'`), ErrIndexLocked},
+	{regexp.MustCompile(`pathspec '.*' did not match any file`), ErrPathspec},
+	{regexp.MustCompile(`ref HEAD is not a symbolic ref|You are not currently on a branch|HEAD detached`), ErrDetachedHead},
+	{regexp.MustCompile(`argument 'HEAD(\^\{commit\})?': unknown revision|does not have any commits yet`), ErrNoCommits},
+}
+
+// CommandError is a git command that failed. Err is the classified error, such as
+// ErrIndexLocked, or nil if the output matched none.
+type CommandError struct {
+	Args     []string
+	Output   string
+	ExitCode int
+	Err      error
+	cause    error
+}
+
+// newCommandError classifies the failure of git args from its output and the error
+// exec or the context returned
+func newCommandError(ctx context.Context, args []string, output string, cause error) *CommandError {
+	e := &CommandError{Args: args, Output: output, ExitCode: -1, cause: cause}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(cause, &exitErr) {
+		e.ExitCode = exitErr.ExitCode()
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		e.Err, e.cause = ErrTimeout, ctx.Err()
+	case ctx.Err() != nil:
+		e.cause = ctx.Err()
+	default:
+		e.Err = classify(output)
+	}
+	return e
+}
+
+// contextError returns the error of a done context, reported as ErrTimeout once its
+// deadline passed like a git command the context killed, or nil while ctx is live
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// exitCode returns the exit code of a failed git command, or -1 if err is not one
+func exitCode(err error) int {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.ExitCode
+	}
+	return -1
+}
+
+// classify returns the error git output reports, or nil if it is not one of ours
+func classify(output string) error {
+	for _, p := range gitErrorPatterns {
+		if p.pattern.MatchString(output) {
+			return p.err
+		}
+	}
+	return nil
+}
+
+func (e *CommandError) Error() string {
+	command := "git"
+	if len(e.Args) > 0 {
+		command += " " + e.Args[0]
+	}
+	if e.Err == ErrTimeout || errors.Is(e.cause, context.Canceled) {
+		return fmt.Sprintf("%s: %v", command, e.Unwrap()[0])
+	}
+	if output := strings.TrimSpace(e.Output); output != "" {
+		return fmt.Sprintf("%s failed: %s", command, output)
+	}
+	return fmt.Sprintf("%s failed: %v", command, e.cause)
+}
+
+// Unwrap returns the classified error and the exec or context error behind it
+func (e *CommandError) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.Err, e.cause} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		output string
+		want   error
+	}{
+		{"fatal: not a git repository (or any of the parent directories): .git", ErrNotRepository},
+		{"fatal: tag 'm-1' already exists", ErrTagExists},
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.\n\nAnother git process seems to be running", ErrIndexLocked},
+		{"fatal: pathspec 'missing.txt' did not match any files", ErrPathspec},
+		{"error: pathspec 'nope' did not match any file(s) known to git", ErrPathspec},
+		{"fatal: ref HEAD is not a symbolic ref", ErrDetachedHead},
+		{"fatal: You are not currently on a branch.", ErrDetachedHead},
+		{"fatal: ambiguous argument 'HEAD^{commit}': unknown revision or path not in the working tree.", ErrNoCommits},
+		{"fatal: your current branch 'main' does not have any commits yet", ErrNoCommits},
+		{"fatal: ambiguous argument 'm-1^{commit}': unknown revision or path not in the working tree.", nil},
+		{"error: Your local changes to the following files would be overwritten by merge", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classify(tt.output), tt.output)
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	exitErr := exec.Command("false").Run()
+
+	err := newCommandError(t.Context(), []string{"tag", "m-1", "abc"}, "fatal: tag 'm-1' already exists\n", exitErr)
+	assert.ErrorIs(t, err, ErrTagExists)
+	assert.Equal(t, "git tag failed: fatal: tag 'm-1' already exists", err.Error())
+	assert.Equal(t, 1, exitCode(err))
+	assert.Equal(t, 1, exitCode(errors.Join(errors.New("creating checkpoint"), err)), "wrapped errors keep the exit code")
+	assert.Equal(t, -1, exitCode(errors.New("other")))
+
+	err = newCommandError(t.Context(), []string{"status"}, "", exitErr)
+	assert.Nil(t, err.Err)
+	assert.Equal(t, "git status failed: exit status 1", err.Error())
+
+	ctx, cancel := context.WithTimeout(t.Context(), 0)
+	defer cancel()
+	<-ctx.Done()
+	err = newCommandError(ctx, []string{"commit", "-m", "x"}, "fatal: Unable to create '.git/index.lock': File exists.", exitErr)
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrIndexLocked, "output of a killed command is not classified")
+	assert.Equal(t, "git commit: git operation timed out", err.Error())
+
+	ctx, cancel = context.WithCancel(t.Context())
+	cancel()
+	err = newCommandError(ctx, []string{"commit"}, "", exitErr)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "git commit: context canceled", err.Error())
+}
@@ -0,0 +1,689 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/spf13/afero"
+)
+
+// Client implementations selectable with NewClient
+const (
+	ClientCmd   = "cmd"
+	ClientGoGit = "go-git"
+)
+
+// ClientOptions selects and configures the client NewClient opens
+type ClientOptions struct {
+	// Kind is ClientCmd or ClientGoGit; empty selects ClientCmd
+	Kind string
+	// Timeout bounds each git command and hook. Zero selects DefaultTimeout and a
+	// negative value disables the limit.
+	Timeout time.Duration
+}
+
+// NewClient opens the git work tree at workDir with the implementation opts select.
+// ErrNotRepository is returned outside a work tree.
+func NewClient(ctx context.Context, workDir string, opts ClientOptions) (GitClient, error) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	switch opts.Kind {
+	case "", ClientCmd:
+		client := NewCmdGitClient(workDir)
+		client.timeout = timeout
+		if err := client.checkRepository(ctx); err != nil {
+			return nil, err
+		}
+		return client, nil
+	case ClientGoGit:
+		client, err := NewGoGitClient(workDir)
+		if err != nil {
+			return nil, err
+		}
+		client.timeout = timeout
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown git client %q, expected %s or %s", opts.Kind, ClientCmd, ClientGoGit)
+	}
+}
+
+// GoGitClient implements GitClient with go-git against a repository on disk, without
+// needing the git CLI. go-git itself cannot be interrupted, so a call checks its context
+// before starting and between files, and the context bounds the hooks it runs.
+type GoGitClient struct {
+	*repository
+	fs   afero.Fs // the work tree, rooted at root
+	root string
+	// hooksDir holds the hooks Commit runs
+	hooksDir string
+	// timeout bounds each hook; zero or less means no limit
+	timeout time.Duration
+}
+
+// NewGoGitClient opens the repository whose work tree is rooted at workDir
+func NewGoGitClient(workDir string) (*GoGitClient, error) {
+	root, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, ErrNotRepository
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening repository: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("opening work tree: %w", err)
+	}
+	if top := wt.Filesystem.Root(); !sameDir(top, root) {
+		return nil, fmt.Errorf("%s is not the top of the work tree %s", workDir, top)
+	}
+
+	client := &GoGitClient{
+		fs:      afero.NewBasePathFs(afero.NewOsFs(), root),
+		root:    root,
+		timeout: DefaultTimeout,
+	}
+	if client.hooksDir, err = hooksDir(repo, root); err != nil {
+		return nil, err
+	}
+	client.repository = &repository{repo: repo, tree: client}
+	return client, nil
+}
+
+func (c *GoGitClient) Add(ctx context.Context, files []string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	if err := c.checkIndexLock(); err != nil {
+		return err
+	}
+	wt, err := c.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := contextError(ctx); err != nil {
+			return err
+		}
+		if exists, _ := afero.Exists(c.fs, file); exists {
+			if _, err := wt.Add(file); err != nil {
+				return err
+			}
+			continue
+		}
+		// A deleted file is removed from the index; like git add, a path that is
+		// neither in the index nor on disk is an error
+		if _, err := wt.Remove(file); err != nil {
+			return fmt.Errorf("pathspec '%s': %w", file, ErrPathspec)
+		}
+	}
+	return nil
+}
+
+// Commit runs the pre-commit and commit-msg hooks, commits the index and then runs
+// the post-commit hook
+func (c *GoGitClient) Commit(ctx context.Context, message string) (string, error) {
+	return c.commit(ctx, message, true)
+}
+
+// CommitNoVerify commits the index without running hooks
+func (c *GoGitClient) CommitNoVerify(ctx context.Context, message string) (string, error) {
+	return c.commit(ctx, message, false)
+}
+
+// commit creates a commit from the index, returning ErrNoChanges if its tree matches HEAD
+func (c *GoGitClient) commit(ctx context.Context, message string, verify bool) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	if err := c.checkIndexLock(); err != nil {
+		return "", err
+	}
+	wt, err := c.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	// Like git commit -m, store the message without surrounding blank lines
+	message = strings.TrimSpace(message) + "\n"
+	if verify {
+		if message, err = c.runCommitHooks(ctx, message); err != nil {
+			return "", err
+		}
+	}
+	author, committer, err := c.signatures()
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: author, Committer: committer})
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return "", ErrNoChanges
+	}
+	if err != nil {
+		return "", err
+	}
+	if verify {
+		// Like git, a failing post-commit hook does not undo the commit
+		_ = c.runHook(ctx, "post-commit")
+	}
+	return hash.String(), nil
+}
+
+// CreateTag creates a lightweight tag like git tag
+func (c *GoGitClient) CreateTag(ctx context.Context, name string, commitHash string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	ref := plumbing.NewTagReferenceName(name)
+	if _, err := c.repo.Reference(ref, false); err == nil {
+		return fmt.Errorf("tag %s: %w", name, ErrTagExists)
+	}
+	commit, err := c.resolveCommit(strings.TrimSpace(commitHash))
+	if err != nil {
+		return err
+	}
+	return c.repo.Storer.SetReference(plumbing.NewHashReference(ref, commit.Hash))
+}
+
+// MergeSquash merges file by file against the merge base and refuses, without changing
+// anything, when a file was changed on both sides or has local changes. The commit runs
+// the hooks like git commit.
+func (c *GoGitClient) MergeSquash(ctx context.Context, branch, message string) (string, error) {
+	paths, err := c.squashFiles(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+	if err := c.Add(ctx, paths); err != nil {
+		return "", err
+	}
+	return c.Commit(ctx, message)
+}
+
+func (c *GoGitClient) readFile(path string) ([]byte, error) {
+	return afero.ReadFile(c.fs, path)
+}
+
+func (c *GoGitClient) writeVersion(path string, version fileVersion) error {
+	if !version.exists {
+		if err := c.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeFile(c.fs, path, version.content)
+}
+
+// walkWorkingTree calls fn with every file outside .git that the ignore rules do not
+// exclude
+func (c *GoGitClient) walkWorkingTree(ctx context.Context, fn func(path string) error) error {
+	patterns, err := c.ignorePatterns()
+	if err != nil {
+		return err
+	}
+	matcher := gitignore.NewMatcher(patterns)
+
+	return afero.Walk(c.fs, ".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := contextError(ctx); err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		path = filepath.ToSlash(path)
+		if path == ".git" || matcher.Match(strings.Split(path, "/"), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// status returns the go-git status of the work tree, also applying the global excludes
+// file go-git leaves out
+func (c *GoGitClient) status() (git.Status, error) {
+	return worktreeStatus(c.repo)
+}
+
+// worktreeStatus returns the go-git status of the work tree of repo
+func worktreeStatus(repo *git.Repository) (git.Status, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	if wt.Excludes, err = globalExcludes(); err != nil {
+		return nil, err
+	}
+	return wt.Status()
+}
+
+// checkIndexLock fails with ErrIndexLocked while a git process holds the index, since
+// go-git does not take the lock itself
+func (c *GoGitClient) checkIndexLock() error {
+	lock := filepath.Join(gitDir(c.repo), "index.lock")
+	if _, err := os.Stat(lock); err == nil {
+		return fmt.Errorf("%s exists: %w", lock, ErrIndexLocked)
+	}
+	return nil
+}
+
+// sameDir reports whether two absolute paths name the same directory, resolving symlinks
+func sameDir(a, b string) bool {
+	if a == b {
+		return true
+	}
+	resolvedA, errA := filepath.EvalSymlinks(a)
+	resolvedB, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && resolvedA == resolvedB
+}
+
+// hooksDir returns core.hooksPath, relative paths being taken from the work tree root,
+// or the hooks directory of the repository
+func hooksDir(repo *git.Repository, root string) (string, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("reading repository config: %w", err)
+	}
+	if path := cfg.Raw.Section("core").Option("hooksPath"); path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		return path, nil
+	}
+	return filepath.Join(commonDir(repo), "hooks"), nil
+}
+
+// gitDir returns the git directory of the work tree, such as <root>/.git
+func gitDir(repo *git.Repository) string {
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		return storage.Filesystem().Root()
+	}
+	return ""
+}
+
+// commonDir returns the git directory shared by all worktrees of the repository
+func commonDir(repo *git.Repository) string {
+	dir := gitDir(repo)
+	if content, err := os.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(content))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+		return filepath.Clean(common)
+	}
+	return dir
+}
+
+// runCommitHooks runs the pre-commit and commit-msg hooks like git commit and returns
+// the message as commit-msg left it
+func (c *GoGitClient) runCommitHooks(ctx context.Context, message string) (string, error) {
+	if err := c.runHook(ctx, "pre-commit"); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(gitDir(c.repo), "COMMIT_EDITMSG")
+	if err := os.WriteFile(path, []byte(message), 0644); err != nil {
+		return "", fmt.Errorf("writing commit message: %w", err)
+	}
+	if err := c.runHook(ctx, "commit-msg", path); err != nil {
+		return "", err
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading commit message: %w", err)
+	}
+	return string(edited), nil
+}
+
+// runHook runs the named hook from the work tree root if it exists and is executable.
+// A hook that outlives the timeout of the client fails with ErrTimeout.
+func (c *GoGitClient) runHook(ctx context.Context, name string, args ...string) error {
+	path := filepath.Join(c.hooksDir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return nil
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = c.root
+	cmd.Env = append(os.Environ(), "GIT_DIR="+gitDir(c.repo), "GIT_INDEX_FILE="+filepath.Join(gitDir(c.repo), "index"))
+	cmd.WaitDelay = waitDelay
+	output, err := cmd.CombinedOutput()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s hook: %w", name, ErrTimeout)
+	case ctx.Err() != nil:
+		return fmt.Errorf("%s hook: %w", name, ctx.Err())
+	case err != nil:
+		return fmt.Errorf("%s hook failed: %s", name, output)
+	}
+	return nil
+}
+
+// signatures returns the author and committer of a new commit, which come from the
+// GIT_AUTHOR_* and GIT_COMMITTER_* variables or user.name and user.email, as with git
+func (c *GoGitClient) signatures() (*object.Signature, *object.Signature, error) {
+	cfg, err := c.repo.ConfigScoped(config.GlobalScope)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading git config: %w", err)
+	}
+	author := &object.Signature{
+		Name:  firstNonEmpty(os.Getenv("GIT_AUTHOR_NAME"), cfg.Author.Name, cfg.User.Name),
+		Email: firstNonEmpty(os.Getenv("GIT_AUTHOR_EMAIL"), cfg.Author.Email, cfg.User.Email),
+	}
+	committer := &object.Signature{
+		Name:  firstNonEmpty(os.Getenv("GIT_COMMITTER_NAME"), cfg.Committer.Name, cfg.User.Name),
+		Email: firstNonEmpty(os.Getenv("GIT_COMMITTER_EMAIL"), cfg.Committer.Email, cfg.User.Email),
+	}
+	if author.Name == "" || author.Email == "" || committer.Name == "" || committer.Email == "" {
+		return nil, nil, fmt.Errorf("no git identity; set user.name and user.email with git config")
+	}
+	author.When = time.Now()
+	committer.When = author.When
+	return author, committer, nil
+}
+
+// ignorePatterns returns the ignore rules of the work tree: .gitignore files,
+// .git/info/exclude and the global excludes file
+func (c *GoGitClient) ignorePatterns() ([]gitignore.Pattern, error) {
+	wt, err := c.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := gitignore.ReadPatterns(wt.Filesystem, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading ignore rules: %w", err)
+	}
+	global, err := globalExcludes()
+	if err != nil {
+		return nil, err
+	}
+	return append(global, patterns...), nil
+}
+
+// globalExcludes reads the file core.excludesFile of ~/.gitconfig names, which go-git
+// does not apply by itself
+func globalExcludes() ([]gitignore.Pattern, error) {
+	patterns, err := gitignore.LoadGlobalPatterns(osfs.New("/"))
+	if err != nil {
+		return nil, fmt.Errorf("reading global ignore rules: %w", err)
+	}
+	return patterns, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// AddWorktree creates branch at startPoint and checks it out in a new linked worktree
+// at path, laid out as git worktree add does: an administrative directory under
+// .git/worktrees and a .git file in the worktree pointing at it
+func (c *GoGitClient) AddWorktree(ctx context.Context, path, branch, startPoint string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	path = c.worktreePath(path)
+	if entries, err := os.ReadDir(path); err == nil && len(entries) > 0 {
+		return fmt.Errorf("'%s' already exists", path)
+	}
+	commit, err := c.resolveCommit(startPoint)
+	if err != nil {
+		return err
+	}
+	if err := c.CreateBranch(ctx, branch, commit.Hash.String()); err != nil {
+		return err
+	}
+
+	admin, err := c.addWorktreeAdmin(path, branch)
+	if err == nil {
+		err = checkoutWorktree(path, commit.Hash)
+	}
+	if err != nil {
+		os.RemoveAll(admin)
+		os.RemoveAll(path)
+		_ = c.repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(branch))
+		return fmt.Errorf("adding worktree %s: %w", path, err)
+	}
+	return nil
+}
+
+// addWorktreeAdmin creates the administrative directory of a worktree at path with
+// branch checked out and the .git file linking the worktree to it
+func (c *GoGitClient) addWorktreeAdmin(path, branch string) (string, error) {
+	worktrees := filepath.Join(commonDir(c.repo), "worktrees")
+	if err := os.MkdirAll(worktrees, 0755); err != nil {
+		return "", err
+	}
+	// Like git, name the directory after the worktree, numbered when that is taken
+	name := filepath.Base(path)
+	admin := filepath.Join(worktrees, name)
+	for i := 1; ; i++ {
+		err := os.Mkdir(admin, 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+		admin = filepath.Join(worktrees, fmt.Sprintf("%s%d", name, i))
+	}
+
+	files := map[string]string{
+		filepath.Join(admin, "gitdir"):    filepath.Join(path, ".git") + "\n",
+		filepath.Join(admin, "commondir"): "../..\n",
+		filepath.Join(admin, "HEAD"):      "ref: " + plumbing.NewBranchReferenceName(branch).String() + "\n",
+		filepath.Join(path, ".git"):       "gitdir: " + admin + "\n",
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return admin, err
+	}
+	for file, content := range files {
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			return admin, err
+		}
+	}
+	return admin, nil
+}
+
+// checkoutWorktree writes the files and index of commit into the linked worktree at path
+func checkoutWorktree(path string, commit plumbing.Hash) error {
+	linked, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return err
+	}
+	wt, err := linked.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Reset(&git.ResetOptions{Commit: commit, Mode: git.HardReset})
+}
+
+// RemoveWorktree deletes a linked worktree and its administrative directory. Without
+// force a worktree with modified or untracked files is refused, like git worktree remove.
+func (c *GoGitClient) RemoveWorktree(ctx context.Context, path string, force bool) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	linked, err := c.linkedWorktrees()
+	if err != nil {
+		return err
+	}
+	path = c.worktreePath(path)
+	for _, wt := range linked {
+		if !sameDir(wt.Path, path) {
+			continue
+		}
+		if !force && !wt.Prunable {
+			clean, err := worktreeClean(wt.Path)
+			if err != nil {
+				return err
+			}
+			if !clean {
+				return fmt.Errorf("'%s' contains modified or untracked files, use --force to delete it", path)
+			}
+		}
+		if err := os.RemoveAll(wt.Path); err != nil {
+			return err
+		}
+		return os.RemoveAll(wt.admin)
+	}
+	return fmt.Errorf("'%s' is not a working tree", path)
+}
+
+// worktreeClean reports whether the linked worktree at path has no modified or
+// untracked files
+func worktreeClean(path string) (bool, error) {
+	linked, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return false, fmt.Errorf("opening worktree %s: %w", path, err)
+	}
+	status, err := worktreeStatus(linked)
+	if err != nil {
+		return false, err
+	}
+	return status.IsClean(), nil
+}
+
+// ListWorktrees lists the main worktree followed by the linked worktrees in path order
+func (c *GoGitClient) ListWorktrees(ctx context.Context) ([]Worktree, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	head, err := c.GetTagCommit(ctx, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branch, err := c.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	worktrees := []Worktree{{Path: c.root, Head: head, Branch: branch}}
+
+	linked, err := c.linkedWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	for _, wt := range linked {
+		worktrees = append(worktrees, wt.Worktree)
+	}
+	return worktrees, nil
+}
+
+// PruneWorktrees deletes the administrative directories of worktrees whose directory
+// no longer exists
+func (c *GoGitClient) PruneWorktrees(ctx context.Context) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	linked, err := c.linkedWorktrees()
+	if err != nil {
+		return err
+	}
+	for _, wt := range linked {
+		if wt.Prunable {
+			if err := os.RemoveAll(wt.admin); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// linkedWorktree is a linked worktree and its administrative directory
+type linkedWorktree struct {
+	Worktree
+	admin string
+}
+
+// worktreePath returns path made absolute against the work tree root
+func (c *GoGitClient) worktreePath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.root, path)
+	}
+	return filepath.Clean(path)
+}
+
+// linkedWorktrees reads the administrative directories under .git/worktrees, sorted
+// by worktree path
+func (c *GoGitClient) linkedWorktrees() ([]linkedWorktree, error) {
+	dir := filepath.Join(commonDir(c.repo), "worktrees")
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading worktrees: %w", err)
+	}
+
+	var worktrees []linkedWorktree
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		admin := filepath.Join(dir, entry.Name())
+		wt := linkedWorktree{admin: admin}
+		gitdir, err := os.ReadFile(filepath.Join(admin, "gitdir"))
+		if err != nil {
+			// git lists a worktree without a gitdir file as prunable under its admin path
+			wt.Path, wt.Prunable = admin, true
+			worktrees = append(worktrees, wt)
+			continue
+		}
+		wt.Path = filepath.Dir(strings.TrimSpace(string(gitdir)))
+		if _, err := os.Stat(wt.Path); err != nil {
+			wt.Prunable = true
+		}
+
+		head, err := os.ReadFile(filepath.Join(admin, "HEAD"))
+		if err != nil {
+			return nil, fmt.Errorf("reading HEAD of worktree %s: %w", wt.Path, err)
+		}
+		if target, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: "); ok {
+			name := plumbing.ReferenceName(target)
+			wt.Branch = name.Short()
+			if ref, err := c.repo.Reference(name, true); err == nil {
+				wt.Head = ref.Hash().String()
+			}
+		} else {
+			wt.Head = strings.TrimSpace(string(head))
+		}
+		worktrees = append(worktrees, wt)
+	}
+	sort.Slice(worktrees, func(i, j int) bool { return worktrees[i].Path < worktrees[j].Path })
+	return worktrees, nil
+}
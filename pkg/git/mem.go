@@ -1,7 +1,12 @@
 package git
 
 import (
-	"io"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
@@ -10,48 +15,49 @@ import (
 	"github.com/spf13/afero"
 )
 
-// MemGitClient implements GitClient using go-git in memory
+// MemGitClient implements GitClient using go-git in memory, with an afero filesystem
+// as the working tree. It is meant for tests: commits have a fixed author and run no
+// hooks, and linked worktrees are only recorded.
 type MemGitClient struct {
-	repo *git.Repository
-	fs   afero.Fs // The filesystem used by the service (afero)
+	*repository
+	fs afero.Fs // The filesystem used by the service (afero)
+	// worktrees records linked worktrees by path; only their branch and directory exist
+	worktrees map[string]Worktree
 }
 
 // NewMemGitClient creates a new MemGitClient
 func NewMemGitClient(repo *git.Repository, fs afero.Fs) *MemGitClient {
-	return &MemGitClient{repo: repo, fs: fs}
+	c := &MemGitClient{fs: fs}
+	c.repository = &repository{repo: repo, tree: c}
+	return c
 }
 
-func (c *MemGitClient) Add(files []string) error {
+func (c *MemGitClient) Add(ctx context.Context, files []string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
 	wt, err := c.repo.Worktree()
 	if err != nil {
 		return err
 	}
 	for _, file := range files {
+		if err := contextError(ctx); err != nil {
+			return err
+		}
 		// Check if file exists in afero fs
 		exists, _ := afero.Exists(c.fs, file)
 		if !exists {
 			// If file doesn't exist, it might be a deletion
 			// We need to remove it from the index
-			_, err := wt.Remove(file)
-			if err != nil {
-				// Ignore if file is not in worktree
-			}
+			_, _ = wt.Remove(file)
 			continue
 		}
-		// We need to ensure the file exists in the go-git worktree filesystem
-		// Since we are mocking, we copy from afero fs to go-git fs
+		// The file must first be copied from the afero fs to the go-git fs
 		content, err := afero.ReadFile(c.fs, file)
 		if err != nil {
 			return err
 		}
-
-		f, err := wt.Filesystem.Create(file)
-		if err != nil {
-			return err
-		}
-		_, err = f.Write(content)
-		f.Close()
-		if err != nil {
+		if err := c.mirrorFile(file, content); err != nil {
 			return err
 		}
 
@@ -63,245 +69,229 @@ func (c *MemGitClient) Add(files []string) error {
 	return nil
 }
 
-func (c *MemGitClient) Commit(message string) (string, error) {
-	wt, err := c.repo.Worktree()
-	if err != nil {
+// Commit commits the index; there are no hooks to run in memory
+func (c *MemGitClient) Commit(ctx context.Context, message string) (string, error) {
+	return c.CommitNoVerify(ctx, message)
+}
+
+// CommitNoVerify creates a commit from the index, returning ErrNoChanges if its tree
+// matches HEAD
+func (c *MemGitClient) CommitNoVerify(ctx context.Context, message string) (string, error) {
+	if err := contextError(ctx); err != nil {
 		return "", err
 	}
-
-	// Check for changes
-	status, err := wt.Status()
+	wt, err := c.repo.Worktree()
 	if err != nil {
 		return "", err
 	}
-	if status.IsClean() {
-		return "", ErrNoChanges
-	}
-
 	hash, err := wt.Commit(message, &git.CommitOptions{
 		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
 	})
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return "", ErrNoChanges
+	}
 	if err != nil {
 		return "", err
 	}
 	return hash.String(), nil
 }
 
-// CommitNoVerify delegates to Commit since in-memory git has no hooks.
-func (c *MemGitClient) CommitNoVerify(message string) (string, error) {
-	return c.Commit(message)
-}
-
-func (c *MemGitClient) CreateTag(name string, commitHash string) error {
+// CreateTag creates an annotated tag
+func (c *MemGitClient) CreateTag(ctx context.Context, name string, commitHash string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
 	hash := plumbing.NewHash(commitHash)
 	_, err := c.repo.CreateTag(name, hash, &git.CreateTagOptions{
 		Message: name,
 		Tagger:  &object.Signature{Name: "Mission Toolkit", Email: "mission@toolkit.local"},
 	})
+	if errors.Is(err, git.ErrTagExists) {
+		return fmt.Errorf("tag %s: %w", name, ErrTagExists)
+	}
 	return err
 }
 
-func (c *MemGitClient) Restore(checkpointName string, files []string) error {
-	tagRef, err := c.repo.Tag(checkpointName)
-	if err != nil {
-		return err
-	}
+func (c *MemGitClient) readFile(path string) ([]byte, error) {
+	return afero.ReadFile(c.fs, path)
+}
 
-	commit, err := c.repo.CommitObject(tagRef.Hash())
-	if err != nil {
-		// Try annotated tag
-		tagObj, errTag := c.repo.TagObject(tagRef.Hash())
-		if errTag == nil {
-			commit, err = c.repo.CommitObject(tagObj.Target)
-			if err != nil {
-				return err
-			}
-		} else {
+// writeVersion writes or removes path in both the afero and go-git filesystems
+func (c *MemGitClient) writeVersion(path string, version fileVersion) error {
+	if !version.exists {
+		c.fs.Remove(path)
+		wt, err := c.repo.Worktree()
+		if err != nil {
 			return err
 		}
+		wt.Filesystem.Remove(path)
+		return nil
 	}
-
-	tree, err := commit.Tree()
-	if err != nil {
+	if err := writeFile(c.fs, path, version.content); err != nil {
 		return err
 	}
+	return c.mirrorFile(path, version.content)
+}
 
+// mirrorFile writes content to path in the go-git fs
+func (c *MemGitClient) mirrorFile(path string, content []byte) error {
 	wt, err := c.repo.Worktree()
 	if err != nil {
 		return err
 	}
+	f, err := wt.Filesystem.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(content)
+	return err
+}
 
-	for _, path := range files {
-		file, err := tree.File(path)
-		if err != nil {
-			// File not in checkpoint, remove from fs
-			c.fs.Remove(path)
-			wt.Filesystem.Remove(path)
-			continue
-		}
-
-		reader, err := file.Reader()
+// walkWorkingTree calls fn with every file outside hidden directories such as .git
+// and .mission
+func (c *MemGitClient) walkWorkingTree(ctx context.Context, fn func(path string) error) error {
+	return afero.Walk(c.fs, ".", func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		content, err := io.ReadAll(reader)
-		reader.Close()
-		if err != nil {
+		if err := contextError(ctx); err != nil {
 			return err
 		}
-
-		// Update afero fs
-		if err := afero.WriteFile(c.fs, path, content, 0644); err != nil {
-			return err
+		if path == "." {
+			return nil
 		}
-
-		// Update go-git fs
-		f, err := wt.Filesystem.Create(path)
-		if err != nil {
-			return err
+		if info.IsDir() {
+			if strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
 		}
-		f.Write(content)
-		f.Close()
-	}
-	return nil
+		return fn(filepath.ToSlash(path))
+	})
 }
 
-func (c *MemGitClient) ListTags(prefix string) ([]string, error) {
-	var tags []string
-	iter, err := c.repo.Tags()
+func (c *MemGitClient) status() (git.Status, error) {
+	wt, err := c.repo.Worktree()
 	if err != nil {
 		return nil, err
 	}
-	err = iter.ForEach(func(ref *plumbing.Reference) error {
-		if strings.HasPrefix(ref.Name().Short(), prefix) {
-			tags = append(tags, ref.Name().Short())
-		}
-		return nil
-	})
-	return tags, err
+	return wt.Status()
 }
 
-func (c *MemGitClient) DeleteTag(name string) error {
-	return c.repo.DeleteTag(name)
+// checkIndexLock never fails, since nothing else can hold an in-memory index
+func (c *MemGitClient) checkIndexLock() error {
+	return nil
 }
 
-func (c *MemGitClient) GetTagCommit(tagName string) (string, error) {
-	tagRef, err := c.repo.Tag(tagName)
-	if err == nil {
-		if tagObj, err := c.repo.TagObject(tagRef.Hash()); err == nil {
-			return tagObj.Target.String(), nil
-		}
-		return tagRef.Hash().String(), nil
+// MergeSquash merges file by file against the merge base and refuses, without changing
+// anything, when a file was changed on both sides or has local changes.
+func (c *MemGitClient) MergeSquash(ctx context.Context, branch, message string) (string, error) {
+	paths, err := c.squashFiles(ctx, branch)
+	if err != nil {
+		return "", err
 	}
-
-	// Fall back to interpreting tagName as commit-ish (e.g., prefix + "^{commit}")
-	hash := plumbing.NewHash(tagName)
-	if hash.IsZero() {
+	if err := c.Add(ctx, paths); err != nil {
 		return "", err
 	}
-	return hash.String(), nil
+	return c.Commit(ctx, message)
 }
 
-func (c *MemGitClient) SoftReset(commitHash string) error {
-	wt, err := c.repo.Worktree()
-	if err != nil {
+// AddWorktree creates the branch and an empty directory for the worktree; the files of
+// startPoint are not checked out there
+func (c *MemGitClient) AddWorktree(ctx context.Context, path, branch, startPoint string) error {
+	if err := contextError(ctx); err != nil {
 		return err
 	}
-	hash := plumbing.NewHash(commitHash)
-	return wt.Reset(&git.ResetOptions{
-		Commit: hash,
-		Mode:   git.SoftReset,
-	})
-}
-
-func (c *MemGitClient) GetCommitMessage(commitHash string) (string, error) {
-	var hash plumbing.Hash
-	if commitHash == "HEAD" {
-		ref, err := c.repo.Head()
-		if err != nil {
-			return "", err
-		}
-		hash = ref.Hash()
-	} else {
-		hash = plumbing.NewHash(commitHash)
+	path = filepath.Clean(path)
+	if _, ok := c.worktrees[path]; ok {
+		return fmt.Errorf("worktree %s already exists", path)
 	}
-
-	commit, err := c.repo.CommitObject(hash)
-	if err != nil {
-		return "", err
+	if exists, _ := afero.Exists(c.fs, path); exists {
+		return fmt.Errorf("%s already exists", path)
 	}
-	return strings.TrimSpace(commit.Message), nil
-}
-
-func (c *MemGitClient) IsTracked(path string) (bool, error) {
-	// Check the index directly to see if the file is tracked
-	idx, err := c.repo.Storer.Index()
+	if err := c.CreateBranch(ctx, branch, startPoint); err != nil {
+		return err
+	}
+	head, err := c.GetTagCommit(ctx, branch)
 	if err != nil {
-		return false, err
+		return err
 	}
-
-	_, err = idx.Entry(path)
-	if err == object.ErrEntryNotFound || (err != nil && strings.Contains(err.Error(), "entry not found")) {
-		return false, nil
+	if err := c.fs.MkdirAll(path, 0755); err != nil {
+		return err
 	}
-	if err != nil {
-		return false, err
+	if c.worktrees == nil {
+		c.worktrees = make(map[string]Worktree)
 	}
-
-	return true, nil
+	c.worktrees[path] = Worktree{Path: path, Head: head, Branch: branch}
+	return nil
 }
 
-func (c *MemGitClient) GetCommitParent(commitHash string) (string, error) {
-	hash := plumbing.NewHash(commitHash)
-	commit, err := c.repo.CommitObject(hash)
-	if err != nil {
-		return "", err
+// RemoveWorktree deletes the worktree directory. Without force any file outside the
+// .mission directory counts as a local change and is refused.
+func (c *MemGitClient) RemoveWorktree(ctx context.Context, path string, force bool) error {
+	if err := contextError(ctx); err != nil {
+		return err
 	}
-	if commit.NumParents() == 0 {
-		return "", nil // No parent (initial commit)
+	path = filepath.Clean(path)
+	if _, ok := c.worktrees[path]; !ok {
+		return fmt.Errorf("%s is not a worktree", path)
 	}
-	parent, err := commit.Parent(0)
-	if err != nil {
-		return "", err
+	if !force {
+		dirty := false
+		afero.Walk(c.fs, path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() && info.Name() == ".mission" {
+				return filepath.SkipDir
+			}
+			dirty = dirty || !info.IsDir()
+			return nil
+		})
+		if dirty {
+			return fmt.Errorf("%s contains modified or untracked files, use force to delete it", path)
+		}
+	}
+	if err := c.fs.RemoveAll(path); err != nil {
+		return err
 	}
-	return parent.Hash.String(), nil
+	delete(c.worktrees, path)
+	return nil
 }
 
-func (c *MemGitClient) GetUnstagedFiles() ([]string, error) {
-	wt, err := c.repo.Worktree()
+func (c *MemGitClient) ListWorktrees(ctx context.Context) ([]Worktree, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	head, err := c.GetTagCommit(ctx, "HEAD")
 	if err != nil {
 		return nil, err
 	}
-	status, err := wt.Status()
+	branch, err := c.CurrentBranch(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var files []string
-	for path, s := range status {
-		// Worktree status: untracked or modified but not staged
-		if s.Worktree == git.Untracked || s.Worktree == git.Modified {
-			files = append(files, path)
-		}
+	worktrees := []Worktree{{Path: ".", Head: head, Branch: branch}}
+
+	var linked []Worktree
+	for path, wt := range c.worktrees {
+		exists, _ := afero.DirExists(c.fs, path)
+		wt.Prunable = !exists
+		linked = append(linked, wt)
 	}
-	return files, nil
+	sort.Slice(linked, func(i, j int) bool { return linked[i].Path < linked[j].Path })
+	return append(worktrees, linked...), nil
 }
 
-// GetUntrackedFiles returns files that exist in the working directory but are not tracked by git.
-func (c *MemGitClient) GetUntrackedFiles() ([]string, error) {
-	wt, err := c.repo.Worktree()
-	if err != nil {
-		return nil, err
-	}
-	status, err := wt.Status()
-	if err != nil {
-		return nil, err
+func (c *MemGitClient) PruneWorktrees(ctx context.Context) error {
+	if err := contextError(ctx); err != nil {
+		return err
 	}
-	var files []string
-	for path, s := range status {
-		// Only untracked files
-		if s.Worktree == git.Untracked {
-			files = append(files, path)
+	for path := range c.worktrees {
+		if exists, _ := afero.DirExists(c.fs, path); !exists {
+			delete(c.worktrees, path)
 		}
 	}
-	return files, nil
+	return nil
 }
@@ -98,7 +98,7 @@ func TestMemGitClient_Add(t *testing.T) {
 			}
 
 			client := NewMemGitClient(repo, fs)
-			err := client.Add(tt.addFiles)
+			err := client.Add(t.Context(), tt.addFiles)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -155,11 +155,11 @@ func TestMemGitClient_Commit(t *testing.T) {
 				// Create and add a file to have changes
 				err := afero.WriteFile(fs, "newfile.txt", []byte("new content"), 0644)
 				require.NoError(t, err)
-				err = client.Add([]string{"newfile.txt"})
+				err = client.Add(t.Context(), []string{"newfile.txt"})
 				require.NoError(t, err)
 			}
 
-			hash, err := client.Commit(tt.message)
+			hash, err := client.Commit(t.Context(), tt.message)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -226,7 +226,7 @@ func TestMemGitClient_CreateTag(t *testing.T) {
 				commitHash = head.Hash().String()
 			}
 
-			err := client.CreateTag(tt.tagName, commitHash)
+			err := client.CreateTag(t.Context(), tt.tagName, commitHash)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -291,11 +291,11 @@ func TestMemGitClient_ListTags(t *testing.T) {
 			head, _ := repo.Head()
 			headHash := head.Hash().String()
 			for _, tag := range tt.setupTags {
-				err := client.CreateTag(tag, headHash)
+				err := client.CreateTag(t.Context(), tag, headHash)
 				require.NoError(t, err)
 			}
 
-			tags, err := client.ListTags(tt.prefix)
+			tags, err := client.ListTags(t.Context(), tt.prefix)
 			require.NoError(t, err)
 			assert.Len(t, tags, tt.wantCount)
 
@@ -315,7 +315,7 @@ func TestMemGitClient_DeleteTag(t *testing.T) {
 	// Create a tag first
 	head, _ := repo.Head()
 	headHash := head.Hash().String()
-	err := client.CreateTag("test-tag", headHash)
+	err := client.CreateTag(t.Context(), "test-tag", headHash)
 	require.NoError(t, err)
 
 	// Verify tag exists
@@ -323,7 +323,7 @@ func TestMemGitClient_DeleteTag(t *testing.T) {
 	require.NoError(t, err)
 
 	// Delete the tag
-	err = client.DeleteTag("test-tag")
+	err = client.DeleteTag(t.Context(), "test-tag")
 	require.NoError(t, err)
 
 	// Verify tag is gone
@@ -363,11 +363,11 @@ func TestMemGitClient_GetTagCommit(t *testing.T) {
 			if tt.setupTag {
 				head, _ := repo.Head()
 				headHash := head.Hash().String()
-				err := client.CreateTag(tt.tagName, headHash)
+				err := client.CreateTag(t.Context(), tt.tagName, headHash)
 				require.NoError(t, err)
 			}
 
-			commitHash, err := client.GetTagCommit(tt.tagName)
+			commitHash, err := client.GetTagCommit(t.Context(), tt.tagName)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -428,18 +428,19 @@ func TestMemGitClient_Restore(t *testing.T) {
 			},
 		},
 		{
-			name: "restore file that was deleted in checkpoint",
+			name: "leave file that is not in checkpoint",
 			setupFiles: map[string]string{
 				"existing.txt": "content",
 			},
 			modifyFiles: map[string]string{
-				"deleted.txt": "will be deleted",
+				"created.txt": "created after checkpoint",
 			},
 			tagName:      "checkpoint-1",
-			restoreFiles: []string{"deleted.txt"},
+			restoreFiles: []string{"created.txt"},
 			wantErr:      false,
 			verifyFiles: map[string]string{
 				"existing.txt": "content",
+				"created.txt":  "created after checkpoint",
 			},
 		},
 	}
@@ -453,15 +454,15 @@ func TestMemGitClient_Restore(t *testing.T) {
 			for path, content := range tt.setupFiles {
 				err := afero.WriteFile(fs, path, []byte(content), 0644)
 				require.NoError(t, err)
-				err = client.Add([]string{path})
+				err = client.Add(t.Context(), []string{path})
 				require.NoError(t, err)
 			}
 
 			// Create checkpoint
-			_, err := client.Commit("Initial state")
+			_, err := client.Commit(t.Context(), "Initial state")
 			require.NoError(t, err)
 			head, _ := repo.Head()
-			err = client.CreateTag(tt.tagName, head.Hash().String())
+			err = client.CreateTag(t.Context(), tt.tagName, head.Hash().String())
 			require.NoError(t, err)
 
 			// Modify files
@@ -471,7 +472,7 @@ func TestMemGitClient_Restore(t *testing.T) {
 			}
 
 			// Restore
-			err = client.Restore(tt.tagName, tt.restoreFiles)
+			err = client.Restore(t.Context(), tt.tagName, tt.restoreFiles)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -499,20 +500,20 @@ func TestMemGitClient_SoftReset(t *testing.T) {
 	// Create two commits
 	err := afero.WriteFile(fs, "file1.txt", []byte("content1"), 0644)
 	require.NoError(t, err)
-	err = client.Add([]string{"file1.txt"})
+	err = client.Add(t.Context(), []string{"file1.txt"})
 	require.NoError(t, err)
-	commit1, err := client.Commit("First commit")
+	commit1, err := client.Commit(t.Context(), "First commit")
 	require.NoError(t, err)
 
 	err = afero.WriteFile(fs, "file2.txt", []byte("content2"), 0644)
 	require.NoError(t, err)
-	err = client.Add([]string{"file2.txt"})
+	err = client.Add(t.Context(), []string{"file2.txt"})
 	require.NoError(t, err)
-	_, err = client.Commit("Second commit")
+	_, err = client.Commit(t.Context(), "Second commit")
 	require.NoError(t, err)
 
 	// Reset to first commit
-	err = client.SoftReset(commit1)
+	err = client.SoftReset(t.Context(), commit1)
 	require.NoError(t, err)
 
 	// Verify HEAD points to first commit
@@ -560,9 +561,9 @@ func TestMemGitClient_GetCommitMessage(t *testing.T) {
 			// Create a commit with specific message
 			err := afero.WriteFile(fs, "test.txt", []byte("test"), 0644)
 			require.NoError(t, err)
-			err = client.Add([]string{"test.txt"})
+			err = client.Add(t.Context(), []string{"test.txt"})
 			require.NoError(t, err)
-			hash, err := client.Commit(tt.commitMsg)
+			hash, err := client.Commit(t.Context(), tt.commitMsg)
 			require.NoError(t, err)
 
 			// Use the actual hash if commitHash is empty
@@ -571,7 +572,7 @@ func TestMemGitClient_GetCommitMessage(t *testing.T) {
 				commitHash = hash
 			}
 
-			message, err := client.GetCommitMessage(commitHash)
+			message, err := client.GetCommitMessage(t.Context(), commitHash)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -612,11 +613,11 @@ func TestMemGitClient_IsTracked(t *testing.T) {
 			if tt.addFile {
 				err := afero.WriteFile(fs, tt.path, []byte("content"), 0644)
 				require.NoError(t, err)
-				err = client.Add([]string{tt.path})
+				err = client.Add(t.Context(), []string{tt.path})
 				require.NoError(t, err)
 			}
 
-			tracked, err := client.IsTracked(tt.path)
+			tracked, err := client.IsTracked(t.Context(), tt.path)
 			require.NoError(t, err)
 			assert.Equal(t, tt.wantBool, tracked)
 		})
@@ -634,31 +635,31 @@ func TestMemGitClient_GetCommitParent(t *testing.T) {
 	// Create first new commit
 	err := afero.WriteFile(fs, "file1.txt", []byte("content1"), 0644)
 	require.NoError(t, err)
-	err = client.Add([]string{"file1.txt"})
+	err = client.Add(t.Context(), []string{"file1.txt"})
 	require.NoError(t, err)
-	commit1, err := client.Commit("First commit")
+	commit1, err := client.Commit(t.Context(), "First commit")
 	require.NoError(t, err)
 
 	// Create second commit
 	err = afero.WriteFile(fs, "file2.txt", []byte("content2"), 0644)
 	require.NoError(t, err)
-	err = client.Add([]string{"file2.txt"})
+	err = client.Add(t.Context(), []string{"file2.txt"})
 	require.NoError(t, err)
-	commit2, err := client.Commit("Second commit")
+	commit2, err := client.Commit(t.Context(), "Second commit")
 	require.NoError(t, err)
 
 	// Test: Get parent of second commit (should be first commit)
-	parent, err := client.GetCommitParent(commit2)
+	parent, err := client.GetCommitParent(t.Context(), commit2)
 	require.NoError(t, err)
 	assert.Equal(t, commit1, parent)
 
 	// Test: Get parent of first commit (should be the initial commit from setup)
-	parent, err = client.GetCommitParent(commit1)
+	parent, err = client.GetCommitParent(t.Context(), commit1)
 	require.NoError(t, err)
 	assert.Equal(t, initialCommit, parent)
 
 	// Test: Get parent of the very first commit (should be empty)
-	parent, err = client.GetCommitParent(initialCommit)
+	parent, err = client.GetCommitParent(t.Context(), initialCommit)
 	require.NoError(t, err)
 	assert.Empty(t, parent)
 }
@@ -668,7 +669,7 @@ func TestMemGitClient_GetUnstagedFiles(t *testing.T) {
 	client := NewMemGitClient(repo, fs)
 
 	// Initially no unstaged files
-	unstaged, err := client.GetUnstagedFiles()
+	unstaged, err := client.GetUnstagedFiles(t.Context())
 	require.NoError(t, err)
 	assert.Empty(t, unstaged)
 
@@ -678,7 +679,7 @@ func TestMemGitClient_GetUnstagedFiles(t *testing.T) {
 	f.Write([]byte("untracked"))
 	f.Close()
 
-	unstaged, err = client.GetUnstagedFiles()
+	unstaged, err = client.GetUnstagedFiles(t.Context())
 	require.NoError(t, err)
 	assert.Contains(t, unstaged, "untracked.txt")
 
@@ -689,7 +690,396 @@ func TestMemGitClient_GetUnstagedFiles(t *testing.T) {
 	})
 
 	// Now no unstaged files
-	unstaged, err = client.GetUnstagedFiles()
+	unstaged, err = client.GetUnstagedFiles(t.Context())
 	require.NoError(t, err)
 	assert.Empty(t, unstaged)
 }
+
+func TestMemGitClient_GetChangedFiles(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+
+	// Mirror the committed README into the working tree and modify it
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# Changed"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "src/new.go", []byte("package src"), 0644))
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte("ignored"), 0644))
+
+	changes, err := client.GetChangedFiles(t.Context(), "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []FileChange{
+		{Path: "README.md", Change: ChangeModified},
+		{Path: "src/new.go", Change: ChangeAdded},
+	}, changes)
+
+	require.NoError(t, fs.Remove("README.md"))
+	changes, err = client.GetChangedFiles(t.Context(), "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, changes, FileChange{Path: "README.md", Change: ChangeDeleted})
+}
+
+func TestMemGitClient_StashChanges(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# Changed"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "src/new.go", []byte("package src"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "other.txt", []byte("not stashed"), 0644))
+
+	hash, err := client.StashChanges(t.Context(), "refs/mission/paused/m1", []string{"README.md", "src/new.go"}, "pause m1")
+	require.NoError(t, err)
+
+	// HEAD is unchanged and the stash commit sits on top of it
+	after, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash(), after.Hash())
+	parent, err := client.GetCommitParent(t.Context(), hash)
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash().String(), parent)
+
+	ref, err := repo.Reference(plumbing.ReferenceName("refs/mission/paused/m1"), true)
+	require.NoError(t, err)
+	assert.Equal(t, hash, ref.Hash().String())
+
+	// Stashed files are reverted, others untouched
+	content, err := afero.ReadFile(fs, "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Test Repository", string(content))
+	exists, _ := afero.Exists(fs, "src/new.go")
+	assert.False(t, exists)
+	content, err = afero.ReadFile(fs, "other.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "not stashed", string(content))
+
+	// Reapplying restores the stashed versions
+	conflicts, err := client.ApplyStash(t.Context(), "refs/mission/paused/m1")
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	content, err = afero.ReadFile(fs, "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Changed", string(content))
+	content, err = afero.ReadFile(fs, "src/new.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package src", string(content))
+
+	require.NoError(t, client.DeleteRef(t.Context(), "refs/mission/paused/m1"))
+	_, err = repo.Reference(plumbing.ReferenceName("refs/mission/paused/m1"), true)
+	assert.Error(t, err)
+}
+
+func TestMemGitClient_StashChanges_NoChanges(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# Test Repository"), 0644))
+
+	_, err := client.StashChanges(t.Context(), "refs/mission/paused/m1", []string{"README.md"}, "pause m1")
+	assert.ErrorIs(t, err, ErrNoChanges)
+	_, err = client.StashChanges(t.Context(), "refs/mission/paused/m1", nil, "pause m1")
+	assert.ErrorIs(t, err, ErrNoChanges)
+}
+
+func TestMemGitClient_ApplyStash_Conflict(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("stashed"), 0644))
+	_, err := client.StashChanges(t.Context(), "refs/mission/paused/m1", []string{"README.md"}, "pause m1")
+	require.NoError(t, err)
+
+	// The file changes again before the stash is reapplied
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("changed meanwhile"), 0644))
+
+	conflicts, err := client.ApplyStash(t.Context(), "refs/mission/paused/m1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, conflicts)
+
+	content, err := afero.ReadFile(fs, "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "<<<<<<< working tree\nchanged meanwhile\n=======\nstashed\n>>>>>>> stashed\n", string(content))
+}
+
+func TestMemGitClient_CommitsBetween(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	baseHash := head.Hash().String()
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a"), 0644))
+	require.NoError(t, client.Add(t.Context(), []string{"a.txt"}))
+	first, err := client.Commit(t.Context(), "first\n\nwith a body")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateTag(t.Context(), "first", first))
+
+	require.NoError(t, afero.WriteFile(fs, "b.txt", []byte("b"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# Changed"), 0644))
+	require.NoError(t, client.Add(t.Context(), []string{"b.txt", "README.md"}))
+	second, err := client.Commit(t.Context(), "second")
+	require.NoError(t, err)
+
+	commits, err := client.CommitsBetween(t.Context(), baseHash, "HEAD")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, second, commits[0].Hash)
+	assert.Equal(t, []string{"README.md", "b.txt"}, commits[0].Files)
+	assert.Equal(t, first, commits[1].Hash)
+	assert.Equal(t, "first", commits[1].Subject())
+	assert.Equal(t, []string{"a.txt"}, commits[1].Files)
+
+	headHash, err := client.GetTagCommit(t.Context(), "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, second, headHash)
+	at, err := client.GetCommitTime(t.Context(), second)
+	require.NoError(t, err)
+	assert.False(t, at.IsZero())
+
+	stats, err := client.DiffStat(t.Context(), baseHash, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []FileStat{
+		{Path: "README.md", Change: ChangeModified, Additions: 1, Deletions: 1},
+		{Path: "a.txt", Change: ChangeAdded, Additions: 1},
+		{Path: "b.txt", Change: ChangeAdded, Additions: 1},
+	}, stats)
+
+	commits, err = client.CommitsBetween(t.Context(), "HEAD", "first")
+	require.NoError(t, err)
+	assert.Empty(t, commits, "ancestors of HEAD are excluded")
+
+	// A tagged commit can be replayed onto the working tree like a stash
+	require.NoError(t, fs.Remove("a.txt"))
+	conflicts, err := client.ApplyStash(t.Context(), "first")
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	content, err := afero.ReadFile(fs, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+}
+
+func TestMemGitClient_FindCommitsByTrailer(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+
+	var hashes []string
+	for i, message := range []string{
+		"feat: one\n\nMission-Id: m-1",
+		"feat: mentions Mission-Id: m-1 in the subject only",
+		"feat: two\n\nMission-Id: m-2\nMission-Track: 2",
+	} {
+		require.NoError(t, afero.WriteFile(fs, "a.txt", []byte{byte('a' + i)}, 0644))
+		require.NoError(t, client.Add(t.Context(), []string{"a.txt"}))
+		hash, err := client.Commit(t.Context(), message)
+		require.NoError(t, err)
+		hashes = append(hashes, hash)
+	}
+
+	commits, err := client.FindCommitsByTrailer(t.Context(), "Mission-Id", "m-1")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, hashes[0], commits[0].Hash)
+	assert.Equal(t, []string{"a.txt"}, commits[0].Files)
+
+	commits, err = client.FindCommitsByTrailer(t.Context(), "mission-id", "")
+	require.NoError(t, err)
+	require.Len(t, commits, 2, "an empty value matches any mission")
+	assert.Equal(t, hashes[2], commits[0].Hash)
+	assert.Equal(t, "2", commits[0].Trailer("Mission-Track"))
+
+	commits, err = client.FindCommitsByTrailer(t.Context(), "Mission-Id", "m-3")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+}
+
+func TestMemGitClient_Branches(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+
+	require.NoError(t, client.CreateBranch(t.Context(), "feature", "HEAD"))
+	assert.ErrorContains(t, client.CreateBranch(t.Context(), "feature", "HEAD"), "branch feature already exists")
+	require.NoError(t, client.SwitchBranch(t.Context(), "feature"))
+	branch, err := client.CurrentBranch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "feature", branch)
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("a"), 0644))
+	require.NoError(t, client.Add(t.Context(), []string{"a.txt"}))
+	feature, err := client.Commit(t.Context(), "feat: a")
+	require.NoError(t, err)
+
+	require.NoError(t, client.SwitchBranch(t.Context(), "master"))
+	exists, err := afero.Exists(fs, "a.txt")
+	require.NoError(t, err)
+	assert.False(t, exists, "files of the other branch are removed")
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("local"), 0644))
+	assert.ErrorContains(t, client.SwitchBranch(t.Context(), "feature"), "local changes to a.txt would be overwritten")
+	require.NoError(t, fs.Remove("a.txt"))
+
+	head, err := client.MergeFastForward(t.Context(), "feature")
+	require.NoError(t, err)
+	assert.Equal(t, feature, head)
+	branch, err = client.CurrentBranch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+	content, err := afero.ReadFile(fs, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+	require.NoError(t, client.DeleteBranch(t.Context(), "feature"))
+
+	// Squash two commits of a branch onto a master that moved on
+	require.NoError(t, client.CreateBranch(t.Context(), "squash", "HEAD"))
+	require.NoError(t, client.SwitchBranch(t.Context(), "squash"))
+	for _, version := range []string{"b1", "b2"} {
+		require.NoError(t, afero.WriteFile(fs, "b.txt", []byte(version), 0644))
+		require.NoError(t, client.Add(t.Context(), []string{"b.txt"}))
+		_, err = client.Commit(t.Context(), "wip "+version)
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.SwitchBranch(t.Context(), "master"))
+	require.NoError(t, afero.WriteFile(fs, "c.txt", []byte("c"), 0644))
+	require.NoError(t, client.Add(t.Context(), []string{"c.txt"}))
+	before, err := client.Commit(t.Context(), "feat: c")
+	require.NoError(t, err)
+
+	_, err = client.MergeFastForward(t.Context(), "squash")
+	assert.ErrorContains(t, err, "cannot fast-forward master to squash")
+	squashed, err := client.MergeSquash(t.Context(), "squash", "feat: b")
+	require.NoError(t, err)
+	commits, err := client.CommitsBetween(t.Context(), before, "HEAD")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, squashed, commits[0].Hash)
+	assert.Equal(t, "feat: b", commits[0].Subject())
+	assert.Equal(t, []string{"b.txt"}, commits[0].Files)
+	content, err = afero.ReadFile(fs, "b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "b2", string(content))
+
+	_, err = client.MergeSquash(t.Context(), "squash", "feat: b again")
+	assert.ErrorIs(t, err, ErrNoChanges)
+	assert.ErrorContains(t, client.DeleteBranch(t.Context(), "squash"), "branch squash is not fully merged")
+}
+
+func TestMemGitClient_Worktrees(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+	head, err := client.GetTagCommit(t.Context(), "HEAD")
+	require.NoError(t, err)
+
+	require.NoError(t, client.AddWorktree(t.Context(), "/wt/one", "mission/one", "HEAD"))
+	require.NoError(t, client.AddWorktree(t.Context(), "/wt/two", "mission/two", "HEAD"))
+	assert.ErrorContains(t, client.AddWorktree(t.Context(), "/wt/one", "mission/three", "HEAD"), "worktree /wt/one already exists")
+	branchHead, err := client.GetTagCommit(t.Context(), "mission/one")
+	require.NoError(t, err)
+	assert.Equal(t, head, branchHead)
+
+	require.NoError(t, fs.RemoveAll("/wt/two"))
+	worktrees, err := client.ListWorktrees(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []Worktree{
+		{Path: ".", Head: head, Branch: "master"},
+		{Path: "/wt/one", Head: head, Branch: "mission/one"},
+		{Path: "/wt/two", Head: head, Branch: "mission/two", Prunable: true},
+	}, worktrees)
+
+	require.NoError(t, client.PruneWorktrees(t.Context()))
+	worktrees, err = client.ListWorktrees(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, worktrees, 2)
+
+	require.NoError(t, afero.WriteFile(fs, "/wt/one/.mission/mission.md", []byte("ignored"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/wt/one/new.txt", []byte("new"), 0644))
+	assert.ErrorContains(t, client.RemoveWorktree(t.Context(), "/wt/one", false), "contains modified or untracked files")
+	require.NoError(t, fs.Remove("/wt/one/new.txt"))
+	require.NoError(t, client.RemoveWorktree(t.Context(), "/wt/one", false))
+	exists, err := afero.DirExists(fs, "/wt/one")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.ErrorContains(t, client.RemoveWorktree(t.Context(), "/wt/one", true), "is not a worktree")
+}
+
+func TestMemGitClient_Diff(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+	base, err := client.GetTagCommit(t.Context(), "HEAD")
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# Test Repository\nmore\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "src/new.go", []byte("package src\n"), 0644))
+
+	t.Run("working tree includes untracked files", func(t *testing.T) {
+		diffs, err := client.Diff(t.Context(), base, "", nil)
+		require.NoError(t, err)
+		require.Len(t, diffs, 2)
+		assert.Equal(t, FileStat{Path: "README.md", Change: ChangeModified, Additions: 2, Deletions: 1}, diffs[0].FileStat)
+		assert.Contains(t, diffs[0].Patch, "diff --git a/README.md b/README.md")
+		assert.Contains(t, diffs[0].Patch, "+more")
+		assert.Equal(t, FileStat{Path: "src/new.go", Change: ChangeAdded, Additions: 1}, diffs[1].FileStat)
+		assert.Contains(t, diffs[1].Patch, "new file mode")
+	})
+
+	t.Run("paths limit the diff", func(t *testing.T) {
+		diffs, err := client.Diff(t.Context(), base, "", []string{"src"})
+		require.NoError(t, err)
+		require.Len(t, diffs, 1)
+		assert.Equal(t, "src/new.go", diffs[0].Path)
+	})
+
+	t.Run("between commits", func(t *testing.T) {
+		require.NoError(t, client.Add(t.Context(), []string{"README.md", "src/new.go"}))
+		_, err := client.Commit(t.Context(), "change")
+		require.NoError(t, err)
+		require.NoError(t, fs.Remove("src/new.go"))
+
+		diffs, err := client.Diff(t.Context(), base, "HEAD", nil)
+		require.NoError(t, err)
+		assert.Len(t, diffs, 2)
+
+		diffs, err = client.Diff(t.Context(), "HEAD", "", nil)
+		require.NoError(t, err)
+		require.Len(t, diffs, 1)
+		assert.Equal(t, FileStat{Path: "src/new.go", Change: ChangeDeleted, Deletions: 1}, diffs[0].FileStat)
+		assert.Contains(t, diffs[0].Patch, "deleted file mode")
+	})
+}
+
+func TestMemGitClient_SnapshotFiles(t *testing.T) {
+	fs, repo := setupTestRepo(t)
+	client := NewMemGitClient(repo, fs)
+	head, err := client.GetTagCommit(t.Context(), "HEAD")
+	require.NoError(t, err)
+
+	_, err = client.SnapshotFiles(t.Context(), "refs/mission/m/1", "HEAD", []string{"missing.txt"}, "snapshot")
+	assert.ErrorIs(t, err, ErrNoChanges)
+
+	require.NoError(t, afero.WriteFile(fs, "dir/a.txt", []byte("a"), 0644))
+	hash, err := client.SnapshotFiles(t.Context(), "refs/mission/m/1", "HEAD", []string{"dir/a.txt"}, "snapshot")
+	require.NoError(t, err)
+
+	after, err := client.GetTagCommit(t.Context(), "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, head, after, "HEAD is untouched")
+	resolved, err := client.GetTagCommit(t.Context(), "refs/mission/m/1")
+	require.NoError(t, err)
+	assert.Equal(t, hash, resolved)
+	parent, err := client.GetCommitParent(t.Context(), hash)
+	require.NoError(t, err)
+	assert.Equal(t, head, parent)
+
+	diffs, err := client.Diff(t.Context(), "HEAD", "refs/mission/m/1", nil)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, "dir/a.txt", diffs[0].Path)
+	tracked, err := client.IsTracked(t.Context(), "dir/a.txt")
+	require.NoError(t, err)
+	assert.False(t, tracked, "the index is untouched")
+
+	require.NoError(t, client.UpdateRef(t.Context(), "refs/mission/m/baseline", hash))
+	refs, err := client.ListRefs(t.Context(), "refs/mission/m")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refs/mission/m/1", "refs/mission/m/baseline"}, refs)
+	require.NoError(t, client.DeleteRef(t.Context(), "refs/mission/m/1"))
+	refs, err = client.ListRefs(t.Context(), "refs/mission/m/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refs/mission/m/baseline"}, refs)
+}
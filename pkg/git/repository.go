@@ -0,0 +1,1205 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+)
+
+// repository implements the GitClient operations both go-git clients perform alike on
+// their repository, reading and writing files through the working tree of the client
+type repository struct {
+	repo *git.Repository
+	tree workTree
+}
+
+// workTree is the working tree and index of a go-git client
+type workTree interface {
+	// readFile returns the content of a working tree file
+	readFile(path string) ([]byte, error)
+	// writeVersion writes a working tree file, or removes it when version does not exist
+	writeVersion(path string, version fileVersion) error
+	// walkWorkingTree calls fn with the slash-separated path of every working tree file
+	// git would list as tracked or untracked
+	walkWorkingTree(ctx context.Context, fn func(path string) error) error
+	// status returns the go-git status of the working tree
+	status() (git.Status, error)
+	// checkIndexLock fails with ErrIndexLocked while another process holds the index
+	checkIndexLock() error
+}
+
+func (r *repository) Restore(ctx context.Context, checkpointName string, files []string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	if err := r.tree.checkIndexLock(); err != nil {
+		return err
+	}
+	commit, err := r.resolveCommit(checkpointName)
+	if err != nil {
+		return err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		if err := contextError(ctx); err != nil {
+			return err
+		}
+		file, err := tree.File(path)
+		if err != nil {
+			// File not in checkpoint; like git checkout, leave it alone
+			continue
+		}
+
+		reader, err := file.Reader()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := r.tree.writeVersion(path, fileVersion{content: content, exists: true}); err != nil {
+			return err
+		}
+		// Like git checkout, restored files are staged
+		if _, err := wt.Add(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository) ListTags(ctx context.Context, prefix string) ([]string, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	var tags []string
+	iter, err := r.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().Short(), prefix) {
+			tags = append(tags, ref.Name().Short())
+		}
+		return nil
+	})
+	sort.Strings(tags)
+	return tags, err
+}
+
+func (r *repository) DeleteTag(ctx context.Context, name string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	return r.repo.DeleteTag(name)
+}
+
+func (r *repository) GetTagCommit(ctx context.Context, tagName string) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	return r.resolveRef(tagName)
+}
+
+// resolveRef resolves HEAD, a full ref name, a tag, a branch or any other revision to
+// the hash of the commit it names
+func (r *repository) resolveRef(tagName string) (string, error) {
+	if tagName == "HEAD" {
+		head, err := r.repo.Head()
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return "", fmt.Errorf("resolving HEAD: %w", ErrNoCommits)
+		}
+		if err != nil {
+			return "", err
+		}
+		return head.Hash().String(), nil
+	}
+
+	if strings.HasPrefix(tagName, "refs/") {
+		ref, err := r.repo.Reference(plumbing.ReferenceName(tagName), true)
+		if err != nil {
+			return "", err
+		}
+		return ref.Hash().String(), nil
+	}
+
+	tagRef, err := r.repo.Tag(tagName)
+	if err == nil {
+		if tagObj, err := r.repo.TagObject(tagRef.Hash()); err == nil {
+			return tagObj.Target.String(), nil
+		}
+		return tagRef.Hash().String(), nil
+	}
+
+	if branch, branchErr := r.repo.Reference(plumbing.NewBranchReferenceName(tagName), true); branchErr == nil {
+		return branch.Hash().String(), nil
+	}
+
+	// Fall back to interpreting tagName as a revision such as a hash or HEAD~1
+	hash, revErr := r.repo.ResolveRevision(plumbing.Revision(tagName))
+	if revErr != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func (r *repository) SoftReset(ctx context.Context, commitHash string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	if err := r.tree.checkIndexLock(); err != nil {
+		return err
+	}
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	hash := plumbing.NewHash(commitHash)
+	return wt.Reset(&git.ResetOptions{
+		Commit: hash,
+		Mode:   git.SoftReset,
+	})
+}
+
+func (r *repository) GetCommitMessage(ctx context.Context, commitHash string) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	var hash plumbing.Hash
+	if commitHash == "HEAD" {
+		ref, err := r.repo.Head()
+		if err != nil {
+			return "", err
+		}
+		hash = ref.Hash()
+	} else {
+		hash = plumbing.NewHash(commitHash)
+	}
+
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(commit.Message), nil
+}
+
+func (r *repository) GetCommitTime(ctx context.Context, commitHash string) (time.Time, error) {
+	if err := contextError(ctx); err != nil {
+		return time.Time{}, err
+	}
+	commit, err := r.resolveCommit(commitHash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.Committer.When, nil
+}
+
+func (r *repository) IsTracked(ctx context.Context, path string) (bool, error) {
+	if err := contextError(ctx); err != nil {
+		return false, err
+	}
+	// Check the index directly to see if the file is tracked
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = idx.Entry(path)
+	if err == object.ErrEntryNotFound || (err != nil && strings.Contains(err.Error(), "entry not found")) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *repository) GetCommitParent(ctx context.Context, commitHash string) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	hash := plumbing.NewHash(commitHash)
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return "", err
+	}
+	if commit.NumParents() == 0 {
+		return "", nil // No parent (initial commit)
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return "", err
+	}
+	return parent.Hash.String(), nil
+}
+
+// GetUnstagedFiles returns files with working tree changes and no staged changes,
+// including untracked files, like the CLI implementation
+func (r *repository) GetUnstagedFiles(ctx context.Context) ([]string, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	status, err := r.tree.status()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for path, s := range status {
+		if s.Worktree != git.Unmodified && (s.Staging == git.Unmodified || s.Staging == git.Untracked) {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// GetUntrackedFiles returns files that exist in the working directory but are not tracked by git.
+func (r *repository) GetUntrackedFiles(ctx context.Context) ([]string, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	status, err := r.tree.status()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for path, s := range status {
+		// Only untracked files
+		if s.Worktree == git.Untracked {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// GetChangedFiles compares the base commit tree with the files of the working tree
+func (r *repository) GetChangedFiles(ctx context.Context, base string) ([]FileChange, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	commit, err := r.resolveCommit(base)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	inTree := make(map[string]bool)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		inTree[f.Name] = true
+		current := r.readVersion(f.Name)
+		if !current.exists {
+			changes = append(changes, FileChange{Path: f.Name, Change: ChangeDeleted})
+			return nil
+		}
+		original, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		if original != string(current.content) {
+			changes = append(changes, FileChange{Path: f.Name, Change: ChangeModified})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.tree.walkWorkingTree(ctx, func(path string) error {
+		if !inTree[path] {
+			changes = append(changes, FileChange{Path: path, Change: ChangeAdded})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// resolveCommit resolves HEAD, a tag name or a commit hash to a commit object.
+func (r *repository) resolveCommit(ref string) (*object.Commit, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	hash, err := r.resolveRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	return r.repo.CommitObject(plumbing.NewHash(hash))
+}
+
+// StashChanges snapshots the files on top of HEAD, then reverts them in the index and
+// working tree, so other staged changes stay out of the stash and staged
+func (r *repository) StashChanges(ctx context.Context, ref string, files []string, message string) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", ErrNoChanges
+	}
+	if err := r.tree.checkIndexLock(); err != nil {
+		return "", err
+	}
+
+	head, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return "", err
+	}
+	commit, err := r.SnapshotFiles(ctx, ref, head.Hash.String(), files, message)
+	if err != nil {
+		return "", err
+	}
+	if err := r.resetFiles(ctx, head, files); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// resetFiles reverts files in the index and working tree to commit, deleting files
+// that do not exist in it
+func (r *repository) resetFiles(ctx context.Context, commit *object.Commit, files []string) error {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := contextError(ctx); err != nil {
+			return err
+		}
+		version, err := treeVersion(commit, file)
+		if err != nil {
+			return err
+		}
+		if err := r.tree.writeVersion(file, version); err != nil {
+			return err
+		}
+		if version.exists {
+			if _, err := wt.Add(file); err != nil {
+				return err
+			}
+			continue
+		}
+		// Like git rm --cached --ignore-unmatch, a file missing from the index is fine
+		_, _ = wt.Remove(file)
+	}
+	return nil
+}
+
+// ApplyStash reapplies a stash. Files changed on both sides are merged line by line,
+// leaving conflict markers where both changed the same lines.
+func (r *repository) ApplyStash(ctx context.Context, ref string) ([]string, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	var commit *object.Commit
+	if stashRef, err := r.repo.Reference(plumbing.ReferenceName(ref), true); err == nil {
+		if commit, err = r.repo.CommitObject(stashRef.Hash()); err != nil {
+			return nil, err
+		}
+	} else if commit, err = r.resolveCommit(ref); err != nil {
+		return nil, err
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+
+		ours := r.readVersion(path)
+		base, err := treeVersion(parent, path)
+		if err != nil {
+			return nil, err
+		}
+		theirs, err := treeVersion(commit, path)
+		if err != nil {
+			return nil, err
+		}
+
+		result, conflict, err := mergeStashedFile(ours, base, theirs, mergeLines)
+		if err != nil {
+			return nil, err
+		}
+		if conflict {
+			conflicts = append(conflicts, path)
+		}
+		if !result.equal(ours) {
+			if err := r.tree.writeVersion(path, result); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// SnapshotFiles writes the blob, tree and commit objects directly so HEAD, the index
+// and the working tree are untouched
+func (r *repository) SnapshotFiles(ctx context.Context, ref, parent string, files []string, message string) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", ErrNoChanges
+	}
+	parentCommit, err := r.resolveCommit(parent)
+	if err != nil {
+		return "", err
+	}
+	snapshot, err := r.treeFiles(parentCommit.Hash.String())
+	if err != nil {
+		return "", err
+	}
+
+	changed := false
+	for _, file := range files {
+		version := r.readVersion(file)
+		if !version.equal(snapshot[file]) {
+			changed = true
+		}
+		if version.exists {
+			snapshot[file] = version
+		} else {
+			delete(snapshot, file)
+		}
+	}
+	if !changed {
+		return "", ErrNoChanges
+	}
+
+	treeHash, err := r.writeTree(snapshot)
+	if err != nil {
+		return "", err
+	}
+	signature := object.Signature{Name: "Mission Toolkit", Email: "mission@toolkit.local", When: time.Now()}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{parentCommit.Hash},
+	}
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return "", err
+	}
+	hash, err := r.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return "", err
+	}
+	if err := r.UpdateRef(ctx, ref, hash.String()); err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// writeTree stores files as nested tree objects and returns the root tree hash
+func (r *repository) writeTree(files map[string]fileVersion) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	dirs := make(map[string]map[string]fileVersion)
+	for path, version := range files {
+		if dir, rest, nested := strings.Cut(path, "/"); nested {
+			if dirs[dir] == nil {
+				dirs[dir] = make(map[string]fileVersion)
+			}
+			dirs[dir][rest] = version
+			continue
+		}
+
+		blob := r.repo.Storer.NewEncodedObject()
+		blob.SetType(plumbing.BlobObject)
+		w, err := blob.Writer()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if _, err := w.Write(version.content); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if err := w.Close(); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		hash, err := r.repo.Storer.SetEncodedObject(blob)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: path, Mode: filemode.Regular, Hash: hash})
+	}
+	for dir, dirFiles := range dirs {
+		hash, err := r.writeTree(dirFiles)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash})
+	}
+
+	// Git orders tree entries as if directory names ended in a slash
+	sortName := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(entries, func(i, j int) bool { return sortName(entries[i]) < sortName(entries[j]) })
+
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.repo.Storer.SetEncodedObject(obj)
+}
+
+func (r *repository) UpdateRef(ctx context.Context, ref, commitHash string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	return r.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(ref), plumbing.NewHash(commitHash)))
+}
+
+func (r *repository) ListRefs(ctx context.Context, prefix string) ([]string, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	iter, err := r.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	var refs []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if name := ref.Name().String(); name == prefix || strings.HasPrefix(name, prefix+"/") {
+			refs = append(refs, name)
+		}
+		return nil
+	})
+	sort.Strings(refs)
+	return refs, err
+}
+
+func (r *repository) DeleteRef(ctx context.Context, ref string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	return r.repo.Storer.RemoveReference(plumbing.ReferenceName(ref))
+}
+
+func (r *repository) CurrentBranch(ctx context.Context) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "", nil
+	}
+	return head.Name().Short(), nil
+}
+
+// CommitsBetween walks the history of to, skipping every commit reachable from from
+func (r *repository) CommitsBetween(ctx context.Context, from, to string) ([]CommitInfo, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	fromCommit, err := r.resolveCommit(from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := r.resolveCommit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[plumbing.Hash]bool)
+	iter, err := r.repo.Log(&git.LogOptions{From: fromCommit.Hash})
+	if err != nil {
+		return nil, err
+	}
+	if err := iter.ForEach(func(commit *object.Commit) error {
+		excluded[commit.Hash] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	iter, err = r.repo.Log(&git.LogOptions{From: toCommit.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitInfo
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if excluded[commit.Hash] {
+			return nil
+		}
+		files, err := commitFiles(commit)
+		if err != nil {
+			return err
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    commit.Hash.String(),
+			Message: strings.TrimSpace(commit.Message),
+			Files:   files,
+		})
+		return nil
+	})
+	return commits, err
+}
+
+// FindCommitsByTrailer walks the history of HEAD and parses each commit message
+func (r *repository) FindCommitsByTrailer(ctx context.Context, key, value string) ([]CommitInfo, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	head, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	iter, err := r.repo.Log(&git.LogOptions{From: head.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitInfo
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if !hasTrailer(commit.Message, key, value) {
+			return nil
+		}
+		files, err := commitFiles(commit)
+		if err != nil {
+			return err
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    commit.Hash.String(),
+			Message: strings.TrimSpace(commit.Message),
+			Files:   files,
+		})
+		return nil
+	})
+	return commits, err
+}
+
+// DiffStat diffs the trees of both commits and counts patch lines per file
+func (r *repository) DiffStat(ctx context.Context, from, to string) ([]FileStat, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	changes, err := r.diffTrees(from, to)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]object.FileStat)
+	for _, stat := range patch.Stats() {
+		counts[stat.Name] = stat
+	}
+	binary := make(map[string]bool)
+	for _, filePatch := range patch.FilePatches() {
+		if filePatch.IsBinary() {
+			from, to := filePatch.Files()
+			if to != nil {
+				binary[to.Path()] = true
+			} else if from != nil {
+				binary[from.Path()] = true
+			}
+		}
+	}
+
+	stats := make([]FileStat, 0, len(changes))
+	for _, change := range changes {
+		stat := FileStat{Path: change.To.Name, Change: ChangeModified}
+		switch {
+		case change.From.Name == "":
+			stat.Change = ChangeAdded
+		case change.To.Name == "":
+			stat.Path, stat.Change = change.From.Name, ChangeDeleted
+		}
+		stat.Additions = counts[stat.Path].Addition
+		stat.Deletions = counts[stat.Path].Deletion
+		stat.Binary = binary[stat.Path]
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Path < stats[j].Path })
+	return stats, nil
+}
+
+func (r *repository) Diff(ctx context.Context, from, to string, paths []string) ([]FileDiff, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	fromFiles, err := r.treeFiles(from)
+	if err != nil {
+		return nil, err
+	}
+	var toFiles map[string]fileVersion
+	if to == "" {
+		toFiles, err = r.workingFiles(ctx)
+	} else {
+		toFiles, err = r.treeFiles(to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]bool)
+	for path, version := range fromFiles {
+		if !version.equal(toFiles[path]) {
+			changed[path] = true
+		}
+	}
+	for path := range toFiles {
+		if !fromFiles[path].exists {
+			changed[path] = true
+		}
+	}
+
+	var diffs []FileDiff
+	for path := range changed {
+		if !matchesPaths(path, paths) {
+			continue
+		}
+		d, err := newFilePatch(path, fromFiles[path], toFiles[path]).fileDiff()
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, d)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+// treeFiles returns the content of every file in the tree of a commit-ish
+func (r *repository) treeFiles(ref string) (map[string]fileVersion, error) {
+	commit, err := r.resolveCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]fileVersion)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		files[f.Name] = fileVersion{content: []byte(content), exists: true}
+		return nil
+	})
+	return files, err
+}
+
+// workingFiles returns the content of every file in the working tree: files tracked
+// in HEAD plus those walkWorkingTree lists, as GetChangedFiles sees them
+func (r *repository) workingFiles(ctx context.Context) (map[string]fileVersion, error) {
+	head, err := r.treeFiles("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]fileVersion)
+	for path := range head {
+		if version := r.readVersion(path); version.exists {
+			files[path] = version
+		}
+	}
+
+	err = r.tree.walkWorkingTree(ctx, func(path string) error {
+		content, err := r.tree.readFile(path)
+		if err != nil {
+			return err
+		}
+		files[path] = fileVersion{content: content, exists: true}
+		return nil
+	})
+	return files, err
+}
+
+// diffTrees returns the tree changes between two commit-ishes
+func (r *repository) diffTrees(from, to string) (object.Changes, error) {
+	fromCommit, err := r.resolveCommit(from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := r.resolveCommit(to)
+	if err != nil {
+		return nil, err
+	}
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return object.DiffTree(fromTree, toTree)
+}
+
+// commitFiles lists the files a commit changed relative to its first parent
+func commitFiles(commit *object.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// treeVersion returns the content of path in the tree of commit
+func treeVersion(commit *object.Commit, path string) (fileVersion, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return fileVersion{}, err
+	}
+	file, err := tree.File(path)
+	if err != nil {
+		return fileVersion{}, nil
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return fileVersion{}, err
+	}
+	return fileVersion{content: []byte(content), exists: true}, nil
+}
+
+func (r *repository) CreateBranch(ctx context.Context, name, startPoint string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	ref := plumbing.NewBranchReferenceName(name)
+	if _, err := r.repo.Reference(ref, false); err == nil {
+		return fmt.Errorf("branch %s already exists", name)
+	}
+	commit, err := r.resolveCommit(startPoint)
+	if err != nil {
+		return err
+	}
+	return r.repo.Storer.SetReference(plumbing.NewHashReference(ref, commit.Hash))
+}
+
+// SwitchBranch updates the files that differ between the two commits, points HEAD at
+// the branch and resets the index to it. Staged changes are therefore unstaged, which
+// git switch would keep.
+func (r *repository) SwitchBranch(ctx context.Context, name string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	ref, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), true)
+	if err != nil {
+		return fmt.Errorf("branch %s not found: %w", name, err)
+	}
+	if err := r.checkoutFiles(ref.Hash()); err != nil {
+		return err
+	}
+	if err := r.repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, ref.Name())); err != nil {
+		return err
+	}
+	return r.resetIndex(ref.Hash())
+}
+
+func (r *repository) DeleteBranch(ctx context.Context, name string) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	ref, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), true)
+	if err != nil {
+		return fmt.Errorf("branch %s not found: %w", name, err)
+	}
+	if current, err := r.CurrentBranch(ctx); err == nil && current == name {
+		return fmt.Errorf("cannot delete the checked out branch %s", name)
+	}
+	unmerged, err := r.CommitsBetween(ctx, "HEAD", ref.Hash().String())
+	if err != nil {
+		return err
+	}
+	if len(unmerged) > 0 {
+		return fmt.Errorf("branch %s is not fully merged", name)
+	}
+	return r.repo.Storer.RemoveReference(ref.Name())
+}
+
+func (r *repository) MergeFastForward(ctx context.Context, branch string) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "", err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	target, err := r.resolveCommit(branch)
+	if err != nil {
+		return "", err
+	}
+	behind, err := r.CommitsBetween(ctx, target.Hash.String(), "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if len(behind) > 0 {
+		return "", fmt.Errorf("cannot fast-forward %s to %s: HEAD has %d commits %s lacks", head.Name().Short(), branch, len(behind), branch)
+	}
+	if err := r.checkoutFiles(target.Hash); err != nil {
+		return "", err
+	}
+	if err := r.moveHead(target.Hash); err != nil {
+		return "", err
+	}
+	return target.Hash.String(), nil
+}
+
+// squashFiles writes the changes branch made since its merge base with HEAD to the
+// working tree and returns their paths for MergeSquash to commit. It refuses, without
+// changing anything, when a file was changed on both sides or has local changes.
+func (r *repository) squashFiles(ctx context.Context, branch string) ([]string, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+	ours, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := r.resolveCommit(branch)
+	if err != nil {
+		return nil, err
+	}
+	bases, err := ours.MergeBase(theirs)
+	if err != nil {
+		return nil, err
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%s has no history in common with HEAD", branch)
+	}
+	base := bases[0]
+	if base.Hash == theirs.Hash {
+		return nil, ErrNoChanges
+	}
+
+	changes, err := r.diffTrees(base.Hash.String(), theirs.Hash.String())
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]fileVersion)
+	var conflicts []string
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+		oursVersion, err := treeVersion(ours, path)
+		if err != nil {
+			return nil, err
+		}
+		baseVersion, err := treeVersion(base, path)
+		if err != nil {
+			return nil, err
+		}
+		theirsVersion, err := treeVersion(theirs, path)
+		if err != nil {
+			return nil, err
+		}
+		result, conflict, err := mergeStashedFile(oursVersion, baseVersion, theirsVersion, func(ours, base, theirs []byte) ([]byte, bool, error) {
+			return ours, true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if conflict || (!r.unchanged(path, oursVersion) && !result.equal(oursVersion)) {
+			conflicts = append(conflicts, path)
+			continue
+		}
+		merged[path] = result
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("squash merge of %s conflicts in %s", branch, strings.Join(conflicts, ", "))
+	}
+
+	paths := make([]string, 0, len(merged))
+	for path, version := range merged {
+		if err := r.tree.writeVersion(path, version); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// checkoutFiles updates the working tree files that differ between HEAD and target,
+// refusing when any of them has local changes
+func (r *repository) checkoutFiles(target plumbing.Hash) error {
+	head, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return err
+	}
+	if head.Hash == target {
+		return nil
+	}
+	commit, err := r.repo.CommitObject(target)
+	if err != nil {
+		return err
+	}
+	changes, err := r.diffTrees(head.Hash.String(), target.String())
+	if err != nil {
+		return err
+	}
+
+	versions := make(map[string]fileVersion)
+	var modified []string
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+		current, err := treeVersion(head, path)
+		if err != nil {
+			return err
+		}
+		if !r.unchanged(path, current) {
+			modified = append(modified, path)
+			continue
+		}
+		if versions[path], err = treeVersion(commit, path); err != nil {
+			return err
+		}
+	}
+	if len(modified) > 0 {
+		sort.Strings(modified)
+		return fmt.Errorf("local changes to %s would be overwritten", strings.Join(modified, ", "))
+	}
+	for path, version := range versions {
+		if err := r.tree.writeVersion(path, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readVersion returns the working tree version of path, which does not exist when it
+// cannot be read
+func (r *repository) readVersion(path string) fileVersion {
+	content, err := r.tree.readFile(path)
+	if err != nil {
+		return fileVersion{}
+	}
+	return fileVersion{content: content, exists: true}
+}
+
+// writeFile writes a working tree file, keeping the permissions of an existing file
+func writeFile(fs afero.Fs, path string, content []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := fs.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, path, content, perm)
+}
+
+// unchanged reports whether the working tree copy of path matches version
+func (r *repository) unchanged(path string, version fileVersion) bool {
+	return r.readVersion(path).equal(version)
+}
+
+// moveHead points the branch HEAD is on, or HEAD itself when detached, at hash and
+// resets the index to it
+func (r *repository) moveHead(hash plumbing.Hash) error {
+	name := plumbing.HEAD
+	if symbolic, err := r.repo.Storer.Reference(plumbing.HEAD); err == nil && symbolic.Type() == plumbing.SymbolicReference {
+		name = symbolic.Target()
+	}
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+		return err
+	}
+	return r.resetIndex(hash)
+}
+
+// resetIndex makes the index match the tree of commit, leaving the working tree alone.
+// HEAD must already point at commit, since a mixed reset also moves it.
+func (r *repository) resetIndex(commit plumbing.Hash) error {
+	if err := r.tree.checkIndexLock(); err != nil {
+		return err
+	}
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Reset(&git.ResetOptions{Commit: commit, Mode: git.MixedReset})
+}
@@ -0,0 +1,183 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// fileVersion is the content of a file on one side of a three-way merge
+type fileVersion struct {
+	content []byte
+	exists  bool
+}
+
+// equal reports whether two versions have the same existence and content
+func (v fileVersion) equal(other fileVersion) bool {
+	return v.exists == other.exists && bytes.Equal(v.content, other.content)
+}
+
+// mergeFunc merges ours and theirs against base, reporting whether conflicts remain
+type mergeFunc func(ours, base, theirs []byte) ([]byte, bool, error)
+
+// mergeStashedFile decides the working tree state of a file when a stash is reapplied.
+// ours is the current working tree, base the stash parent and theirs the stashed version.
+func mergeStashedFile(ours, base, theirs fileVersion, merge mergeFunc) (fileVersion, bool, error) {
+	switch {
+	case ours.equal(theirs):
+		return ours, false, nil
+	case ours.equal(base):
+		return theirs, false, nil
+	case !theirs.exists:
+		// Deleted in the stash but changed since: keep the working tree version
+		return ours, true, nil
+	case !ours.exists:
+		// Deleted since the stash was taken: bring the stashed version back
+		return theirs, true, nil
+	}
+
+	merged, conflict, err := merge(ours.content, base.content, theirs.content)
+	if err != nil {
+		return fileVersion{}, false, err
+	}
+	return fileVersion{content: merged, exists: true}, conflict, nil
+}
+
+// mergeLines merges ours and theirs line by line against base like git merge-file:
+// regions only one side changed take that change, and regions both sides changed,
+// or changed next to each other, are wrapped in conflict markers unless the changes
+// are identical
+func mergeLines(ours, base, theirs []byte) ([]byte, bool, error) {
+	baseLines := splitLines(string(base))
+	oursHunks := lineHunks(baseLines, string(ours))
+	theirsHunks := lineHunks(baseLines, string(theirs))
+
+	var buf bytes.Buffer
+	conflict := false
+	pos := 0
+	for len(oursHunks) > 0 || len(theirsHunks) > 0 {
+		start := len(baseLines)
+		if len(oursHunks) > 0 {
+			start = oursHunks[0].start
+		}
+		if len(theirsHunks) > 0 && theirsHunks[0].start < start {
+			start = theirsHunks[0].start
+		}
+
+		// Collect the hunks of both sides that overlap or touch the region
+		end := start
+		var oursRegion, theirsRegion []lineHunk
+		for {
+			if len(oursHunks) > 0 && oursHunks[0].start <= end {
+				oursRegion = append(oursRegion, oursHunks[0])
+				end = max(end, oursHunks[0].end)
+				oursHunks = oursHunks[1:]
+				continue
+			}
+			if len(theirsHunks) > 0 && theirsHunks[0].start <= end {
+				theirsRegion = append(theirsRegion, theirsHunks[0])
+				end = max(end, theirsHunks[0].end)
+				theirsHunks = theirsHunks[1:]
+				continue
+			}
+			break
+		}
+
+		buf.WriteString(strings.Join(baseLines[pos:start], ""))
+		oursText := applyHunks(baseLines, start, end, oursRegion)
+		theirsText := applyHunks(baseLines, start, end, theirsRegion)
+		switch {
+		case len(theirsRegion) == 0 || oursText == theirsText:
+			buf.WriteString(oursText)
+		case len(oursRegion) == 0:
+			buf.WriteString(theirsText)
+		default:
+			buf.Write(conflictMarkers([]byte(oursText), []byte(theirsText)))
+			conflict = true
+		}
+		pos = end
+	}
+	buf.WriteString(strings.Join(baseLines[pos:], ""))
+	return buf.Bytes(), conflict, nil
+}
+
+// lineHunk replaces the base lines [start, end) with lines
+type lineHunk struct {
+	start, end int
+	lines      []string
+}
+
+// lineHunks returns the changes that turn the base lines into other
+func lineHunks(base []string, other string) []lineHunk {
+	var hunks []lineHunk
+	var current *lineHunk
+	pos := 0
+	for _, d := range diff.Do(strings.Join(base, ""), other) {
+		lines := splitLines(d.Text)
+		if d.Type == diffmatchpatch.DiffEqual {
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+			pos += len(lines)
+			continue
+		}
+		if current == nil {
+			current = &lineHunk{start: pos, end: pos}
+		}
+		if d.Type == diffmatchpatch.DiffDelete {
+			current.end += len(lines)
+			pos += len(lines)
+		} else {
+			current.lines = append(current.lines, lines...)
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+	return hunks
+}
+
+// applyHunks returns the base lines [start, end) with hunks applied
+func applyHunks(base []string, start, end int, hunks []lineHunk) string {
+	var b strings.Builder
+	pos := start
+	for _, h := range hunks {
+		b.WriteString(strings.Join(base[pos:h.start], ""))
+		b.WriteString(strings.Join(h.lines, ""))
+		pos = h.end
+	}
+	b.WriteString(strings.Join(base[pos:end], ""))
+	return b.String()
+}
+
+// splitLines splits text after each newline, keeping a last line without one
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// conflictMarkers wraps both versions of a file in conflict markers
+func conflictMarkers(ours, theirs []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "<<<<<<< working tree")
+	buf.Write(withTrailingNewline(ours))
+	fmt.Fprintln(&buf, "=======")
+	buf.Write(withTrailingNewline(theirs))
+	fmt.Fprintln(&buf, ">>>>>>> stashed")
+	return buf.Bytes()
+}
+
+// withTrailingNewline returns content ending in a newline
+func withTrailingNewline(content []byte) []byte {
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		return append(bytes.Clone(content), '\n')
+	}
+	return content
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name         string
+		ours         string
+		base         string
+		theirs       string
+		want         string
+		wantConflict bool
+	}{
+		{"separate changes", "a\nB\nc\nd\ne\n", "a\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n", "a\nB\nc\nd\nE\n", false},
+		{"one side", "a\nb\nc\n", "a\nb\nc\n", "a\nb\nc\nd\n", "a\nb\nc\nd\n", false},
+		{"same change", "a\nB\nc\n", "a\nb\nc\n", "a\nB\nc\n", "a\nB\nc\n", false},
+		{"deleted line", "a\nc\nd\ne\n", "a\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n", "a\nc\nd\nE\n", false},
+		{"adjacent changes", "a\nB\nc\n", "a\nb\nc\n", "a\nb\nC\n", "a\n<<<<<<< working tree\nB\nc\n=======\nb\nC\n>>>>>>> stashed\n", true},
+		{"same line", "a\nours\nc\n", "a\nb\nc\n", "a\ntheirs\nc\n", "a\n<<<<<<< working tree\nours\n=======\ntheirs\n>>>>>>> stashed\nc\n", true},
+		{"no trailing newline", "ours", "base", "theirs", "<<<<<<< working tree\nours\n=======\ntheirs\n>>>>>>> stashed\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflict, err := mergeLines([]byte(tt.ours), []byte(tt.base), []byte(tt.theirs))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(merged))
+			assert.Equal(t, tt.wantConflict, conflict)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Trailer is a "Key: value" line in the last paragraph of a commit message, such as
+// Signed-off-by or Mission-Id
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// String renders the trailer as a commit message line
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// trailerPattern matches a trailer line and captures its key and value
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s+(.*\S)\s*$`)
+
+// ParseTrailers returns the trailers of message. Like git interpret-trailers, only
+// the last paragraph counts, and only if it is not the subject and every line in it
+// is a trailer.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var trailers []Trailer
+	for _, line := range strings.Split(strings.TrimSpace(paragraphs[len(paragraphs)-1]), "\n") {
+		match := trailerPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: match[1], Value: match[2]})
+	}
+	return trailers
+}
+
+// AppendTrailers adds trailers to the trailer block of message, starting one if the
+// message has none. Trailers the message already has with the same value are skipped.
+func AppendTrailers(message string, trailers ...Trailer) string {
+	message = strings.TrimSpace(message)
+	existing := ParseTrailers(message)
+
+	var lines []string
+	for _, trailer := range trailers {
+		if trailerValue(existing, trailer.Key) == trailer.Value {
+			continue
+		}
+		lines = append(lines, trailer.String())
+	}
+	if len(lines) == 0 {
+		return message
+	}
+
+	separator := "\n\n"
+	if len(existing) > 0 {
+		separator = "\n"
+	}
+	return message + separator + strings.Join(lines, "\n")
+}
+
+// Trailer returns the value of the first trailer of the commit message with the
+// given key, compared case-insensitively, or "" if there is none
+func (c CommitInfo) Trailer(key string) string {
+	return trailerValue(ParseTrailers(c.Message), key)
+}
+
+// hasTrailer reports whether message has a trailer with the given key and, unless
+// value is empty, the given value
+func hasTrailer(message, key, value string) bool {
+	for _, trailer := range ParseTrailers(message) {
+		if strings.EqualFold(trailer.Key, key) && (value == "" || trailer.Value == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// trailerValue returns the value of the first trailer with the given key
+func trailerValue(trailers []Trailer, key string) string {
+	for _, trailer := range trailers {
+		if strings.EqualFold(trailer.Key, key) {
+			return trailer.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Trailer
+	}{
+		{"trailer block", "feat: x\n\nBody\n\nMission-Id: m-1\nSigned-off-by: A <a@b.c>", []Trailer{{"Mission-Id", "m-1"}, {"Signed-off-by", "A <a@b.c>"}}},
+		{"subject only", "Mission-Id: m-1", nil},
+		{"prose in last paragraph", "feat: x\n\nSee: the docs\nfor details", nil},
+		{"no trailers", "feat: x\n\nBody", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseTrailers(tt.message))
+		})
+	}
+}
+
+func TestAppendTrailers(t *testing.T) {
+	id := Trailer{Key: "Mission-Id", Value: "m-1"}
+	track := Trailer{Key: "Mission-Track", Value: "2"}
+
+	assert.Equal(t, "feat: x\n\nMission-Id: m-1\nMission-Track: 2", AppendTrailers("feat: x\n", id, track))
+	assert.Equal(t, "feat: x\n\nSigned-off-by: A\nMission-Id: m-1", AppendTrailers("feat: x\n\nSigned-off-by: A", id))
+	assert.Equal(t, "feat: x\n\nMission-Id: m-1\nMission-Track: 2", AppendTrailers("feat: x\n\nMission-Id: m-1", id, track), "present trailers are not repeated")
+
+	commit := CommitInfo{Message: AppendTrailers("feat: x", id)}
+	assert.Equal(t, "m-1", commit.Trailer("mission-id"))
+	assert.Empty(t, commit.Trailer("Mission-Type"))
+}
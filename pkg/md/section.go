@@ -11,6 +11,9 @@ import (
 var (
 	// regexCache caches compiled regex patterns for section extraction
 	regexCache sync.Map
+
+	// checkboxPattern matches checkbox list items, including plan step markers
+	checkboxPattern = regexp.MustCompile(`^- \[[ xX~!-]\] `)
 )
 
 // findSection locates a section header in markdown body and returns its line index.
@@ -61,7 +64,7 @@ func extractSection(body, sectionName string) string {
 //   - Dash lists: "- item"
 //   - Asterisk lists: "* item"
 //   - Numbered lists: "1. item"
-//   - Checkboxes: "- [ ] item" or "- [x] item"
+//   - Checkboxes: "- [ ] item", "- [x] item" or any plan step marker ("- [~] item")
 //
 // Returns empty slice if section not found or contains no list items.
 // Empty lines within lists are ignored.
@@ -82,9 +85,7 @@ func extractList(body, sectionName string) []string {
 		}
 
 		// Check checkbox patterns first (more specific than plain dash)
-		if strings.HasPrefix(trimmed, "- [ ] ") {
-			items = append(items, strings.TrimSpace(trimmed[6:]))
-		} else if strings.HasPrefix(trimmed, "- [x] ") {
+		if checkboxPattern.MatchString(trimmed) {
 			items = append(items, strings.TrimSpace(trimmed[6:]))
 		} else if strings.HasPrefix(trimmed, "- ") {
 			items = append(items, strings.TrimSpace(trimmed[2:]))
@@ -1,8 +1,11 @@
 package mission
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/dnatag/mission-toolkit/pkg/git"
 	"github.com/dnatag/mission-toolkit/pkg/utils"
@@ -28,10 +31,13 @@ func NewArchiver(fs afero.Fs, path string, git git.GitClient) *Archiver {
 	}
 }
 
-// Archive copies mission artifacts to the completed directory.
-// If force is true and no mission exists, this is a no-op.
+// Archive copies mission artifacts and the mission commit message to the completed
+// directory. The mission commit is the one with the mission's Mission-Id trailer, which
+// must be HEAD.
+// If force is true and no mission exists, this is a no-op; a mission without a commit
+// is archived without one, and one whose commit is not HEAD with that commit.
 // If force is false and no mission exists, returns an error.
-func (a *Archiver) Archive(force bool) error {
+func (a *Archiver) Archive(ctx context.Context, force bool) error {
 	missionPath := a.MissionPath()
 
 	// Check if mission file exists
@@ -51,19 +57,26 @@ func (a *Archiver) Archive(force bool) error {
 	}
 
 	// Mission exists, proceed with archiving
-	completedDir := filepath.Join(a.MissionDir(), "completed")
+	completedDir := filepath.Join(a.RootDir(), "completed")
 	if err := a.FS().MkdirAll(completedDir, 0755); err != nil {
 		return fmt.Errorf("creating completed directory: %w", err)
 	}
 
-	missionID, err := a.reader.GetMissionID()
+	m, err := a.reader.Read()
 	if err != nil {
 		return fmt.Errorf("getting mission ID: %w", err)
 	}
+	missionID := m.ID
+
+	// Find the commit before copying anything, so a refused archive leaves no trace
+	commit, err := a.missionCommit(ctx, missionID, force)
+	if err != nil {
+		return err
+	}
 
 	// Archive mission artifacts
 	for _, filename := range []string{"mission.md", "execution.log", "diagnosis.md"} {
-		src := filepath.Join(a.MissionDir(), filename)
+		src := filepath.Join(a.artifactDir(filename), filename)
 		if exists, _ := afero.Exists(a.FS(), src); !exists {
 			continue
 		}
@@ -75,19 +88,59 @@ func (a *Archiver) Archive(force bool) error {
 	}
 
 	// Archive commit message
-	commitMsg, err := a.git.GetCommitMessage("HEAD")
-	if err != nil {
-		return fmt.Errorf("archiving commit message: %w", err)
+	entry := NewArchiveEntry(m, fmt.Sprintf("%s-mission.md", missionID))
+	entry.ArchivedAt = time.Now().UTC()
+	if commit != nil {
+		dst := filepath.Join(completedDir, fmt.Sprintf("%s-commit.msg", missionID))
+		if err := afero.WriteFile(a.FS(), dst, []byte(commit.Message), 0644); err != nil {
+			return fmt.Errorf("writing commit message: %w", err)
+		}
+		entry.CommitHash = commit.Hash
 	}
 
-	dst := filepath.Join(completedDir, fmt.Sprintf("%s-commit.msg", missionID))
-	if err := afero.WriteFile(a.FS(), dst, []byte(commitMsg), 0644); err != nil {
-		return fmt.Errorf("writing commit message: %w", err)
+	// Record the mission in the archive index
+	if err := NewArchiveIndex(a.FS(), completedDir).Upsert(entry); err != nil {
+		return fmt.Errorf("updating archive index: %w", err)
 	}
 
 	return nil
 }
 
+// missionCommit returns the newest commit with the Mission-Id trailer of the mission,
+// refusing to go on when there is none or it is not HEAD unless force is set. With
+// force, a mission without a commit yields nil; git errors are returned either way.
+func (a *Archiver) missionCommit(ctx context.Context, missionID string, force bool) (*git.CommitInfo, error) {
+	if a.git == nil {
+		if force {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mission %s has no commit outside a git repository; use --force to archive it without one", missionID)
+	}
+	// A repository without commits has no mission commit either
+	commits, err := a.git.FindCommitsByTrailer(ctx, TrailerMissionID, missionID)
+	if err != nil && !errors.Is(err, git.ErrNoCommits) {
+		return nil, fmt.Errorf("finding the commit of mission %s: %w", missionID, err)
+	}
+	if len(commits) == 0 {
+		if force {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("no commit has the trailer %s: %s; create it with `m checkpoint commit` or use --force to archive the mission without a commit",
+			TrailerMissionID, missionID)
+	}
+
+	commit := commits[0]
+	head, err := a.git.GetTagCommit(ctx, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %w", err)
+	}
+	if head != commit.Hash && !force {
+		return nil, fmt.Errorf("HEAD is not the commit of mission %s (%s %q); check out the branch it was committed on or use --force to archive it with that commit",
+			missionID, commit.Hash[:min(len(commit.Hash), 8)], commit.Subject())
+	}
+	return &commit, nil
+}
+
 // CleanupObsoleteFiles removes obsolete mission files after successful archive.
 // This function safely removes temporary mission files that are no longer needed
 // after the mission has been archived to the completed directory.
@@ -96,7 +149,7 @@ func (a *Archiver) CleanupObsoleteFiles() error {
 	filesToClean := []string{"execution.log", "mission.md", "id", "plan.json", "diagnosis.md"}
 
 	for _, filename := range filesToClean {
-		filePath := filepath.Join(a.MissionDir(), filename)
+		filePath := filepath.Join(a.artifactDir(filename), filename)
 
 		// Check if file exists before attempting removal
 		exists, err := afero.Exists(a.FS(), filePath)
@@ -111,5 +164,24 @@ func (a *Archiver) CleanupObsoleteFiles() error {
 		}
 	}
 
+	// Named missions also drop their directory and the active pointer
+	if a.MissionDir() != a.RootDir() {
+		if err := a.FS().RemoveAll(a.MissionDir()); err != nil {
+			return fmt.Errorf("removing mission directory: %w", err)
+		}
+		if err := NewWorkspace(a.FS(), a.RootDir()).Release(filepath.Base(a.MissionDir())); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// artifactDir returns the directory holding the given artifact. diagnosis.md is
+// created before a mission exists and therefore always lives in the root directory.
+func (a *Archiver) artifactDir(filename string) string {
+	if filename == "diagnosis.md" {
+		return a.RootDir()
+	}
+	return a.MissionDir()
+}
@@ -1,10 +1,13 @@
 package mission
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 
+	"github.com/dnatag/mission-toolkit/pkg/git"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,12 +32,12 @@ Body
 
 	// Mock GitClient
 	mockGit := &MockGitClient{
-		commitMessage: "feat: test commit",
+		commitMessage: "feat: test commit\n\nMission-Id: " + missionID,
 	}
 
 	// Archive
 	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
-	err = archiver.Archive(false)
+	err = archiver.Archive(t.Context(), false)
 	require.NoError(t, err)
 
 	// Verify files were copied
@@ -55,7 +58,7 @@ Body
 	require.True(t, exists, "commit.msg should be archived")
 	content, err := afero.ReadFile(fs, archivedCommitPath)
 	require.NoError(t, err)
-	require.Equal(t, "feat: test commit", string(content))
+	require.Equal(t, "feat: test commit\n\nMission-Id: "+missionID, string(content))
 }
 
 func TestArchiver_Archive_MissingOptionalFiles(t *testing.T) {
@@ -77,12 +80,12 @@ Body
 
 	// Mock GitClient
 	mockGit := &MockGitClient{
-		commitMessage: "feat: test commit",
+		commitMessage: "feat: test commit\n\nMission-Id: " + missionID,
 	}
 
 	// Archive
 	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
-	err = archiver.Archive(false)
+	err = archiver.Archive(t.Context(), false)
 	require.NoError(t, err)
 
 	// Verify essential file was copied
@@ -158,7 +161,7 @@ func TestArchiver_Archive_ForceWithNoMission(t *testing.T) {
 
 	// Archive with force=true should succeed as no-op when no mission exists
 	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
-	err = archiver.Archive(true)
+	err = archiver.Archive(t.Context(), true)
 	require.NoError(t, err, "Archive with force=true should succeed when no mission exists")
 }
 
@@ -175,7 +178,7 @@ func TestArchiver_Archive_NoForceWithNoMission(t *testing.T) {
 
 	// Archive with force=false should return error when no mission exists
 	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
-	err = archiver.Archive(false)
+	err = archiver.Archive(t.Context(), false)
 	require.Error(t, err, "Archive with force=false should fail when no mission exists")
 	require.Contains(t, err.Error(), "no current mission to archive")
 }
@@ -195,7 +198,7 @@ func TestArchiver_Archive_CorruptedMissionFile(t *testing.T) {
 	mockGit := &MockGitClient{}
 	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
 
-	err = archiver.Archive(false)
+	err = archiver.Archive(t.Context(), false)
 	require.Error(t, err, "Should fail with corrupted mission file")
 }
 
@@ -220,10 +223,10 @@ Body`
 	err = fs.MkdirAll(completedDir, 0444)
 	require.NoError(t, err)
 
-	mockGit := &MockGitClient{}
+	mockGit := &MockGitClient{commitMessage: "feat: test\n\nMission-Id: " + missionID}
 	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
 
-	err = archiver.Archive(false)
+	err = archiver.Archive(t.Context(), false)
 	// Should handle permission errors gracefully
 	if err != nil {
 		require.Contains(t, err.Error(), "permission denied")
@@ -254,9 +257,9 @@ Test symptom`
 	err = afero.WriteFile(fs, filepath.Join(missionDir, "diagnosis.md"), []byte(diagnosisContent), 0644)
 	require.NoError(t, err)
 
-	mockGit := &MockGitClient{commitMessage: "fix: test"}
+	mockGit := &MockGitClient{commitMessage: "fix: test\n\nMission-Id: " + missionID}
 	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
-	err = archiver.Archive(false)
+	err = archiver.Archive(t.Context(), false)
 	require.NoError(t, err)
 
 	// Verify diagnosis.md was archived
@@ -269,3 +272,94 @@ Test symptom`
 	require.NoError(t, err)
 	require.Contains(t, string(content), "Test symptom")
 }
+
+func TestArchiver_Archive_UpdatesIndex(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionDir := ".mission"
+
+	missionContent := `---
+id: idx-1
+type: WET
+track: 2
+status: completed
+domains:
+    - api
+---
+
+## INTENT
+Add the archive index
+Second line
+
+## SCOPE
+pkg/mission/index.go
+`
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(missionDir, "mission.md"), []byte(missionContent), 0644))
+
+	mockGit := &MockGitClient{commitMessage: "feat: index\n\nMission-Id: idx-1", tags: map[string]string{"HEAD": "abc123"}}
+	archiver := NewArchiver(fs, filepath.Join(missionDir, "mission.md"), mockGit)
+	require.NoError(t, archiver.Archive(t.Context(), false))
+
+	entries, err := NewArchiveIndex(fs, filepath.Join(missionDir, "completed")).Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "idx-1", entries[0].ID)
+	require.Equal(t, "Add the archive index", entries[0].Intent)
+	require.Equal(t, "abc123", entries[0].CommitHash)
+	require.Equal(t, []string{"pkg/mission/index.go"}, entries[0].Files)
+	require.Equal(t, []string{"api"}, entries[0].Domains)
+	require.False(t, entries[0].ArchivedAt.IsZero())
+}
+
+func TestArchiver_Archive_FindsCommitByTrailer(t *testing.T) {
+	writeMission := func(t *testing.T) afero.Fs {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte("---\nid: trailer-1\n---\nBody"), 0644))
+		return fs
+	}
+	missionCommit := git.CommitInfo{Hash: "def456", Message: "feat: trailer\n\nMission-Id: trailer-1"}
+
+	t.Run("no commit", func(t *testing.T) {
+		fs := writeMission(t)
+		mockGit := &MockGitClient{commitMessage: "feat: unrelated", tags: map[string]string{"HEAD": "abc123"}}
+
+		err := NewArchiver(fs, ".mission/mission.md", mockGit).Archive(t.Context(), false)
+		require.ErrorContains(t, err, "no commit has the trailer Mission-Id: trailer-1")
+		exists, _ := afero.Exists(fs, ".mission/completed/trailer-1-mission.md")
+		assert.False(t, exists, "nothing is archived")
+
+		require.NoError(t, NewArchiver(fs, ".mission/mission.md", mockGit).Archive(t.Context(), true))
+		exists, _ = afero.Exists(fs, ".mission/completed/trailer-1-commit.msg")
+		assert.False(t, exists, "--force archives without a commit")
+	})
+
+	t.Run("git errors", func(t *testing.T) {
+		fs := writeMission(t)
+		mockGit := &MockGitClient{headErr: git.ErrTimeout}
+
+		err := NewArchiver(fs, ".mission/mission.md", mockGit).Archive(t.Context(), true)
+		require.ErrorIs(t, err, git.ErrTimeout, "--force does not hide git failures")
+		exists, _ := afero.Exists(fs, ".mission/completed/trailer-1-mission.md")
+		assert.False(t, exists, "nothing is archived")
+
+		mockGit.headErr = fmt.Errorf("resolving HEAD: %w", git.ErrNoCommits)
+		require.NoError(t, NewArchiver(fs, ".mission/mission.md", mockGit).Archive(t.Context(), true))
+		exists, _ = afero.Exists(fs, ".mission/completed/trailer-1-commit.msg")
+		assert.False(t, exists, "a repository without commits has no mission commit")
+	})
+
+	t.Run("HEAD moved on", func(t *testing.T) {
+		fs := writeMission(t)
+		mockGit := &MockGitClient{tags: map[string]string{"HEAD": "abc123"}, trailerCommits: []git.CommitInfo{missionCommit}}
+
+		err := NewArchiver(fs, ".mission/mission.md", mockGit).Archive(t.Context(), false)
+		require.ErrorContains(t, err, `HEAD is not the commit of mission trailer-1 (def456 "feat: trailer")`)
+
+		require.NoError(t, NewArchiver(fs, ".mission/mission.md", mockGit).Archive(t.Context(), true))
+		content, err := afero.ReadFile(fs, ".mission/completed/trailer-1-commit.msg")
+		require.NoError(t, err)
+		assert.Equal(t, missionCommit.Message, string(content))
+		entries, err := NewArchiveIndex(fs, ".mission/completed").Load()
+		require.NoError(t, err)
+		assert.Equal(t, "def456", entries[0].CommitHash)
+	})
+}
@@ -45,6 +45,17 @@ func (b *BaseService) MissionDir() string {
 	return b.missionDir
 }
 
+// RootDir returns the top-level mission directory holding shared state such as
+// completed/, paused/ and templates/. For named missions stored under
+// <root>/missions/<id>/ this is two levels above the mission directory.
+func (b *BaseService) RootDir() string {
+	parent := filepath.Dir(b.missionDir)
+	if filepath.Base(parent) == missionsDirName {
+		return filepath.Dir(parent)
+	}
+	return b.missionDir
+}
+
 // MissionPath returns the full path to the mission file.
 func (b *BaseService) MissionPath() string {
 	return b.missionPath
@@ -0,0 +1,223 @@
+package mission
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/dnatag/mission-toolkit/pkg/utils"
+	"github.com/spf13/afero"
+)
+
+// MissionBranchPrefix namespaces the branches created by m mission start
+const MissionBranchPrefix = "mission/"
+
+// MissionBranch returns the branch a mission runs on in branch-per-mission mode
+func MissionBranch(missionID string) string {
+	return MissionBranchPrefix + missionID
+}
+
+// Starter moves a mission onto its own branch, so its checkpoints and commit stay off
+// the branch it was started from until they are merged back
+type Starter struct {
+	*BaseService
+	reader *Reader
+	git    git.GitClient
+}
+
+// StartOptions controls where a mission branch is checked out
+type StartOptions struct {
+	// Worktree checks the mission branch out in a new git worktree at WorktreeDir/<id>
+	// instead of switching the current checkout, and moves the mission there
+	Worktree    bool
+	WorktreeDir string
+}
+
+// StartResult describes the branch a mission was started on
+type StartResult struct {
+	MissionID  string
+	Branch     string
+	BaseBranch string
+	// Worktree is the directory of the worktree the mission moved to, if any
+	Worktree string
+	// AlreadyStarted reports that the mission was on its branch before Start was called
+	AlreadyStarted bool
+}
+
+// NewStarter creates a new Starter for the specified mission file path
+func NewStarter(fs afero.Fs, path string, git git.GitClient) *Starter {
+	missionDir := filepath.Dir(path)
+	return &Starter{
+		BaseService: NewBaseServiceWithPath(fs, missionDir, path),
+		reader:      NewReader(fs, path),
+		git:         git,
+	}
+}
+
+// Start creates mission/<id> from HEAD, switches to it and records the branch and the
+// branch it was started from in the mission frontmatter. Local changes are carried over.
+// Starting a mission that is already on its branch does nothing.
+func (s *Starter) Start(ctx context.Context) (*StartResult, error) {
+	return s.StartWithOptions(ctx, StartOptions{})
+}
+
+// StartWithOptions is Start that can check the mission branch out in its own worktree.
+// The current checkout then stays on its branch with its local changes, and the mission
+// files move to the .mission directory of the worktree.
+func (s *Starter) StartWithOptions(ctx context.Context, opts StartOptions) (*StartResult, error) {
+	if s.git == nil {
+		return nil, fmt.Errorf("starting a mission branch needs a git repository")
+	}
+	m, err := s.reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+	if m.ID == "" {
+		return nil, fmt.Errorf("mission has no id")
+	}
+	current, err := s.git.CurrentBranch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("checking branch: %w", err)
+	}
+
+	if m.Branch != "" {
+		if current != m.Branch {
+			return nil, fmt.Errorf("mission %s was started on %s but %s is checked out; switch back with `git switch %s`",
+				m.ID, m.Branch, branchDescription(current), m.Branch)
+		}
+		return &StartResult{MissionID: m.ID, Branch: m.Branch, BaseBranch: m.BaseBranch, Worktree: m.Worktree, AlreadyStarted: true}, nil
+	}
+	if current == "" {
+		return nil, fmt.Errorf("cannot start a mission branch: %w; switch to a branch first", git.ErrDetachedHead)
+	}
+	if strings.HasPrefix(current, MissionBranchPrefix) {
+		return nil, fmt.Errorf("%s is already a mission branch; switch to the branch the mission should be merged into", current)
+	}
+
+	// Checkpoints record the branch of their baseline, so they cannot move to a new one
+	for _, baseline := range []string{m.ID + "-baseline", "refs/mission/" + m.ID + "/baseline"} {
+		if _, err := s.git.GetTagCommit(ctx, baseline); err == nil {
+			return nil, fmt.Errorf("mission %s already has checkpoints on %s; start the mission branch before the first checkpoint or run `m checkpoint clear` first", m.ID, current)
+		}
+	}
+
+	branch := MissionBranch(m.ID)
+	if opts.Worktree {
+		return s.startWorktree(ctx, m, branch, current, filepath.Join(opts.WorktreeDir, m.ID))
+	}
+	if err := s.git.CreateBranch(ctx, branch, "HEAD"); err != nil {
+		return nil, fmt.Errorf("creating branch %s: %w", branch, err)
+	}
+	if err := s.git.SwitchBranch(ctx, branch); err != nil {
+		_ = s.git.DeleteBranch(ctx, branch)
+		return nil, fmt.Errorf("switching to %s: %w", branch, err)
+	}
+
+	m.Branch = branch
+	m.BaseBranch = current
+	if err := NewWriter(s.FS(), s.MissionPath()).Write(m); err != nil {
+		return nil, fmt.Errorf("recording mission branch: %w", err)
+	}
+	return &StartResult{MissionID: m.ID, Branch: branch, BaseBranch: current}, nil
+}
+
+// startWorktree checks branch out in a new worktree at path and moves the mission into it
+func (s *Starter) startWorktree(ctx context.Context, m *Mission, branch, base, path string) (*StartResult, error) {
+	if exists, _ := afero.Exists(s.FS(), path); exists {
+		return nil, fmt.Errorf("worktree directory %s already exists", path)
+	}
+	if err := s.git.AddWorktree(ctx, path, branch, "HEAD"); err != nil {
+		return nil, fmt.Errorf("creating worktree %s: %w", path, err)
+	}
+
+	m.Branch, m.BaseBranch, m.Worktree = branch, base, path
+	if err := s.moveToWorktree(m, path); err != nil {
+		_ = s.git.RemoveWorktree(ctx, path, true)
+		_ = s.git.DeleteBranch(ctx, branch)
+		return nil, fmt.Errorf("moving mission to worktree: %w", err)
+	}
+	return &StartResult{MissionID: m.ID, Branch: branch, BaseBranch: base, Worktree: path}, nil
+}
+
+// worktreeLocal lists the entries of the mission directory that belong to one checkout.
+// Everything else, such as completed/, backlog.md and governance.md, is shared with a
+// worktree through symlinks, so archiving there records the mission here.
+var worktreeLocal = map[string]bool{
+	activePointerFile:  true,
+	missionsDirName:    true,
+	"paused":           true,
+	"checkpoints.json": true,
+	"snapshots":        true,
+	"diagnosis.md":     true,
+}
+
+// moveToWorktree writes the mission to the .mission directory of the worktree at path,
+// moves its other artifacts there and links the shared entries of the mission directory.
+// Links are skipped on filesystems without symlinks.
+func (s *Starter) moveToWorktree(m *Mission, path string) error {
+	dst := filepath.Join(path, DefaultDir)
+	if err := s.FS().MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	if err := NewWriter(s.FS(), filepath.Join(dst, "mission.md")).Write(m); err != nil {
+		return err
+	}
+	for _, name := range missionArtifacts {
+		src := filepath.Join(s.MissionDir(), name)
+		if exists, _ := afero.Exists(s.FS(), src); !exists || name == "mission.md" {
+			continue
+		}
+		if err := utils.CopyFile(s.FS(), src, filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+
+	if linker, ok := s.FS().(afero.Linker); ok {
+		// completed/ must be a link before the first mission is archived in the worktree
+		if err := s.FS().MkdirAll(filepath.Join(s.RootDir(), "completed"), 0755); err != nil {
+			return err
+		}
+		entries, err := afero.ReadDir(s.FS(), s.RootDir())
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if worktreeLocal[entry.Name()] || slices.Contains(missionArtifacts, entry.Name()) {
+				continue
+			}
+			target, err := filepath.Abs(filepath.Join(s.RootDir(), entry.Name()))
+			if err != nil {
+				return err
+			}
+			if err := linker.SymlinkIfPossible(target, filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The mission now lives in the worktree only
+	if s.MissionDir() != s.RootDir() {
+		if err := s.FS().RemoveAll(s.MissionDir()); err != nil {
+			return err
+		}
+		return NewWorkspace(s.FS(), s.RootDir()).Release(m.ID)
+	}
+	for _, name := range missionArtifacts {
+		if err := s.FS().Remove(filepath.Join(s.MissionDir(), name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// branchDescription names a branch for messages, describing a detached HEAD as such
+func branchDescription(branch string) string {
+	if branch == "" {
+		return "a detached HEAD"
+	}
+	return branch
+}
@@ -0,0 +1,94 @@
+package mission
+
+import (
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const startMission = `---
+id: start-1
+type: WET
+track: 2
+iteration: 1
+status: active
+---
+
+## INTENT
+Test intent
+`
+
+func newTestStarter(t *testing.T, gitClient *MockGitClient) (afero.Fs, *Starter) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte(startMission), 0644))
+	return fs, NewStarter(fs, ".mission/mission.md", gitClient)
+}
+
+func TestStarter_Start(t *testing.T) {
+	gitClient := &MockGitClient{tags: map[string]string{}}
+	fs, starter := newTestStarter(t, gitClient)
+
+	result, err := starter.Start(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &StartResult{MissionID: "start-1", Branch: "mission/start-1", BaseBranch: "main"}, result)
+	assert.Equal(t, []string{"mission/start-1"}, gitClient.createdBranches)
+	assert.Equal(t, "mission/start-1", gitClient.branch)
+
+	m, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, "mission/start-1", m.Branch)
+	assert.Equal(t, "main", m.BaseBranch)
+
+	// Starting again on the mission branch changes nothing
+	result, err = starter.Start(t.Context())
+	require.NoError(t, err)
+	assert.True(t, result.AlreadyStarted)
+	assert.Equal(t, "main", result.BaseBranch)
+	assert.Len(t, gitClient.createdBranches, 1)
+
+	gitClient.branch = "main"
+	_, err = starter.Start(t.Context())
+	assert.ErrorContains(t, err, "mission start-1 was started on mission/start-1 but main is checked out")
+}
+
+func TestStarter_StartRefused(t *testing.T) {
+	tests := []struct {
+		name    string
+		git     *MockGitClient
+		wantErr string
+	}{
+		{
+			name:    "detached HEAD",
+			git:     &MockGitClient{tags: map[string]string{}, detached: true},
+			wantErr: "cannot start a mission branch: HEAD is detached",
+		},
+		{
+			name:    "on a mission branch",
+			git:     &MockGitClient{tags: map[string]string{}, branch: "mission/other"},
+			wantErr: "mission/other is already a mission branch",
+		},
+		{
+			name:    "existing checkpoints",
+			git:     &MockGitClient{tags: map[string]string{"start-1-baseline": "abc123"}},
+			wantErr: "mission start-1 already has checkpoints on main",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, starter := newTestStarter(t, tt.git)
+			_, err := starter.Start(t.Context())
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, tt.git.createdBranches)
+		})
+	}
+
+	_, starter := newTestStarter(t, &MockGitClient{tags: map[string]string{}, detached: true})
+	_, err := starter.Start(t.Context())
+	assert.ErrorIs(t, err, git.ErrDetachedHead)
+
+	_, err = NewStarter(afero.NewMemMapFs(), ".mission/mission.md", nil).Start(t.Context())
+	assert.ErrorContains(t, err, "needs a git repository")
+}
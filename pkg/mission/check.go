@@ -14,10 +14,17 @@ type Status struct {
 	MissionStatus    string   `json:"mission_status,omitempty"`
 	MissionID        string   `json:"mission_id,omitempty"`
 	MissionIntent    string   `json:"mission_intent,omitempty"`
+	MissionPath      string   `json:"mission_path,omitempty"`
 	StaleArtifacts   []string `json:"stale_artifacts_cleaned,omitempty"`
+	Warnings         []string `json:"warnings,omitempty"`
 	Ready            bool     `json:"ready"`
 	Message          string   `json:"message"`
 	NextStep         string   `json:"next_step"`
+
+	StatusHistory []StatusTransition `json:"status_history,omitempty"`
+	RetryCount    int                `json:"retry_count,omitempty"`
+	PlanProgress  *PlanProgress      `json:"plan_progress,omitempty"`
+	PlanSteps     []PlanStep         `json:"plan_steps,omitempty"`
 }
 
 // CheckService handles mission state validation using reader/writer
@@ -85,6 +92,11 @@ func (c *CheckService) handleExistingMission(status *Status) (*Status, error) {
 	status.MissionStatus = mission.Status
 	status.MissionID = mission.ID
 	status.MissionIntent = mission.GetIntent()
+	status.MissionPath = c.MissionPath()
+	status.StatusHistory = mission.StatusHistory
+	status.RetryCount = mission.RetryCount()
+	status.PlanProgress = mission.PlanProgress()
+	status.PlanSteps = mission.PlanSteps()
 	status.Ready = false
 
 	// Context-specific validation dispatch
@@ -105,6 +117,9 @@ func (c *CheckService) handleExistingMission(status *Status) (*Status, error) {
 func (c *CheckService) validateApplyContext(mission *Mission, status *Status) (*Status, error) {
 	if mission.Status == "planned" || mission.Status == "active" || mission.Status == "failed" {
 		status.Message = "Mission is ready for execution or re-execution"
+		if status.PlanProgress != nil && mission.Status == "active" {
+			status.Message = fmt.Sprintf("%s (%s)", status.Message, status.PlanProgress.Summary)
+		}
 		status.NextStep = "PROCEED with m.apply execution."
 		return status, nil
 	}
@@ -128,7 +143,7 @@ func (c *CheckService) validateCompleteContext(mission *Mission, status *Status)
 // validateDebugContext validates mission state for m.debug command context.
 // Checks for existence and validity of diagnosis.md file.
 func (c *CheckService) validateDebugContext(mission *Mission, status *Status) (*Status, error) {
-	diagnosisPath := filepath.Join(c.MissionDir(), "diagnosis.md")
+	diagnosisPath := filepath.Join(c.RootDir(), "diagnosis.md")
 	exists, _ := afero.Exists(c.FS(), diagnosisPath)
 
 	if !exists {
@@ -631,3 +631,69 @@ created: invalid-date-format
 	require.Contains(t, status.Message, "Invalid diagnosis.md")
 	require.Contains(t, status.NextStep, "STOP")
 }
+
+func TestCheckService_SurfacesStatusHistory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionDir := ".mission"
+	fs.MkdirAll(missionDir, 0755)
+
+	missionContent := `---
+id: test-123
+status: active
+status_history:
+  - from: planned
+    to: active
+    at: 2026-01-02T10:00:00Z
+    command: m mission update --status active
+  - from: active
+    to: failed
+    at: 2026-01-02T10:05:00Z
+  - from: failed
+    to: active
+    at: 2026-01-02T10:10:00Z
+---
+
+## INTENT
+Test intent
+`
+	afero.WriteFile(fs, missionDir+"/mission.md", []byte(missionContent), 0644)
+
+	service := NewCheckService(fs, filepath.Join(missionDir, "mission.md"))
+	service.SetContext("apply")
+	status, err := service.CheckMissionState()
+	require.NoError(t, err)
+	require.Len(t, status.StatusHistory, 3)
+	require.Equal(t, "m mission update --status active", status.StatusHistory[0].Command)
+	require.Equal(t, 1, status.RetryCount)
+}
+
+func TestCheckService_ReportsPlanProgress(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionDir := ".mission"
+	fs.MkdirAll(missionDir, 0755)
+
+	missionContent := `---
+id: test-123
+status: active
+---
+
+## INTENT
+Test intent
+
+## PLAN
+- [x] First
+- [x] Second
+- [~] Third
+- [ ] Fourth
+`
+	afero.WriteFile(fs, missionDir+"/mission.md", []byte(missionContent), 0644)
+
+	service := NewCheckService(fs, filepath.Join(missionDir, "mission.md"))
+	service.SetContext("apply")
+	status, err := service.CheckMissionState()
+	require.NoError(t, err)
+	require.NotNil(t, status.PlanProgress)
+	require.Equal(t, "step 3/4 in progress", status.PlanProgress.Summary)
+	require.Len(t, status.PlanSteps, 4)
+	require.Contains(t, status.Message, "step 3/4 in progress")
+}
@@ -0,0 +1,432 @@
+package mission
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/dnatag/mission-toolkit/pkg/logger"
+	"github.com/spf13/afero"
+)
+
+// CommitTemplateFile is the file in the mission directory that replaces
+// DefaultCommitTemplate when it exists
+const CommitTemplateFile = "commit-message.tmpl"
+
+// Trailers m checkpoint commit adds to the mission commit, which m mission archive and
+// the history look the commit up by
+const (
+	TrailerMissionID    = "Mission-Id"
+	TrailerMissionTrack = "Mission-Track"
+	TrailerMissionType  = "Mission-Type"
+)
+
+// maxSubjectLength bounds the commit header, type and scope included
+const maxSubjectLength = 72
+
+// maxNoteLength bounds each execution log note
+const maxNoteLength = 100
+
+// DefaultCommitTemplate renders CommitMessageData as a conventional commit message.
+// Runs of blank lines left by empty sections are collapsed after rendering.
+const DefaultCommitTemplate = `{{.Type}}{{with .Scope}}({{.}}){{end}}: {{.Subject}}
+
+{{with .Details}}{{.}}
+
+{{end}}{{with .Narrative}}{{.}}
+
+{{end}}{{with .Steps}}Changes:
+{{range .}}- {{.}}
+{{end}}
+{{end}}{{with .Notes}}Notes:
+{{range .}}- {{.}}
+{{end}}
+{{end}}{{with .Verification}}Verification: {{.}}
+{{end}}{{with .Domains}}Domains: {{join . ", "}}
+{{end}}`
+
+// CommitMessageData is the data a commit message template is rendered with
+type CommitMessageData struct {
+	ID          string
+	MissionType string // WET or DRY
+	Track       int
+	Domains     []string
+
+	// Type and Scope form the conventional commit prefix. Type is refactor for DRY
+	// missions and feat or fix for WET missions, depending on the intent; Scope is
+	// the directory shared by all SCOPE files, if any.
+	Type  string
+	Scope string
+	// Subject is the first sentence of the INTENT, shortened to fit the header, and
+	// Details the rest of the INTENT
+	Subject string
+	Details string
+	Intent  string
+
+	// Steps lists the PLAN steps marked done
+	Steps []string
+	// Verification summarizes the last verification run in execution.log
+	Verification string
+	// Notes lists the warnings and failures from execution.log, such as failed
+	// verification attempts and rollbacks
+	Notes []string
+	// Narrative is the optional paragraph supplied by the caller
+	Narrative string
+}
+
+// CommitMessageBuilder builds the consolidated commit message of a mission from its
+// frontmatter, INTENT, PLAN and execution log
+type CommitMessageBuilder struct {
+	*BaseService
+	reader *Reader
+}
+
+// NewCommitMessageBuilder creates a new CommitMessageBuilder for the specified mission file path
+func NewCommitMessageBuilder(fs afero.Fs, path string) *CommitMessageBuilder {
+	missionDir := filepath.Dir(path)
+	return &CommitMessageBuilder{
+		BaseService: NewBaseServiceWithPath(fs, missionDir, path),
+		reader:      NewReader(fs, path),
+	}
+}
+
+// Build renders the commit message with the template in CommitTemplateFile, or
+// DefaultCommitTemplate if there is none. narrative may be empty.
+func (b *CommitMessageBuilder) Build(narrative string) (string, error) {
+	data, err := b.Data(narrative)
+	if err != nil {
+		return "", err
+	}
+
+	text := DefaultCommitTemplate
+	templatePath := filepath.Join(b.RootDir(), CommitTemplateFile)
+	if content, err := afero.ReadFile(b.FS(), templatePath); err == nil {
+		text = string(content)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading commit template: %w", err)
+	}
+
+	tmpl, err := template.New(CommitTemplateFile).Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing commit template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering commit template: %w", err)
+	}
+
+	message := normalizeCommitMessage(buf.String())
+	if message == "" {
+		return "", fmt.Errorf("commit template rendered an empty message")
+	}
+	return message, nil
+}
+
+// Data collects the template data of the mission
+func (b *CommitMessageBuilder) Data(narrative string) (*CommitMessageData, error) {
+	m, err := b.reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+	intent := strings.TrimSpace(m.GetIntent())
+	if intent == "" {
+		return nil, fmt.Errorf("mission has no INTENT to build a commit message from")
+	}
+
+	data := &CommitMessageData{
+		ID:          m.ID,
+		MissionType: strings.ToUpper(m.Type),
+		Track:       m.Track,
+		Domains:     m.Domains,
+		Type:        commitType(m.Type, intent),
+		Scope:       commitScope(m.GetScope()),
+		Intent:      intent,
+		Narrative:   strings.TrimSpace(narrative),
+	}
+	prefixLength := len(data.Type) + 2
+	if data.Scope != "" {
+		prefixLength += len(data.Scope) + 2
+	}
+	data.Subject, data.Details = commitSubject(intent, maxSubjectLength-prefixLength)
+
+	for _, step := range m.PlanSteps() {
+		if step.Status == StepDone {
+			data.Steps = append(data.Steps, step.Text)
+		}
+	}
+
+	entries, err := b.readLog()
+	if err != nil {
+		return nil, err
+	}
+	data.Verification = verificationSummary(entries, VerificationCommands(m.GetVerification()))
+	data.Notes = logNotes(entries)
+	return data, nil
+}
+
+// CommitTrailers returns the trailers identifying m in its commit. Track and type are
+// left out when the mission does not set them.
+func CommitTrailers(m *Mission) []git.Trailer {
+	trailers := []git.Trailer{{Key: TrailerMissionID, Value: m.ID}}
+	if m.Track > 0 {
+		trailers = append(trailers, git.Trailer{Key: TrailerMissionTrack, Value: strconv.Itoa(m.Track)})
+	}
+	if m.Type != "" {
+		trailers = append(trailers, git.Trailer{Key: TrailerMissionType, Value: strings.ToUpper(m.Type)})
+	}
+	return trailers
+}
+
+// readLog parses the execution.log next to the mission file, which may not exist
+func (b *CommitMessageBuilder) readLog() ([]logEntry, error) {
+	content, err := afero.ReadFile(b.FS(), filepath.Join(b.MissionDir(), "execution.log"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading execution log: %w", err)
+	}
+
+	var entries []logEntry
+	for _, line := range strings.Split(string(content), "\n") {
+		if entry, ok := parseLogEntry(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// fixPattern matches intents that describe a bug fix
+var fixPattern = regexp.MustCompile(`(?i)^(fix|bug|resolve|correct|repair|prevent)`)
+
+// commitType maps the mission type and intent to a conventional commit type
+func commitType(missionType, intent string) string {
+	if strings.EqualFold(missionType, "DRY") {
+		return "refactor"
+	}
+	if fixPattern.MatchString(intent) {
+		return "fix"
+	}
+	return "feat"
+}
+
+// commitScope returns the name of the deepest directory holding all scope files, or
+// an empty string if they share none. Glob entries count from the directory before
+// their first pattern segment, and negations are skipped.
+func commitScope(files []string) string {
+	var common []string
+	first := true
+	for _, file := range files {
+		file = filepath.ToSlash(strings.TrimSpace(file))
+		if file == "" || strings.HasPrefix(file, "!") {
+			continue
+		}
+		dirs := strings.Split(scopeEntryDir(file), "/")
+		if dirs[0] == "." {
+			return ""
+		}
+		if first {
+			common, first = dirs, false
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(dirs) && common[n] == dirs[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if len(common) == 0 {
+		return ""
+	}
+	return common[len(common)-1]
+}
+
+// scopeEntryDir returns the directory of a SCOPE entry, stopping before the first
+// segment with glob meta characters
+func scopeEntryDir(entry string) string {
+	if !hasGlobMeta(entry) {
+		return path.Dir(entry)
+	}
+	segments := strings.Split(entry, "/")
+	n := 0
+	for n < len(segments) && !hasGlobMeta(segments[n]) {
+		n++
+	}
+	if n == 0 {
+		return "."
+	}
+	return path.Join(segments[:n]...)
+}
+
+// sentenceEnd matches the end of the first sentence of a text
+var sentenceEnd = regexp.MustCompile(`[.!?](\s|$)`)
+
+// commitSubject splits intent into a subject of at most max characters, without
+// trailing punctuation and starting in lower case, and the remaining details.
+// A subject cut at a word boundary leaves the full intent in the details.
+func commitSubject(intent string, max int) (string, string) {
+	subject, details := intent, ""
+	if loc := sentenceEnd.FindStringIndex(intent); loc != nil {
+		subject, details = intent[:loc[0]], strings.TrimSpace(intent[loc[1]:])
+	}
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	if utf8.RuneCountInString(subject) > max {
+		words := strings.Fields(subject)
+		subject = ""
+		for _, word := range words {
+			if subject != "" && utf8.RuneCountInString(subject+" "+word) > max {
+				break
+			}
+			subject = strings.TrimSpace(subject + " " + word)
+		}
+		details = intent
+	}
+
+	// Keep acronyms such as API intact
+	if first, size := utf8.DecodeRuneInString(subject); size > 0 {
+		if second, _ := utf8.DecodeRuneInString(subject[size:]); !unicode.IsUpper(second) {
+			subject = string(unicode.ToLower(first)) + subject[size:]
+		}
+	}
+	return subject, details
+}
+
+// blankLines matches runs of more than one blank line
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// normalizeCommitMessage strips trailing whitespace from every line and collapses
+// blank lines left by empty template sections
+func normalizeCommitMessage(message string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	message = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(message)
+}
+
+// logEntry is a step logged to execution.log
+type logEntry struct {
+	Level   string
+	Step    string
+	Message string
+}
+
+// logFieldPattern matches key=value pairs of logrus text lines; values are bare or quoted
+var logFieldPattern = regexp.MustCompile(`([\w.]+)=("(?:[^"\\]|\\.)*"|\S*)`)
+
+// parseLogEntry parses a line written by logger.LogStep in text or JSON format
+func parseLogEntry(line string) (logEntry, bool) {
+	line = strings.TrimSpace(line)
+	fields := make(map[string]string)
+	if strings.HasPrefix(line, "{") {
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return logEntry{}, false
+		}
+		for key, value := range raw {
+			fields[key] = fmt.Sprint(value)
+		}
+	} else {
+		for _, match := range logFieldPattern.FindAllStringSubmatch(line, -1) {
+			value := match[2]
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			fields[match[1]] = value
+		}
+	}
+
+	// logrus prefixes the level field of LogStep because it clashes with its own
+	level := fields["fields.level"]
+	if level == "" {
+		return logEntry{}, false
+	}
+	return logEntry{Level: level, Step: fields["step"], Message: fields["msg"]}, true
+}
+
+// commandFields extracts the command and exit code of a verification log entry
+var commandFields = regexp.MustCompile(`^command=("(?:[^"\\]|\\.)*") exit_code=(-?\d+)`)
+
+// verificationRun is a command result recorded by m mission verify
+func verificationRun(entry logEntry) (string, string, bool) {
+	if entry.Step != "Verification" {
+		return "", "", false
+	}
+	match := commandFields.FindStringSubmatch(entry.Message)
+	if match == nil {
+		return "", "", false
+	}
+	command, err := strconv.Unquote(match[1])
+	if err != nil {
+		return "", "", false
+	}
+	return command, match[2], true
+}
+
+// verificationSummary describes the last verification run, which starts at the last
+// entry for the first of commands. It is empty if verification never ran.
+func verificationSummary(entries []logEntry, commands []string) string {
+	start := -1
+	for i, entry := range entries {
+		command, _, ok := verificationRun(entry)
+		if ok && (start == -1 || len(commands) == 0 || command == commands[0]) {
+			start = i
+		}
+	}
+	if start == -1 {
+		return ""
+	}
+
+	var ran []string
+	for _, entry := range entries[start:] {
+		command, exitCode, ok := verificationRun(entry)
+		if !ok {
+			continue
+		}
+		if entry.Level != logger.LevelSuccess {
+			return fmt.Sprintf("failed (`%s` exited with code %s)", command, exitCode)
+		}
+		ran = append(ran, "`"+command+"`")
+	}
+	return "passed (" + strings.Join(ran, ", ") + ")"
+}
+
+// logNotes lists the warnings and failures of the execution log, skipping repeats
+func logNotes(entries []logEntry) []string {
+	var notes []string
+	for _, entry := range entries {
+		if entry.Level == logger.LevelSuccess || entry.Level == logger.LevelDebug {
+			continue
+		}
+		note := entry.Step + ": " + firstLine(entry.Message)
+		if command, exitCode, ok := verificationRun(entry); ok {
+			note = fmt.Sprintf("Verification failed: `%s` exited with code %s", command, exitCode)
+		}
+		if len(notes) > 0 && notes[len(notes)-1] == note {
+			continue
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// firstLine returns the first line of message, shortened to maxNoteLength characters
+func firstLine(message string) string {
+	message, _, _ = strings.Cut(strings.TrimSpace(message), "\n")
+	if utf8.RuneCountInString(message) > maxNoteLength {
+		message = string([]rune(message)[:maxNoteLength-3]) + "..."
+	}
+	return message
+}
@@ -0,0 +1,138 @@
+package mission
+
+import (
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/logger"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commitMission = `---
+id: commit-1
+type: WET
+track: 2
+iteration: 1
+status: executed
+domains:
+    - security
+---
+
+## INTENT
+Add token refresh to the API client. Expired tokens were only detected after a failed request.
+
+## SCOPE
+pkg/client/auth.go
+pkg/client/auth_test.go
+
+## PLAN
+- [x] Refresh tokens before they expire
+- [-] Cache tokens on disk
+- [x] Cover refresh in tests
+
+## VERIFICATION
+go test ./pkg/client/...
+`
+
+// writeCommitMission writes commitMission and an execution log with a failed and a
+// passed verification run and a polish rollback
+func writeCommitMission(t *testing.T) afero.Fs {
+	fs := afero.NewMemMapFs()
+	writeTestMission(t, fs, commitMission)
+
+	config := logger.DefaultConfig()
+	config.Output = logger.OutputFile
+	config.FilePath = ".mission/execution.log"
+	config.Fs = fs
+	log := logger.NewWithConfig("commit-1", config)
+	log.LogStep(logger.LevelSuccess, "Plan Step 1", "Refresh added")
+	log.LogStep(logger.LevelFailed, "Verification", formatCommandResult(CommandResult{Command: "go test ./pkg/client/...", ExitCode: 1, Stderr: "FAIL"}))
+	log.LogStep(logger.LevelWarn, "Polish", "Rolled back polish changes\nverification failed")
+	log.LogStep(logger.LevelSuccess, "Verification", formatCommandResult(CommandResult{Command: "go test ./pkg/client/...", Stdout: "ok"}))
+	return fs
+}
+
+func TestCommitMessageBuilder_Build(t *testing.T) {
+	fs := writeCommitMission(t)
+
+	message, err := NewCommitMessageBuilder(fs, ".mission/mission.md").Build("Refreshing early avoids a failed request per expiry.")
+	require.NoError(t, err)
+	assert.Equal(t, `feat(client): add token refresh to the API client
+
+Expired tokens were only detected after a failed request.
+
+Refreshing early avoids a failed request per expiry.
+
+Changes:
+- Refresh tokens before they expire
+- Cover refresh in tests
+
+Notes:
+- Verification failed: `+"`go test ./pkg/client/...`"+` exited with code 1
+- Polish: Rolled back polish changes
+
+Verification: passed (`+"`go test ./pkg/client/...`"+`)
+Domains: security`, message)
+}
+
+func TestCommitMessageBuilder_Build_CustomTemplate(t *testing.T) {
+	fs := writeCommitMission(t)
+	require.NoError(t, afero.WriteFile(fs, ".mission/"+CommitTemplateFile, []byte("{{.Type}}: {{.Subject}}\n\n\n\n{{.Narrative}}\n\nMission {{.ID}} ({{.MissionType}}, track {{.Track}})   \n"), 0644))
+
+	message, err := NewCommitMessageBuilder(fs, ".mission/mission.md").Build("")
+	require.NoError(t, err)
+	assert.Equal(t, "feat: add token refresh to the API client\n\nMission commit-1 (WET, track 2)", message)
+
+	require.NoError(t, afero.WriteFile(fs, ".mission/"+CommitTemplateFile, []byte("{{.Unknown}}"), 0644))
+	_, err = NewCommitMessageBuilder(fs, ".mission/mission.md").Build("")
+	assert.ErrorContains(t, err, "rendering commit template")
+}
+
+func TestCommitMessageBuilder_Build_WithoutLog(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte("---\nid: dry-1\ntype: DRY\n---\n\n## INTENT\nExtract the shared retry loop\n"), 0644))
+
+	message, err := NewCommitMessageBuilder(fs, ".mission/mission.md").Build("")
+	require.NoError(t, err)
+	assert.Equal(t, "refactor: extract the shared retry loop", message)
+
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte("---\nid: dry-1\ntype: DRY\n---\n"), 0644))
+	_, err = NewCommitMessageBuilder(fs, ".mission/mission.md").Build("")
+	assert.ErrorContains(t, err, "mission has no INTENT")
+}
+
+func TestCommitSubject(t *testing.T) {
+	tests := []struct {
+		name        string
+		intent      string
+		max         int
+		wantSubject string
+		wantDetails string
+	}{
+		{"single sentence", "Fix the retry loop.", 60, "fix the retry loop", ""},
+		{"acronym", "API keys are rotated", 60, "API keys are rotated", ""},
+		{"shortened", "Add a very long feature description", 20, "add a very long", "Add a very long feature description"},
+		{"multi-line", "Add retries\nto the client", 60, "add retries to the client", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, details := commitSubject(tt.intent, tt.max)
+			assert.Equal(t, tt.wantSubject, subject)
+			assert.Equal(t, tt.wantDetails, details)
+		})
+	}
+}
+
+func TestCommitScope(t *testing.T) {
+	assert.Equal(t, "client", commitScope([]string{"pkg/client/auth.go", "pkg/client/token/cache.go"}))
+	assert.Equal(t, "pkg", commitScope([]string{"pkg/client/auth.go", "pkg/server/auth.go"}))
+	assert.Equal(t, "", commitScope([]string{"pkg/client/auth.go", "main.go"}))
+	assert.Equal(t, "", commitScope(nil))
+
+	// Globs count from the directory before their first pattern segment
+	assert.Equal(t, "pkg", commitScope([]string{"pkg/**/*.go"}))
+	assert.Equal(t, "client", commitScope([]string{"pkg/client/*.go", "pkg/client/token/", "!pkg/client/*_test.go"}))
+	assert.Equal(t, "", commitScope([]string{"**/*.go"}))
+	assert.Equal(t, "", commitScope([]string{"*.go"}))
+}
@@ -66,7 +66,7 @@ func (s *FinalizeService) Finalize() (string, error) {
 
 // cleanupTemplates removes .mission/templates folder if it exists
 func (s *FinalizeService) cleanupTemplates() error {
-	templatesPath := filepath.Join(s.MissionDir(), "templates")
+	templatesPath := filepath.Join(s.RootDir(), "templates")
 	exists, err := afero.DirExists(s.FS(), templatesPath)
 	if err != nil {
 		return fmt.Errorf("checking templates directory: %w", err)
@@ -82,7 +82,8 @@ func (s *FinalizeService) cleanupTemplates() error {
 // updateStatusToPlanned changes mission status from planning to planned
 func (s *FinalizeService) updateStatusToPlanned(missionPath string) error {
 	writer := NewWriter(s.FS(), missionPath)
-	return writer.UpdateStatus("planned")
+	writer.SetCommand("m mission finalize")
+	return writer.UpdateStatus(StatusPlanned)
 }
 
 // validateSections checks that all required sections exist and are not empty
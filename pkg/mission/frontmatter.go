@@ -0,0 +1,263 @@
+package mission
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter edit operations accepted by UpdateFrontmatter
+const (
+	OpSet        = "set"         // key=value
+	OpUnset      = "unset"       // -key
+	OpListAdd    = "list-add"    // key+=value
+	OpListRemove = "list-remove" // key-=value
+)
+
+// FrontmatterEdit is a single parsed frontmatter change
+type FrontmatterEdit struct {
+	Key    string
+	Op     string
+	Values []string
+}
+
+// fieldKind describes the value type of a known frontmatter key
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindList
+	kindManaged
+)
+
+// frontmatterField describes a known frontmatter key and how edits are validated
+type frontmatterField struct {
+	kind     fieldKind
+	required bool
+	validate func(value string) (interface{}, error)
+	managed  string // hint for keys that cannot be edited directly
+}
+
+// knownFrontmatter lists the keys backed by Mission struct fields
+var knownFrontmatter = map[string]frontmatterField{
+	"id":             {kind: kindString, required: true, validate: validateMissionID},
+	"type":           {kind: kindString, required: true, validate: validateMissionType},
+	"track":          {kind: kindInt, required: true, validate: intInRange(1, 4)},
+	"iteration":      {kind: kindInt, required: true, validate: intInRange(1, 0)},
+	"parent_mission": {kind: kindString},
+	"domains":        {kind: kindList},
+	"status":         {kind: kindManaged, managed: "use --status to change the mission status"},
+	"status_history": {kind: kindManaged, managed: "status history is recorded by --status"},
+	"plan_steps":     {kind: kindManaged, managed: "use m mission step to change plan steps"},
+	"branch":         {kind: kindManaged, managed: "the mission branch is set by m mission start"},
+	"base_branch":    {kind: kindManaged, managed: "the base branch is set by m mission start"},
+	"worktree":       {kind: kindManaged, managed: "the worktree is set by m mission start --worktree"},
+}
+
+// ParseFrontmatterEdits parses key=value, key+=value, key-=value and -key edits.
+// A fragment without an operator continues the previous edit's values, so comma
+// separated lists split by the CLI flag parser (domains=a,b) are reassembled.
+func ParseFrontmatterEdits(pairs []string) ([]FrontmatterEdit, error) {
+	var edits []FrontmatterEdit
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		edit, ok := parseFrontmatterEdit(pair)
+		if !ok {
+			if len(edits) == 0 || edits[len(edits)-1].Op == OpUnset || pair == "" {
+				return nil, fmt.Errorf("invalid frontmatter pair: %s", pair)
+			}
+			last := &edits[len(edits)-1]
+			last.Values = append(last.Values, pair)
+			continue
+		}
+		if edit.Key == "" {
+			return nil, fmt.Errorf("invalid frontmatter pair: %s", pair)
+		}
+		edits = append(edits, edit)
+	}
+	return edits, nil
+}
+
+// parseFrontmatterEdit parses a single edit, reporting false if pair has no operator
+func parseFrontmatterEdit(pair string) (FrontmatterEdit, bool) {
+	if strings.HasPrefix(pair, "-") && !strings.Contains(pair, "=") {
+		return FrontmatterEdit{Key: strings.TrimSpace(pair[1:]), Op: OpUnset}, true
+	}
+	idx := strings.Index(pair, "=")
+	if idx == -1 {
+		return FrontmatterEdit{}, false
+	}
+	key, value := pair[:idx], strings.TrimSpace(pair[idx+1:])
+	op := OpSet
+	switch {
+	case strings.HasSuffix(key, "+"):
+		op, key = OpListAdd, strings.TrimSuffix(key, "+")
+	case strings.HasSuffix(key, "-"):
+		op, key = OpListRemove, strings.TrimSuffix(key, "-")
+	}
+	return FrontmatterEdit{Key: strings.TrimSpace(key), Op: op, Values: []string{value}}, true
+}
+
+// applyFrontmatterEdits applies edits to a frontmatter map, validating known keys.
+// Repeated set edits of a list key within one call accumulate instead of replacing.
+func applyFrontmatterEdits(frontmatter map[string]interface{}, edits []FrontmatterEdit) error {
+	replaced := make(map[string]bool)
+	for _, edit := range edits {
+		field, known := knownFrontmatter[edit.Key]
+		if known && field.kind == kindManaged {
+			return fmt.Errorf("%s cannot be edited as frontmatter: %s", edit.Key, field.managed)
+		}
+
+		switch edit.Op {
+		case OpUnset:
+			if field.required {
+				return fmt.Errorf("%s is required and cannot be unset", edit.Key)
+			}
+			delete(frontmatter, edit.Key)
+
+		case OpSet:
+			isList := (known && field.kind == kindList) || (!known && len(edit.Values) > 1)
+			if !isList {
+				if len(edit.Values) > 1 {
+					return fmt.Errorf("%s takes a single value, got %d", edit.Key, len(edit.Values))
+				}
+				value, err := frontmatterScalar(edit.Key, field, known, edit.Values[0])
+				if err != nil {
+					return err
+				}
+				frontmatter[edit.Key] = value
+				continue
+			}
+			var list []string
+			if replaced[edit.Key] {
+				list, _ = frontmatterList(frontmatter, edit.Key)
+			}
+			frontmatter[edit.Key] = appendUnique(list, edit.Values)
+			replaced[edit.Key] = true
+
+		case OpListAdd, OpListRemove:
+			if known && field.kind != kindList {
+				return fmt.Errorf("%s is not a list", edit.Key)
+			}
+			list, err := frontmatterList(frontmatter, edit.Key)
+			if err != nil {
+				return err
+			}
+			if edit.Op == OpListAdd {
+				list = appendUnique(list, edit.Values)
+			} else {
+				remove := splitListValues(edit.Values)
+				list = slices.DeleteFunc(list, func(v string) bool { return slices.Contains(remove, v) })
+			}
+			if len(list) == 0 && (!known || !field.required) {
+				delete(frontmatter, edit.Key)
+			} else {
+				frontmatter[edit.Key] = list
+			}
+		}
+	}
+	return nil
+}
+
+// frontmatterScalar converts a set value for key, validating known keys and inferring
+// YAML scalar types (numbers, booleans) for unknown ones.
+func frontmatterScalar(key string, field frontmatterField, known bool, value string) (interface{}, error) {
+	if known {
+		if field.validate == nil {
+			return value, nil
+		}
+		converted, err := field.validate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		return converted, nil
+	}
+
+	var scalar interface{}
+	if err := yaml.Unmarshal([]byte(value), &scalar); err == nil {
+		switch scalar.(type) {
+		case int, float64, bool:
+			return scalar, nil
+		}
+	}
+	return value, nil
+}
+
+// frontmatterList returns the current value of key as a list of strings
+func frontmatterList(frontmatter map[string]interface{}, key string) ([]string, error) {
+	switch v := frontmatter[key].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%s is not a list", key)
+	}
+}
+
+// appendUnique appends values to list, splitting on commas and skipping empty and duplicate items
+func appendUnique(list, values []string) []string {
+	for _, value := range splitListValues(values) {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// splitListValues splits comma separated values into trimmed, non-empty items
+func splitListValues(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// validateMissionID rejects empty IDs and IDs containing whitespace
+func validateMissionID(value string) (interface{}, error) {
+	if value == "" || strings.ContainsAny(value, " \t\n") {
+		return nil, fmt.Errorf("must be a non-empty value without whitespace, got %q", value)
+	}
+	return value, nil
+}
+
+// validateMissionType accepts WET or DRY in any case
+func validateMissionType(value string) (interface{}, error) {
+	upper := strings.ToUpper(value)
+	if upper != "WET" && upper != "DRY" {
+		return nil, fmt.Errorf("must be WET or DRY, got %q", value)
+	}
+	return upper, nil
+}
+
+// intInRange returns a validator for integers >= min and, when max > 0, <= max
+func intInRange(min, max int) func(string) (interface{}, error) {
+	return func(value string) (interface{}, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer, got %q", value)
+		}
+		if n < min || (max > 0 && n > max) {
+			if max > 0 {
+				return nil, fmt.Errorf("must be between %d and %d, got %d", min, max, n)
+			}
+			return nil, fmt.Errorf("must be at least %d, got %d", min, n)
+		}
+		return n, nil
+	}
+}
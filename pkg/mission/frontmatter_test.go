@@ -0,0 +1,140 @@
+package mission
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extraFrontmatterMission = `---
+id: fm-1
+type: WET
+track: 2
+iteration: 1
+status: planned
+jira: ABC-12
+duration_minutes: 45
+labels:
+    - backend
+---
+
+## INTENT
+Test
+`
+
+func newFrontmatterWriter(t *testing.T) (afero.Fs, *Writer) {
+	fs := afero.NewMemMapFs()
+	writeTestMission(t, fs, extraFrontmatterMission)
+	return fs, NewWriter(fs, ".mission/mission.md")
+}
+
+func TestMission_PreservesUnknownFrontmatter(t *testing.T) {
+	fs, writer := newFrontmatterWriter(t)
+
+	require.NoError(t, writer.UpdateStatus(StatusActive))
+
+	m, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, StatusActive, m.Status)
+	assert.Equal(t, "ABC-12", m.Extra["jira"])
+	assert.Equal(t, 45, m.Extra["duration_minutes"])
+	assert.Equal(t, []interface{}{"backend"}, m.Extra["labels"])
+	assert.NotContains(t, m.Extra, "status", "known keys must not leak into Extra")
+}
+
+func TestReader_LegacyExtraFields(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := "# MISSION\n\nid: legacy-1\nstatus: completed\ncompleted_at: 2026-01-02\nduration_minutes: 30\n\n## INTENT\nOld\n"
+	require.NoError(t, afero.WriteFile(fs, "mission.md", []byte(content), 0644))
+
+	m, err := NewReader(fs, "mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-02", m.Extra["completed_at"])
+	assert.Equal(t, 30, m.Extra["duration_minutes"])
+}
+
+func TestParseFrontmatterEdits(t *testing.T) {
+	edits, err := ParseFrontmatterEdits([]string{"track=3", "domains=security", "performance", "labels+=api", "labels-=old", "-jira"})
+	require.NoError(t, err)
+	assert.Equal(t, []FrontmatterEdit{
+		{Key: "track", Op: OpSet, Values: []string{"3"}},
+		{Key: "domains", Op: OpSet, Values: []string{"security", "performance"}},
+		{Key: "labels", Op: OpListAdd, Values: []string{"api"}},
+		{Key: "labels", Op: OpListRemove, Values: []string{"old"}},
+		{Key: "jira", Op: OpUnset},
+	}, edits)
+
+	_, err = ParseFrontmatterEdits([]string{"invalid"})
+	assert.Error(t, err)
+	_, err = ParseFrontmatterEdits([]string{"=value"})
+	assert.Error(t, err)
+}
+
+func TestWriter_UpdateFrontmatter_Operations(t *testing.T) {
+	fs, writer := newFrontmatterWriter(t)
+
+	require.NoError(t, writer.UpdateFrontmatter([]string{
+		"type=dry",
+		"domains=security,performance",
+		"labels+=api",
+		"labels-=backend",
+		"-jira",
+		"reviewer=alice",
+		"estimate=3",
+	}))
+
+	m, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, "DRY", m.Type)
+	assert.Equal(t, []string{"security", "performance"}, m.Domains)
+	assert.Equal(t, []interface{}{"api"}, m.Extra["labels"])
+	assert.NotContains(t, m.Extra, "jira")
+	assert.Equal(t, "alice", m.Extra["reviewer"])
+	assert.Equal(t, 3, m.Extra["estimate"])
+	assert.Equal(t, 45, m.Extra["duration_minutes"])
+
+	// A later set replaces the list; list-remove can empty it
+	require.NoError(t, writer.UpdateFrontmatter([]string{"domains=api"}))
+	require.NoError(t, writer.UpdateFrontmatter([]string{"domains+=cli", "domains-=api"}))
+	m, err = NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cli"}, m.Domains)
+
+	require.NoError(t, writer.UpdateFrontmatter([]string{"-domains"}))
+	m, err = NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Empty(t, m.Domains)
+}
+
+func TestWriter_UpdateFrontmatter_Validation(t *testing.T) {
+	tests := []struct {
+		pair    string
+		wantErr string
+	}{
+		{"track=9", "invalid track: must be between 1 and 4"},
+		{"track=two", "invalid track: must be an integer"},
+		{"iteration=0", "invalid iteration: must be at least 1"},
+		{"type=MOIST", "invalid type: must be WET or DRY"},
+		{"id=has space", "invalid id"},
+		{"status=active", "use --status"},
+		{"plan_steps=x", "m mission step"},
+		{"-track", "track is required"},
+		{"track+=1", "track is not a list"},
+		{"jira+=ABC-13", "jira is not a list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pair, func(t *testing.T) {
+			fs, writer := newFrontmatterWriter(t)
+			err := writer.UpdateFrontmatter([]string{tt.pair})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			content, err := afero.ReadFile(fs, ".mission/mission.md")
+			require.NoError(t, err)
+			assert.Equal(t, extraFrontmatterMission, string(content), "failed edits must not modify the file")
+		})
+	}
+}
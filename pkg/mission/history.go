@@ -0,0 +1,220 @@
+package mission
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// HistoryFilter selects archived missions. Zero values match everything.
+type HistoryFilter struct {
+	Status   string
+	Type     string
+	Track    int
+	Domain   string
+	Since    time.Time
+	Until    time.Time
+	Touching string // file or directory path matched against the mission SCOPE
+	Text     string // case-insensitive search in the mission and its commit message
+}
+
+// ArchivedMission is an archived mission together with its archived artifacts
+type ArchivedMission struct {
+	Entry         ArchiveEntry `json:"entry"`
+	Mission       *Mission     `json:"-"`
+	Content       string       `json:"mission"`
+	ExecutionLog  string       `json:"execution_log,omitempty"`
+	CommitMessage string       `json:"commit_message,omitempty"`
+}
+
+// History queries missions archived in the completed directory
+type History struct {
+	*BaseService
+}
+
+// NewHistory creates a History for the workspace of the specified mission file path.
+// The mission directory is derived from the path's directory component.
+func NewHistory(fs afero.Fs, path string) *History {
+	missionDir := filepath.Dir(path)
+	return &History{BaseService: NewBaseServiceWithPath(fs, missionDir, path)}
+}
+
+// CompletedDir returns the directory holding archived missions
+func (h *History) CompletedDir() string {
+	return filepath.Join(h.RootDir(), "completed")
+}
+
+// Query returns archived missions matching filter, newest first. Metadata filters
+// use the archive index; only --text reads the archived mission files.
+func (h *History) Query(filter HistoryFilter) ([]ArchiveEntry, error) {
+	entries, err := NewArchiveIndex(h.FS(), h.CompletedDir()).Load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("loading archive index: %w", err)
+	}
+
+	var matches []ArchiveEntry
+	for _, entry := range entries {
+		if h.matches(entry, filter) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches, nil
+}
+
+// Show loads the archived mission with the given ID and its execution log and commit message
+func (h *History) Show(id string) (*ArchivedMission, error) {
+	missionFile := id + "-mission.md"
+	path := filepath.Join(h.CompletedDir(), missionFile)
+	data, err := afero.ReadFile(h.FS(), path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("archived mission %s not found", id)
+		}
+		return nil, fmt.Errorf("reading archived mission: %w", err)
+	}
+
+	m, err := NewReader(h.FS(), path).Read()
+	if err != nil {
+		return nil, fmt.Errorf("parsing archived mission: %w", err)
+	}
+
+	archived := &ArchivedMission{
+		Entry:         NewArchiveEntry(m, missionFile),
+		Mission:       m,
+		Content:       string(data),
+		ExecutionLog:  h.readArtifact(id, "execution.log"),
+		CommitMessage: h.readArtifact(id, "commit.msg"),
+	}
+
+	// Prefer the indexed entry, which carries the commit hash and archive time
+	if entries, err := NewArchiveIndex(h.FS(), h.CompletedDir()).Load(); err == nil {
+		for _, entry := range entries {
+			if entry.ID == id {
+				archived.Entry = entry
+				break
+			}
+		}
+	}
+	return archived, nil
+}
+
+// matches reports whether entry satisfies every set filter field
+func (h *History) matches(entry ArchiveEntry, filter HistoryFilter) bool {
+	if filter.Status != "" && !strings.EqualFold(entry.Status, filter.Status) {
+		return false
+	}
+	if filter.Type != "" && !strings.EqualFold(entry.Type, filter.Type) {
+		return false
+	}
+	if filter.Track != 0 && entry.Track != filter.Track {
+		return false
+	}
+	if filter.Domain != "" && !containsFold(entry.Domains, filter.Domain) {
+		return false
+	}
+
+	if !filter.Since.IsZero() || !filter.Until.IsZero() {
+		at := entry.ArchivedAt
+		if entry.CompletedAt != nil {
+			at = *entry.CompletedAt
+		}
+		if !filter.Since.IsZero() && at.Before(filter.Since) {
+			return false
+		}
+		if !filter.Until.IsZero() && at.After(filter.Until) {
+			return false
+		}
+	}
+
+	if filter.Touching != "" && !h.touches(entry, filter.Touching) {
+		return false
+	}
+	if filter.Text != "" && !h.containsText(entry, filter.Text) {
+		return false
+	}
+	return true
+}
+
+// touches reports whether the mission scope covers path, or any scope file lies below it
+func (h *History) touches(entry ArchiveEntry, path string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if NewScope(h.FS(), entry.Files).Match(path) {
+		return true
+	}
+	for _, file := range entry.Files {
+		if !strings.HasPrefix(file, "!") && strings.HasPrefix(file, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsText searches the archived mission and commit message for query
+func (h *History) containsText(entry ArchiveEntry, query string) bool {
+	query = strings.ToLower(query)
+	if strings.Contains(strings.ToLower(entry.ID+"\n"+entry.Intent), query) {
+		return true
+	}
+
+	m, err := NewReader(h.FS(), filepath.Join(h.CompletedDir(), entry.MissionFile)).Read()
+	if err == nil && strings.Contains(strings.ToLower(m.Body), query) {
+		return true
+	}
+	commit := h.readArtifact(entry.ID, "commit.msg")
+	return strings.Contains(strings.ToLower(commit), query)
+}
+
+// readArtifact returns an archived artifact of the mission, or "" if it was not archived
+func (h *History) readArtifact(id, name string) string {
+	data, err := afero.ReadFile(h.FS(), filepath.Join(h.CompletedDir(), fmt.Sprintf("%s-%s", id, name)))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// ParseHistoryTime parses a --since/--until value: a date (2006-01-02), an RFC 3339
+// timestamp, or a relative age such as 7d, 2w or 12h. A date used as an upper bound
+// (endOfDay) covers the whole day.
+func ParseHistoryTime(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		if endOfDay {
+			return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+		}
+		return t, nil
+	}
+
+	units := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD, RFC 3339 or an age like 7d)", value)
+}
+
+// containsFold reports whether list contains value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
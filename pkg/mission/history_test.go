@@ -0,0 +1,142 @@
+package mission
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHistoryFixture(t *testing.T) (afero.Fs, *History) {
+	fs := afero.NewMemMapFs()
+	missions := []struct {
+		id, typ, status, completed, domain, scope, intent string
+		track                                             int
+	}{
+		{"m-1", "WET", "completed", "2026-01-05T10:00:00Z", "security", "pkg/auth/login.go", "Harden login", 3},
+		{"m-2", "DRY", "completed", "2026-01-12T10:00:00Z", "api", "pkg/api/**/*.go", "Extract handler helpers", 3},
+		{"m-3", "WET", "failed", "2026-01-20T10:00:00Z", "api", "cmd/root.go", "Add flag parsing", 2},
+	}
+	for _, m := range missions {
+		content := fmt.Sprintf(`---
+id: %s
+type: %s
+track: %d
+iteration: 1
+status: %s
+domains:
+    - %s
+status_history:
+    - from: executed
+      to: %s
+      at: %s
+---
+
+## INTENT
+%s
+
+## SCOPE
+%s
+`, m.id, m.typ, m.track, m.status, m.domain, m.status, m.completed, m.intent, m.scope)
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(indexCompletedDir, m.id+"-mission.md"), []byte(content), 0644))
+	}
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(indexCompletedDir, "m-1-commit.msg"), []byte("fix(auth): rate limit login attempts"), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(indexCompletedDir, "m-1-execution.log"), []byte("step 1 done"), 0644))
+	return fs, NewHistory(fs, ".mission/mission.md")
+}
+
+func historyIDs(entries []ArchiveEntry) []string {
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func TestHistory_Query(t *testing.T) {
+	_, history := newHistoryFixture(t)
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{"all newest first", HistoryFilter{}, []string{"m-3", "m-2", "m-1"}},
+		{"status", HistoryFilter{Status: "failed"}, []string{"m-3"}},
+		{"type is case insensitive", HistoryFilter{Type: "dry"}, []string{"m-2"}},
+		{"track", HistoryFilter{Track: 3}, []string{"m-2", "m-1"}},
+		{"domain", HistoryFilter{Domain: "api"}, []string{"m-3", "m-2"}},
+		{"since", HistoryFilter{Since: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)}, []string{"m-3", "m-2"}},
+		{"until", HistoryFilter{Until: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)}, []string{"m-1"}},
+		{"touching literal file", HistoryFilter{Touching: "pkg/auth/login.go"}, []string{"m-1"}},
+		{"touching directory", HistoryFilter{Touching: "pkg/auth"}, []string{"m-1"}},
+		{"touching glob scope", HistoryFilter{Touching: "pkg/api/v1/users.go"}, []string{"m-2"}},
+		{"text in body", HistoryFilter{Text: "HANDLER"}, []string{"m-2"}},
+		{"text in commit message", HistoryFilter{Text: "rate limit"}, []string{"m-1"}},
+		{"combined", HistoryFilter{Domain: "api", Status: "completed"}, []string{"m-2"}},
+		{"no match", HistoryFilter{Text: "nothing"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := history.Query(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, historyIDs(entries))
+		})
+	}
+}
+
+func TestHistory_Query_NoArchive(t *testing.T) {
+	entries, err := NewHistory(afero.NewMemMapFs(), ".mission/mission.md").Query(HistoryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestHistory_Show(t *testing.T) {
+	_, history := newHistoryFixture(t)
+
+	archived, err := history.Show("m-1")
+	require.NoError(t, err)
+	assert.Equal(t, "m-1", archived.Entry.ID)
+	assert.Contains(t, archived.Content, "Harden login")
+	assert.Equal(t, "step 1 done", archived.ExecutionLog)
+	assert.Equal(t, "fix(auth): rate limit login attempts", archived.CommitMessage)
+
+	archived, err = history.Show("m-2")
+	require.NoError(t, err)
+	assert.Empty(t, archived.ExecutionLog)
+
+	_, err = history.Show("missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "archived mission missing not found")
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := ParseHistoryTime("2026-01-15", now, false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseHistoryTime("2026-01-15", now, true)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond), got)
+
+	got, err = ParseHistoryTime("7d", now, false)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), got)
+
+	got, err = ParseHistoryTime("2026-01-15T08:00:00Z", now, false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseHistoryTime("", now, false)
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	_, err = ParseHistoryTime("last week", now, false)
+	assert.Error(t, err)
+}
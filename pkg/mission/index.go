@@ -0,0 +1,321 @@
+package mission
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// IndexFileName is the archive index kept in the completed directory
+const IndexFileName = "index.json"
+
+// archiveIndexVersion is bumped when the index format changes incompatibly
+const archiveIndexVersion = 1
+
+// ArchiveEntry summarizes an archived mission so listings do not need to parse markdown.
+// CompletedAt is when the mission reached its final status, completed or failed.
+type ArchiveEntry struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Type        string     `json:"type"`
+	Track       int        `json:"track"`
+	Domains     []string   `json:"domains,omitempty"`
+	Intent      string     `json:"intent"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ArchivedAt  time.Time  `json:"archived_at"`
+	CommitHash  string     `json:"commit_hash,omitempty"`
+	Files       []string   `json:"files,omitempty"`
+	MissionFile string     `json:"mission_file"`
+}
+
+// archiveIndexFile is the on-disk layout of index.json
+type archiveIndexFile struct {
+	Version  int            `json:"version"`
+	Missions []ArchiveEntry `json:"missions"`
+	// Unreadable lists mission files that could not be parsed, so they do not
+	// trigger a rebuild on every load
+	Unreadable []string `json:"unreadable,omitempty"`
+}
+
+// ArchiveIndex maintains completed/index.json, the summary of all archived missions
+type ArchiveIndex struct {
+	fs  afero.Fs
+	dir string
+}
+
+// NewArchiveIndex creates an ArchiveIndex for the given completed directory
+func NewArchiveIndex(fs afero.Fs, completedDir string) *ArchiveIndex {
+	return &ArchiveIndex{fs: fs, dir: completedDir}
+}
+
+// Path returns the location of the index file
+func (x *ArchiveIndex) Path() string {
+	return filepath.Join(x.dir, IndexFileName)
+}
+
+// NewArchiveEntry builds the index entry for an archived mission file
+func NewArchiveEntry(m *Mission, missionFile string) ArchiveEntry {
+	entry := ArchiveEntry{
+		ID:          m.ID,
+		Status:      m.Status,
+		Type:        m.Type,
+		Track:       m.Track,
+		Domains:     m.Domains,
+		Intent:      summarizeIntent(m.GetIntent()),
+		Files:       m.GetScope(),
+		MissionFile: missionFile,
+	}
+
+	for _, transition := range m.StatusHistory {
+		at := transition.At
+		if entry.CreatedAt == nil {
+			entry.CreatedAt = &at
+		}
+		if transition.To == StatusCompleted || transition.To == StatusFailed {
+			entry.CompletedAt = &at
+		}
+	}
+
+	// Legacy missions record the completion date as plain frontmatter
+	if entry.CompletedAt == nil {
+		if value, ok := m.Extra["completed_at"].(string); ok {
+			if at, err := time.Parse("2006-01-02", value); err == nil {
+				entry.CompletedAt = &at
+			}
+		}
+	}
+
+	return entry
+}
+
+// Mission returns a lightweight Mission built from the entry. The body only holds
+// the intent summary and scope; read MissionFile for the full archived mission.
+func (e ArchiveEntry) Mission() *Mission {
+	var body strings.Builder
+	body.WriteString("## INTENT\n" + e.Intent + "\n")
+	if len(e.Files) > 0 {
+		body.WriteString("\n## SCOPE\n")
+		for _, file := range e.Files {
+			body.WriteString(file + "\n")
+		}
+	}
+	return &Mission{
+		ID:      e.ID,
+		Type:    e.Type,
+		Track:   e.Track,
+		Status:  e.Status,
+		Domains: e.Domains,
+		Body:    body.String(),
+	}
+}
+
+// Load returns all index entries, newest mission ID first. The index is rebuilt
+// when it is missing or no longer matches the archived mission files, so archives
+// written before the index existed are picked up automatically.
+func (x *ArchiveIndex) Load() ([]ArchiveEntry, error) {
+	files, err := x.missionFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := x.read()
+	if err != nil || !index.matches(files) {
+		return x.Rebuild()
+	}
+
+	sortArchiveEntries(index.Missions)
+	return index.Missions, nil
+}
+
+// Page returns limit entries starting at offset along with the total entry count.
+// A negative limit returns every entry after offset.
+func (x *ArchiveIndex) Page(offset, limit int) ([]ArchiveEntry, int, error) {
+	entries, err := x.Load()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(entries)
+	offset = min(max(offset, 0), total)
+	end := total
+	if limit >= 0 {
+		end = min(offset+limit, total)
+	}
+	return entries[offset:end], total, nil
+}
+
+// Upsert adds or replaces the entry with the same ID and saves the index.
+// A missing or unreadable index is rebuilt from the mission files first.
+func (x *ArchiveIndex) Upsert(entry ArchiveEntry) error {
+	index, err := x.read()
+	if err != nil {
+		if _, err := x.Rebuild(); err != nil {
+			return err
+		}
+		if index, err = x.read(); err != nil {
+			return err
+		}
+	}
+
+	entries := index.Missions
+
+	replaced := false
+	for i := range entries {
+		if entries[i].ID == entry.ID {
+			entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+
+	index.Missions = entries
+	index.Unreadable = slices.DeleteFunc(index.Unreadable, func(name string) bool { return name == entry.MissionFile })
+	return x.save(index)
+}
+
+// Rebuild re-parses every archived mission file and rewrites the index. Commit
+// hashes and archive times are kept from the previous index where available since
+// they cannot be recovered from the markdown.
+func (x *ArchiveIndex) Rebuild() ([]ArchiveEntry, error) {
+	files, err := x.missionFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make(map[string]ArchiveEntry)
+	if old, err := x.read(); err == nil {
+		for _, entry := range old.Missions {
+			previous[entry.MissionFile] = entry
+		}
+	}
+
+	index := &archiveIndexFile{Missions: make([]ArchiveEntry, 0, len(files))}
+	for _, name := range files {
+		path := filepath.Join(x.dir, name)
+		m, err := NewReader(x.fs, path).Read()
+		if err != nil || m.ID == "" {
+			index.Unreadable = append(index.Unreadable, name)
+			continue
+		}
+
+		entry := NewArchiveEntry(m, name)
+		if old, ok := previous[name]; ok {
+			entry.CommitHash = old.CommitHash
+			entry.ArchivedAt = old.ArchivedAt
+		}
+		if entry.ArchivedAt.IsZero() {
+			if info, err := x.fs.Stat(path); err == nil {
+				entry.ArchivedAt = info.ModTime().UTC()
+			}
+		}
+		index.Missions = append(index.Missions, entry)
+	}
+
+	if err := x.save(index); err != nil {
+		return nil, err
+	}
+	return index.Missions, nil
+}
+
+// read loads the index file without validating it against the directory
+func (x *ArchiveIndex) read() (*archiveIndexFile, error) {
+	data, err := afero.ReadFile(x.fs, x.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	var index archiveIndexFile
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", IndexFileName, err)
+	}
+	if index.Version != archiveIndexVersion {
+		return nil, fmt.Errorf("unsupported %s version %d (run: m archive reindex)", IndexFileName, index.Version)
+	}
+	return &index, nil
+}
+
+// save writes the index atomically via a temporary file
+func (x *ArchiveIndex) save(index *archiveIndexFile) error {
+	index.Version = archiveIndexVersion
+	sortArchiveEntries(index.Missions)
+	sort.Strings(index.Unreadable)
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", IndexFileName, err)
+	}
+
+	if err := x.fs.MkdirAll(x.dir, 0755); err != nil {
+		return fmt.Errorf("creating completed directory: %w", err)
+	}
+	tmp := x.Path() + ".tmp"
+	if err := afero.WriteFile(x.fs, tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", IndexFileName, err)
+	}
+	if err := x.fs.Rename(tmp, x.Path()); err != nil {
+		return fmt.Errorf("writing %s: %w", IndexFileName, err)
+	}
+	return nil
+}
+
+// missionFiles lists the archived *-mission.md file names in the completed directory
+func (x *ArchiveIndex) missionFiles() ([]string, error) {
+	infos, err := afero.ReadDir(x.fs, x.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), "-mission.md") {
+			files = append(files, info.Name())
+		}
+	}
+	return files, nil
+}
+
+// matches reports whether the index covers exactly the given mission files
+func (index *archiveIndexFile) matches(files []string) bool {
+	if len(index.Missions)+len(index.Unreadable) != len(files) {
+		return false
+	}
+	indexed := make(map[string]bool, len(files))
+	for _, entry := range index.Missions {
+		indexed[entry.MissionFile] = true
+	}
+	for _, name := range index.Unreadable {
+		indexed[name] = true
+	}
+	for _, file := range files {
+		if !indexed[file] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortArchiveEntries orders entries by mission ID, newest first
+func sortArchiveEntries(entries []ArchiveEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+}
+
+// summarizeIntent returns the first non-empty line of an intent
+func summarizeIntent(intent string) string {
+	for _, line := range strings.Split(intent, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package mission
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const indexCompletedDir = ".mission/completed"
+
+func writeArchivedMission(t *testing.T, fs afero.Fs, id, intent string) {
+	content := fmt.Sprintf(`---
+id: %s
+type: WET
+track: 2
+iteration: 1
+status: completed
+status_history:
+    - from: planned
+      to: active
+      at: 2026-01-02T10:00:00Z
+    - from: executed
+      to: completed
+      at: 2026-01-02T11:00:00Z
+---
+
+## INTENT
+%s
+
+## SCOPE
+pkg/%s.go
+`, id, intent, id)
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(indexCompletedDir, id+"-mission.md"), []byte(content), 0644))
+}
+
+func TestArchiveIndex_LoadBuildsMissingIndex(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeArchivedMission(t, fs, "m-1", "First")
+	writeArchivedMission(t, fs, "m-2", "Second")
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(indexCompletedDir, "m-2-execution.log"), []byte("log"), 0644))
+
+	index := NewArchiveIndex(fs, indexCompletedDir)
+	entries, err := index.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "m-2", entries[0].ID, "newest mission first")
+	assert.Equal(t, "m-1-mission.md", entries[1].MissionFile)
+	assert.Equal(t, []string{"pkg/m-1.go"}, entries[1].Files)
+	require.NotNil(t, entries[1].CreatedAt)
+	require.NotNil(t, entries[1].CompletedAt)
+	assert.Equal(t, time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), entries[1].CreatedAt.UTC())
+	assert.Equal(t, time.Date(2026, 1, 2, 11, 0, 0, 0, time.UTC), entries[1].CompletedAt.UTC())
+
+	exists, err := afero.Exists(fs, index.Path())
+	require.NoError(t, err)
+	assert.True(t, exists, "Load should persist the rebuilt index")
+}
+
+func TestArchiveIndex_Page(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for i := 1; i <= 5; i++ {
+		writeArchivedMission(t, fs, fmt.Sprintf("m-%d", i), "Intent")
+	}
+	index := NewArchiveIndex(fs, indexCompletedDir)
+
+	page, total, err := index.Page(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, "m-4", page[0].ID)
+	assert.Equal(t, "m-3", page[1].ID)
+
+	page, _, err = index.Page(3, -1)
+	require.NoError(t, err)
+	assert.Len(t, page, 2)
+
+	page, _, err = index.Page(10, 2)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func TestArchiveIndex_UsesIndexWithoutParsing(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeArchivedMission(t, fs, "m-1", "Original intent")
+	index := NewArchiveIndex(fs, indexCompletedDir)
+	_, err := index.Load()
+	require.NoError(t, err)
+
+	// Editing the markdown does not change listings until reindex
+	writeArchivedMission(t, fs, "m-1", "Edited intent")
+	entries, err := index.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "Original intent", entries[0].Intent)
+
+	entries, err = index.Rebuild()
+	require.NoError(t, err)
+	assert.Equal(t, "Edited intent", entries[0].Intent)
+}
+
+func TestArchiveIndex_RebuildKeepsCommitHash(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeArchivedMission(t, fs, "m-1", "First")
+	index := NewArchiveIndex(fs, indexCompletedDir)
+
+	m, err := NewReader(fs, filepath.Join(indexCompletedDir, "m-1-mission.md")).Read()
+	require.NoError(t, err)
+	entry := NewArchiveEntry(m, "m-1-mission.md")
+	entry.CommitHash = "abc123"
+	require.NoError(t, index.Upsert(entry))
+
+	// New archives written without the index are picked up on load
+	writeArchivedMission(t, fs, "m-2", "Second")
+	entries, err := index.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "abc123", entries[1].CommitHash)
+
+	entries, err = index.Rebuild()
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", entries[1].CommitHash)
+}
+
+func TestArchiveIndex_UnreadableMissionFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeArchivedMission(t, fs, "m-1", "First")
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(indexCompletedDir, "bad-mission.md"), []byte("---\ninvalid: yaml: content:\n---\n"), 0644))
+
+	index := NewArchiveIndex(fs, indexCompletedDir)
+	entries, err := index.Load()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	content, err := afero.ReadFile(fs, index.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "bad-mission.md")
+}
+
+func TestArchiveIndex_MissingDirectory(t *testing.T) {
+	_, _, err := NewArchiveIndex(afero.NewMemMapFs(), indexCompletedDir).Page(0, -1)
+	assert.Error(t, err)
+}
+
+func TestArchiveEntry_Mission(t *testing.T) {
+	entry := ArchiveEntry{ID: "m-1", Type: "DRY", Track: 3, Status: StatusCompleted, Intent: "Refactor", Files: []string{"a.go"}}
+	m := entry.Mission()
+	assert.Equal(t, "Refactor", m.GetIntent())
+	assert.Equal(t, []string{"a.go"}, m.GetScope())
+	assert.Equal(t, 3, m.Track)
+}
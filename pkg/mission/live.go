@@ -0,0 +1,63 @@
+package mission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+)
+
+// LiveMissionIDs returns the IDs of missions whose checkpoints may still be needed:
+// missions in the workspace at rootDir or in any other worktree of the repository,
+// paused missions, and missions archived at or after archivedSince. gitClient may be
+// nil outside a repository, in which case only rootDir is read.
+func LiveMissionIDs(ctx context.Context, fs afero.Fs, rootDir string, gitClient git.GitClient, archivedSince time.Time) (map[string]bool, error) {
+	roots := []string{rootDir}
+	if gitClient != nil {
+		worktrees, err := gitClient.ListWorktrees(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing worktrees: %w", err)
+		}
+		for _, wt := range worktrees {
+			if !wt.Prunable {
+				roots = append(roots, filepath.Join(wt.Path, DefaultDir))
+			}
+		}
+	}
+
+	live := make(map[string]bool)
+	for _, root := range roots {
+		missions, err := NewWorkspace(fs, root).List()
+		if err != nil {
+			return nil, fmt.Errorf("listing missions in %s: %w", root, err)
+		}
+		for _, m := range missions {
+			live[m.ID] = true
+		}
+
+		paused, err := NewPauser(fs, filepath.Join(root, "mission.md"), nil).List()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paused {
+			live[p.MissionID] = true
+		}
+
+		archived, err := NewArchiveIndex(fs, filepath.Join(root, "completed")).Load()
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading archive index: %w", err)
+		}
+		for _, entry := range archived {
+			if !entry.ArchivedAt.Before(archivedSince) {
+				live[entry.ID] = true
+			}
+		}
+	}
+	delete(live, "")
+	return live, nil
+}
@@ -0,0 +1,34 @@
+package mission
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveMissionIDs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	now := time.Now().UTC()
+
+	live, err := LiveMissionIDs(t.Context(), fs, ".mission", nil, now)
+	require.NoError(t, err)
+	assert.Empty(t, live, "an empty workspace has no live missions")
+
+	pauseMission(t, fs, "paused-1", nil)
+	writeWorkspaceMission(t, fs, filepath.Join(".mission", "mission.md"), "root-1", "active")
+	writeWorkspaceMission(t, fs, filepath.Join(".mission", "missions", "named-1", "mission.md"), "named-1", "planned")
+
+	index := NewArchiveIndex(fs, indexCompletedDir)
+	for id, archivedAt := range map[string]time.Time{"recent-1": now.Add(-time.Hour), "old-1": now.AddDate(0, 0, -30)} {
+		writeArchivedMission(t, fs, id, "Archived "+id)
+		require.NoError(t, index.Upsert(ArchiveEntry{ID: id, Status: StatusCompleted, ArchivedAt: archivedAt, MissionFile: id + "-mission.md"}))
+	}
+
+	live, err = LiveMissionIDs(t.Context(), fs, ".mission", &MockGitClient{}, now.AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"paused-1": true, "root-1": true, "named-1": true, "recent-1": true}, live)
+}
@@ -16,6 +16,23 @@ type Mission struct {
 	Status        string   `yaml:"status"`
 	ParentMission string   `yaml:"parent_mission,omitempty"`
 
+	// Branch is the mission/<id> branch created by m mission start and BaseBranch the
+	// branch it was started from, which the mission is merged back into or restored to
+	Branch     string `yaml:"branch,omitempty"`
+	BaseBranch string `yaml:"base_branch,omitempty"`
+	// Worktree is the directory of the git worktree the mission runs in, set by
+	// m mission start --worktree
+	Worktree string `yaml:"worktree,omitempty"`
+
+	// StatusHistory records every status transition applied through the Writer
+	StatusHistory []StatusTransition `yaml:"status_history,omitempty"`
+
+	// Steps holds plan step IDs and timestamps; see PlanSteps for the reconciled view
+	Steps []PlanStep `yaml:"plan_steps,omitempty"`
+
+	// Extra holds frontmatter keys without a dedicated field so they survive rewrites
+	Extra map[string]interface{} `yaml:",inline"`
+
 	// Markdown body (everything after frontmatter)
 	Body string
 }
@@ -3,9 +3,17 @@ package mission
 import (
 	"testing"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// writeTestMission writes content as the active mission, .mission/mission.md
+func writeTestMission(t *testing.T, fs afero.Fs, content string) {
+	t.Helper()
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte(content), 0644))
+}
+
 func TestMission_GetScope(t *testing.T) {
 	testCases := []struct {
 		name     string
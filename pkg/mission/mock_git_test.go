@@ -1,62 +1,232 @@
 package mission
 
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+)
+
 type MockGitClient struct {
 	commitMessage string
 	commitError   error
+	changedFiles  []git.FileChange
+	tags          map[string]string
+	// headErr is returned by GetTagCommit for HEAD and by FindCommitsByTrailer when set
+	headErr error
+
+	// stash records StashChanges calls; stashConflicts is returned by ApplyStash
+	stashRef       string
+	stashedFiles   []string
+	stashConflicts []string
+	appliedRefs    []string
+	deletedRefs    []string
+
+	// commitsBetween is returned by CommitsBetween, keyed by "from..to"
+	commitsBetween map[string][]git.CommitInfo
+	// trailerCommits is returned by FindCommitsByTrailer; when nil HEAD is found if
+	// commitMessage has the trailer
+	trailerCommits []git.CommitInfo
+	createdTags    map[string]string
+
+	// branch is the checked out branch, main when unset, unless detached is set
+	branch          string
+	detached        bool
+	createdBranches []string
 }
 
-func (m *MockGitClient) Add(files []string) error {
+func (m *MockGitClient) Add(ctx context.Context, files []string) error {
 	return nil
 }
 
-func (m *MockGitClient) Commit(message string) (string, error) {
+func (m *MockGitClient) Commit(ctx context.Context, message string) (string, error) {
 	return "mock-hash", nil
 }
 
-func (m *MockGitClient) CommitNoVerify(message string) (string, error) {
+func (m *MockGitClient) CommitNoVerify(ctx context.Context, message string) (string, error) {
 	return "mock-hash", nil
 }
 
-func (m *MockGitClient) CreateTag(name string, commitHash string) error {
+func (m *MockGitClient) CreateTag(ctx context.Context, name string, commitHash string) error {
+	if m.createdTags != nil {
+		m.createdTags[name] = commitHash
+	}
 	return nil
 }
 
-func (m *MockGitClient) Restore(checkpointName string, files []string) error {
+func (m *MockGitClient) Restore(ctx context.Context, checkpointName string, files []string) error {
 	return nil
 }
 
-func (m *MockGitClient) ListTags(prefix string) ([]string, error) {
-	return []string{}, nil
+func (m *MockGitClient) ListTags(ctx context.Context, prefix string) ([]string, error) {
+	tags := []string{}
+	for tag := range m.tags {
+		if strings.HasPrefix(tag, prefix) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
 }
 
-func (m *MockGitClient) DeleteTag(name string) error {
+func (m *MockGitClient) DeleteTag(ctx context.Context, name string) error {
 	return nil
 }
 
-func (m *MockGitClient) GetTagCommit(tagName string) (string, error) {
+func (m *MockGitClient) GetTagCommit(ctx context.Context, tagName string) (string, error) {
+	if tagName == "HEAD" && m.headErr != nil {
+		return "", m.headErr
+	}
+	if m.tags != nil {
+		if hash, ok := m.tags[tagName]; ok {
+			return hash, nil
+		}
+		return "", fmt.Errorf("tag %s not found", tagName)
+	}
 	return "mock-commit-hash", nil
 }
 
-func (m *MockGitClient) SoftReset(commitHash string) error {
+func (m *MockGitClient) SoftReset(ctx context.Context, commitHash string) error {
 	return nil
 }
 
-func (m *MockGitClient) GetCommitMessage(commitHash string) (string, error) {
+func (m *MockGitClient) GetCommitMessage(ctx context.Context, commitHash string) (string, error) {
 	return m.commitMessage, m.commitError
 }
 
-func (m *MockGitClient) IsTracked(path string) (bool, error) {
+func (m *MockGitClient) GetCommitTime(ctx context.Context, commitHash string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (m *MockGitClient) IsTracked(ctx context.Context, path string) (bool, error) {
 	return true, nil
 }
 
-func (m *MockGitClient) GetCommitParent(commitHash string) (string, error) {
+func (m *MockGitClient) GetCommitParent(ctx context.Context, commitHash string) (string, error) {
 	return "mock-parent-hash", nil
 }
 
-func (m *MockGitClient) GetUnstagedFiles() ([]string, error) {
+func (m *MockGitClient) GetUnstagedFiles(ctx context.Context) ([]string, error) {
 	return []string{}, nil
 }
 
-func (m *MockGitClient) GetUntrackedFiles() ([]string, error) {
+func (m *MockGitClient) GetUntrackedFiles(ctx context.Context) ([]string, error) {
 	return []string{}, nil
 }
+
+func (m *MockGitClient) GetChangedFiles(ctx context.Context, base string) ([]git.FileChange, error) {
+	return m.changedFiles, nil
+}
+
+func (m *MockGitClient) StashChanges(ctx context.Context, ref string, files []string, message string) (string, error) {
+	if len(files) == 0 {
+		return "", git.ErrNoChanges
+	}
+	m.stashRef = ref
+	m.stashedFiles = files
+	return "mock-stash-hash", nil
+}
+
+func (m *MockGitClient) ApplyStash(ctx context.Context, ref string) ([]string, error) {
+	m.appliedRefs = append(m.appliedRefs, ref)
+	return m.stashConflicts, nil
+}
+
+func (m *MockGitClient) SnapshotFiles(ctx context.Context, ref, parent string, files []string, message string) (string, error) {
+	if len(files) == 0 {
+		return "", git.ErrNoChanges
+	}
+	return "mock-snapshot-hash", nil
+}
+
+func (m *MockGitClient) UpdateRef(ctx context.Context, ref, commitHash string) error {
+	return nil
+}
+
+func (m *MockGitClient) ListRefs(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockGitClient) DeleteRef(ctx context.Context, ref string) error {
+	m.deletedRefs = append(m.deletedRefs, ref)
+	return nil
+}
+
+func (m *MockGitClient) CommitsBetween(ctx context.Context, from, to string) ([]git.CommitInfo, error) {
+	return m.commitsBetween[from+".."+to], nil
+}
+
+func (m *MockGitClient) FindCommitsByTrailer(ctx context.Context, key, value string) ([]git.CommitInfo, error) {
+	if m.headErr != nil {
+		return nil, m.headErr
+	}
+	if m.trailerCommits != nil {
+		return m.trailerCommits, nil
+	}
+	hash, _ := m.GetTagCommit(ctx, "HEAD")
+	head := git.CommitInfo{Hash: hash, Message: m.commitMessage}
+	if trailer := head.Trailer(key); trailer != "" && (value == "" || trailer == value) {
+		return []git.CommitInfo{head}, nil
+	}
+	return nil, nil
+}
+
+func (m *MockGitClient) DiffStat(ctx context.Context, from, to string) ([]git.FileStat, error) {
+	return nil, nil
+}
+
+func (m *MockGitClient) Diff(ctx context.Context, from, to string, paths []string) ([]git.FileDiff, error) {
+	return nil, nil
+}
+
+func (m *MockGitClient) CurrentBranch(ctx context.Context) (string, error) {
+	if m.detached {
+		return "", nil
+	}
+	if m.branch == "" {
+		return "main", nil
+	}
+	return m.branch, nil
+}
+
+func (m *MockGitClient) CreateBranch(ctx context.Context, name, startPoint string) error {
+	m.createdBranches = append(m.createdBranches, name)
+	return nil
+}
+
+func (m *MockGitClient) SwitchBranch(ctx context.Context, name string) error {
+	m.branch = name
+	return nil
+}
+
+func (m *MockGitClient) DeleteBranch(ctx context.Context, name string) error {
+	return nil
+}
+
+func (m *MockGitClient) MergeFastForward(ctx context.Context, branch string) (string, error) {
+	return "mock-hash", nil
+}
+
+func (m *MockGitClient) MergeSquash(ctx context.Context, branch, message string) (string, error) {
+	return "mock-hash", nil
+}
+
+func (m *MockGitClient) AddWorktree(ctx context.Context, path, branch, startPoint string) error {
+	m.createdBranches = append(m.createdBranches, branch)
+	return nil
+}
+
+func (m *MockGitClient) RemoveWorktree(ctx context.Context, path string, force bool) error {
+	return nil
+}
+
+func (m *MockGitClient) ListWorktrees(ctx context.Context) ([]git.Worktree, error) {
+	return []git.Worktree{{Path: ".", Head: "mock-hash", Branch: "main"}}, nil
+}
+
+func (m *MockGitClient) PruneWorktrees(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,266 @@
+package mission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+)
+
+// RestoreOptions controls how Restore handles changes made since the pause
+type RestoreOptions struct {
+	// Force restores even though SCOPE files changed since the pause; stashed changes
+	// are merged into them and conflicts are left with markers
+	Force bool
+	// Replay recreates checkpoints that are no longer in the history of HEAD on top of it
+	Replay bool
+}
+
+// ScopeOverlap is a SCOPE file of a paused mission that commits made since the pause also changed
+type ScopeOverlap struct {
+	Path    string          `json:"path"`
+	Commits []OverlapCommit `json:"commits"`
+}
+
+// OverlapCommit is a commit that changed a paused mission's SCOPE file
+type OverlapCommit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	// MissionID is the archived or checkpointed mission that made the commit, if known
+	MissionID string `json:"mission_id,omitempty"`
+}
+
+// ScopeOverlapError is returned by Restore when the paused mission's SCOPE files changed
+// since the pause, or its checkpoints are no longer in the history of HEAD
+type ScopeOverlapError struct {
+	MissionID string
+	Overlaps  []ScopeOverlap
+	// MissingCheckpoints lists checkpoint tags whose commits HEAD no longer contains
+	MissingCheckpoints []string
+}
+
+func (e *ScopeOverlapError) Error() string {
+	var problems []string
+	if len(e.Overlaps) > 0 {
+		problems = append(problems, fmt.Sprintf("%d SCOPE files changed since %s was paused", len(e.Overlaps), e.MissionID))
+	}
+	if len(e.MissingCheckpoints) > 0 {
+		problems = append(problems, fmt.Sprintf("%d checkpoints of %s are no longer in the history of HEAD", len(e.MissingCheckpoints), e.MissionID))
+	}
+	return strings.Join(problems, "; ") + " (use --force to merge anyway or --replay to replay its checkpoints onto HEAD)"
+}
+
+// checkpointCommit is a checkpoint tag and the commit it created
+type checkpointCommit struct {
+	tag    string
+	commit git.CommitInfo
+}
+
+// checkRestore compares the paused mission against HEAD, returning SCOPE files changed
+// since the pause and checkpoints HEAD no longer contains. Without git or a recorded
+// base commit there is nothing to compare.
+func (p *Pauser) checkRestore(ctx context.Context, paused *PausedMission) ([]ScopeOverlap, []checkpointCommit, error) {
+	if p.git == nil || paused.BaseCommit == "" {
+		return nil, nil, nil
+	}
+	head, err := p.git.GetTagCommit(ctx, "HEAD")
+	if err != nil || head == paused.BaseCommit {
+		return nil, nil, nil
+	}
+
+	overlaps, err := p.scopeOverlaps(ctx, paused)
+	if err != nil {
+		return nil, nil, err
+	}
+	missing, err := p.missingCheckpoints(ctx, &paused.PauseRecord)
+	if err != nil {
+		return nil, nil, err
+	}
+	return overlaps, missing, nil
+}
+
+// scopeOverlaps lists the SCOPE files changed by commits between the pause base and HEAD
+func (p *Pauser) scopeOverlaps(ctx context.Context, paused *PausedMission) ([]ScopeOverlap, error) {
+	if paused.mission == nil {
+		return nil, nil
+	}
+	commits, err := p.git.CommitsBetween(ctx, paused.BaseCommit, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("listing commits since the pause: %w", err)
+	}
+
+	scope := NewScope(p.FS(), paused.mission.GetScope())
+	var attribution *commitAttribution
+	byPath := make(map[string]*ScopeOverlap)
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			if isExcludedScopePath(file) || !scope.Match(file) {
+				continue
+			}
+			if attribution == nil {
+				attribution = p.commitAttribution()
+			}
+			overlap, ok := byPath[file]
+			if !ok {
+				overlap = &ScopeOverlap{Path: file}
+				byPath[file] = overlap
+			}
+			overlap.Commits = append(overlap.Commits, OverlapCommit{
+				Hash:      commit.Hash,
+				Subject:   commit.Subject(),
+				MissionID: attribution.missionID(commit),
+			})
+		}
+	}
+
+	overlaps := make([]ScopeOverlap, 0, len(byPath))
+	for _, overlap := range byPath {
+		overlaps = append(overlaps, *overlap)
+	}
+	sort.Slice(overlaps, func(i, j int) bool { return overlaps[i].Path < overlaps[j].Path })
+	return overlaps, nil
+}
+
+// missingCheckpoints returns the numbered checkpoints, oldest first, whose commits are
+// not in the history of HEAD. Checkpoints that only tagged an existing commit because
+// nothing changed are skipped, as there is nothing to replay.
+func (p *Pauser) missingCheckpoints(ctx context.Context, record *PauseRecord) ([]checkpointCommit, error) {
+	var missing []checkpointCommit
+	for _, tag := range numberedCheckpoints(record.MissionID, record.Checkpoints) {
+		hash, err := p.git.GetTagCommit(ctx, tag)
+		if err != nil {
+			continue
+		}
+		commits, err := p.git.CommitsBetween(ctx, "HEAD", tag)
+		if err != nil {
+			return nil, fmt.Errorf("checking checkpoint %s: %w", tag, err)
+		}
+		for _, commit := range commits {
+			if commit.Hash == hash && commit.Subject() == "checkpoint: "+tag {
+				missing = append(missing, checkpointCommit{tag: tag, commit: commit})
+				break
+			}
+		}
+	}
+	return missing, nil
+}
+
+// replayCheckpoints recreates the missing checkpoints on top of HEAD, oldest first, and
+// moves their tags (and the baseline tag) to the new commits, like a rebase. It stops
+// at the first checkpoint that does not apply cleanly, leaving conflict markers;
+// restoring with --replay again resumes from that checkpoint.
+func (p *Pauser) replayCheckpoints(ctx context.Context, record *PauseRecord, missing []checkpointCommit) ([]string, error) {
+	baselineTag := record.MissionID + "-baseline"
+	baseline, _ := p.git.GetTagCommit(ctx, baselineTag)
+
+	var replayed []string
+	for _, cp := range missing {
+		conflicts, err := p.git.ApplyStash(ctx, cp.tag)
+		if err != nil {
+			return replayed, fmt.Errorf("replaying checkpoint %s: %w", cp.tag, err)
+		}
+		if len(conflicts) > 0 {
+			return replayed, fmt.Errorf("replaying checkpoint %s left conflict markers in %s; resolve them and restore with --replay again",
+				cp.tag, strings.Join(conflicts, ", "))
+		}
+
+		if err := p.git.Add(ctx, cp.commit.Files); err != nil {
+			return replayed, fmt.Errorf("staging checkpoint %s: %w", cp.tag, err)
+		}
+		hash, err := p.git.CommitNoVerify(ctx, "checkpoint: "+cp.tag)
+		if errors.Is(err, git.ErrNoChanges) {
+			// Already applied, e.g. by resolving a conflict from an earlier replay
+			hash, err = p.git.GetTagCommit(ctx, "HEAD")
+		}
+		if err != nil {
+			return replayed, fmt.Errorf("committing checkpoint %s: %w", cp.tag, err)
+		}
+
+		tags := []string{cp.tag}
+		if baseline == cp.commit.Hash {
+			tags = append(tags, baselineTag)
+		}
+		for _, tag := range tags {
+			if err := p.git.DeleteTag(ctx, tag); err != nil {
+				return replayed, fmt.Errorf("moving tag %s: %w", tag, err)
+			}
+			if err := p.git.CreateTag(ctx, tag, hash); err != nil {
+				return replayed, fmt.Errorf("moving tag %s: %w", tag, err)
+			}
+		}
+		replayed = append(replayed, cp.tag)
+	}
+	return replayed, nil
+}
+
+// numberedCheckpoints returns the numbered checkpoint tags of missionID in checkpoint order
+func numberedCheckpoints(missionID string, tags []string) []string {
+	number := func(tag string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(tag, missionID+"-"))
+		return n
+	}
+	var numbered []string
+	for _, tag := range missionCheckpointTags(missionID, tags) {
+		if number(tag) > 0 {
+			numbered = append(numbered, tag)
+		}
+	}
+	sort.Slice(numbered, func(i, j int) bool { return number(numbered[i]) < number(numbered[j]) })
+	return numbered
+}
+
+// commitAttribution maps commits to the missions that made them using the archive
+type commitAttribution struct {
+	byHash    map[string]string
+	byMessage map[string]string
+}
+
+// commitAttribution indexes archived missions by their commit hash and commit message
+func (p *Pauser) commitAttribution() *commitAttribution {
+	a := &commitAttribution{byHash: make(map[string]string), byMessage: make(map[string]string)}
+	completedDir := filepath.Join(p.RootDir(), "completed")
+	entries, err := NewArchiveIndex(p.FS(), completedDir).Load()
+	if err != nil {
+		return a
+	}
+	for _, entry := range entries {
+		if entry.CommitHash != "" {
+			a.byHash[entry.CommitHash] = entry.ID
+		}
+		if data, err := afero.ReadFile(p.FS(), filepath.Join(completedDir, entry.ID+"-commit.msg")); err == nil {
+			if message := strings.TrimSpace(string(data)); message != "" {
+				a.byMessage[message] = entry.ID
+			}
+		}
+	}
+	return a
+}
+
+// missionID returns the mission that made commit: the mission named by its Mission-Id
+// trailer, an archived mission by commit hash or archived commit message, or a mission
+// whose checkpoint commit it is
+func (a *commitAttribution) missionID(commit git.CommitInfo) string {
+	if id := commit.Trailer(TrailerMissionID); id != "" {
+		return id
+	}
+	if id, ok := a.byHash[commit.Hash]; ok {
+		return id
+	}
+	if id, ok := a.byMessage[strings.TrimSpace(commit.Message)]; ok {
+		return id
+	}
+	if tag, ok := strings.CutPrefix(commit.Subject(), "checkpoint: "); ok {
+		if idx := strings.LastIndex(tag, "-"); idx > 0 {
+			if _, err := strconv.Atoi(tag[idx+1:]); err == nil {
+				return tag[:idx]
+			}
+		}
+	}
+	return ""
+}
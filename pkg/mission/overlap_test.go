@@ -0,0 +1,105 @@
+package mission
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauser_Restore_ScopeOverlap(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+
+	mockGit := &MockGitClient{
+		tags:         map[string]string{"HEAD": "base-hash"},
+		changedFiles: []git.FileChange{{Path: "pkg/a.go", Change: git.ChangeModified}},
+	}
+	_, err := NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+
+	// Other missions were committed and archived meanwhile, and one is still checkpointing
+	writeArchivedMission(t, fs, "20260102-100000", "Other work")
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(indexCompletedDir, "20260102-100000-commit.msg"), []byte("feat: other work\n"), 0644))
+	mockGit.tags["HEAD"] = "new-head"
+	mockGit.commitsBetween = map[string][]git.CommitInfo{
+		"base-hash..HEAD": {
+			{Hash: "c3", Message: "fix: not archived yet\n\nMission-Id: 20260103-090000", Files: []string{"pkg/a.go"}},
+			{Hash: "c2", Message: "feat: other work", Files: []string{"README.md", "pkg/a.go"}},
+			{Hash: "c1", Message: "checkpoint: wip-3", Files: []string{"pkg/a.go", "pkg/b.go"}},
+		},
+	}
+
+	_, err = NewPauser(fs, missionPath, mockGit).Restore(t.Context(), "stash-1", RestoreOptions{})
+	var overlapErr *ScopeOverlapError
+	require.True(t, errors.As(err, &overlapErr), "expected ScopeOverlapError, got %v", err)
+	assert.Contains(t, err.Error(), "2 SCOPE files changed since stash-1 was paused")
+	require.Len(t, overlapErr.Overlaps, 2)
+	assert.Equal(t, "pkg/a.go", overlapErr.Overlaps[0].Path)
+	assert.Equal(t, []OverlapCommit{
+		{Hash: "c3", Subject: "fix: not archived yet", MissionID: "20260103-090000"},
+		{Hash: "c2", Subject: "feat: other work", MissionID: "20260102-100000"},
+		{Hash: "c1", Subject: "checkpoint: wip-3", MissionID: "wip"},
+	}, overlapErr.Overlaps[0].Commits)
+	assert.Equal(t, "pkg/b.go", overlapErr.Overlaps[1].Path)
+	assert.Empty(t, mockGit.appliedRefs, "nothing is reapplied without --force")
+
+	exists, err := afero.Exists(fs, missionPath)
+	require.NoError(t, err)
+	assert.False(t, exists, "a refused restore must not restore the mission files")
+
+	result, err := NewPauser(fs, missionPath, mockGit).Restore(t.Context(), "stash-1", RestoreOptions{Force: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Overlaps, 2)
+	assert.Equal(t, []string{"refs/mission/paused/stash-1"}, mockGit.appliedRefs)
+}
+
+func TestPauser_Restore_ReplaysCheckpoints(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+
+	mockGit := &MockGitClient{
+		tags: map[string]string{
+			"HEAD":             "cp2",
+			"stash-1-baseline": "cp1",
+			"stash-1-1":        "cp1",
+			"stash-1-2":        "cp2",
+		},
+		changedFiles: []git.FileChange{{Path: "pkg/a.go", Change: git.ChangeModified}},
+		createdTags:  make(map[string]string),
+	}
+	record, err := NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stash-1-1", "stash-1-2", "stash-1-baseline"}, record.Checkpoints)
+
+	// HEAD was reset to before the checkpoints
+	mockGit.tags["HEAD"] = "other-head"
+	mockGit.commitsBetween = map[string][]git.CommitInfo{
+		"HEAD..stash-1-1": {{Hash: "cp1", Message: "checkpoint: stash-1-1", Files: []string{"pkg/a.go"}}},
+		"HEAD..stash-1-2": {
+			{Hash: "cp2", Message: "checkpoint: stash-1-2", Files: []string{"pkg/b.go"}},
+			{Hash: "cp1", Message: "checkpoint: stash-1-1", Files: []string{"pkg/a.go"}},
+		},
+	}
+
+	_, err = NewPauser(fs, missionPath, mockGit).Restore(t.Context(), "stash-1", RestoreOptions{})
+	var overlapErr *ScopeOverlapError
+	require.True(t, errors.As(err, &overlapErr), "expected ScopeOverlapError, got %v", err)
+	assert.Equal(t, []string{"stash-1-1", "stash-1-2"}, overlapErr.MissingCheckpoints)
+
+	result, err := NewPauser(fs, missionPath, mockGit).Restore(t.Context(), "stash-1", RestoreOptions{Replay: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stash-1-1", "stash-1-2"}, result.Replayed)
+	assert.Equal(t, []string{"stash-1-1", "stash-1-2", "refs/mission/paused/stash-1"}, mockGit.appliedRefs)
+	assert.Equal(t, map[string]string{
+		"stash-1-1":        "mock-hash",
+		"stash-1-baseline": "mock-hash",
+		"stash-1-2":        "mock-hash",
+	}, mockGit.createdTags)
+}
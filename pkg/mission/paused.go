@@ -0,0 +1,237 @@
+package mission
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// pausedTimeLayout is the timestamp prefix of paused mission file names
+	pausedTimeLayout = "20060102-150405"
+	// pauseManifestSuffix ends the name of the manifest describing a paused mission
+	pauseManifestSuffix = "-pause.json"
+)
+
+// PausedMission is a paused mission as described by its manifest, with a summary
+// read from the paused mission file
+type PausedMission struct {
+	PauseRecord
+	Intent string `json:"intent"`
+	// Status is the status the mission resumes with
+	Status   string        `json:"status"`
+	Progress *PlanProgress `json:"plan_progress,omitempty"`
+	// Content is the paused mission file, only loaded by Show
+	Content string `json:"mission,omitempty"`
+
+	// manifest is the manifest file name, empty for missions paused before manifests existed
+	manifest string
+	mission  *Mission
+}
+
+// PausedDir returns the directory holding paused missions
+func (p *Pauser) PausedDir() string {
+	return filepath.Join(p.RootDir(), "paused")
+}
+
+// List returns all paused missions, most recently paused first
+func (p *Pauser) List() ([]PausedMission, error) {
+	pausedDir := p.PausedDir()
+	infos, err := afero.ReadDir(p.FS(), pausedDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading paused directory: %w", err)
+	}
+
+	var paused []PausedMission
+	claimed := make(map[string]bool)
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, pauseManifestSuffix) {
+			continue
+		}
+		record, err := p.readRecord(filepath.Join(pausedDir, name))
+		if err != nil {
+			return nil, err
+		}
+		// Manifests written before they named the paused files share their prefix
+		if record.MissionFile == "" {
+			prefix := strings.TrimSuffix(name, pauseManifestSuffix)
+			record.MissionFile = prefix + "-mission.md"
+			record.LogFile = p.existingFile(prefix + "-execution.log")
+		}
+		claimed[record.MissionFile] = true
+		paused = append(paused, p.describe(*record, name))
+	}
+
+	// Missions paused before manifests existed only have timestamped files
+	for _, info := range infos {
+		if info.IsDir() || claimed[info.Name()] {
+			continue
+		}
+		if record, ok := p.legacyRecord(info.Name()); ok {
+			paused = append(paused, p.describe(record, ""))
+		}
+	}
+
+	sort.SliceStable(paused, func(i, j int) bool {
+		return paused[i].PausedAt.After(paused[j].PausedAt)
+	})
+	return paused, nil
+}
+
+// Show returns the most recently paused mission with the given ID, including its content
+func (p *Pauser) Show(missionID string) (*PausedMission, error) {
+	paused, err := p.find(missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := afero.ReadFile(p.FS(), filepath.Join(p.PausedDir(), paused.MissionFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading paused mission: %w", err)
+	}
+	paused.Content = string(data)
+	return paused, nil
+}
+
+// Drop discards the most recently paused mission with the given ID along with its
+// stashed code changes
+func (p *Pauser) Drop(ctx context.Context, missionID string) (*PausedMission, error) {
+	if missionID == "" {
+		return nil, fmt.Errorf("mission ID is required")
+	}
+	paused, err := p.find(missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if paused.StashRef != "" {
+		if p.git == nil {
+			return nil, fmt.Errorf("paused mission has stashed code changes in %s; dropping requires git", paused.StashRef)
+		}
+		if err := p.git.DeleteRef(ctx, paused.StashRef); err != nil {
+			return nil, fmt.Errorf("deleting stash ref: %w", err)
+		}
+	}
+
+	if err := p.removePaused(paused); err != nil {
+		return nil, err
+	}
+	return paused, nil
+}
+
+// find returns the most recently paused mission with missionID, or the most recent
+// paused mission if missionID is empty
+func (p *Pauser) find(missionID string) (*PausedMission, error) {
+	paused, err := p.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(paused) == 0 {
+		return nil, fmt.Errorf("no paused missions found")
+	}
+
+	for i := range paused {
+		if missionID == "" || paused[i].MissionID == missionID {
+			return &paused[i], nil
+		}
+	}
+	return nil, fmt.Errorf("paused mission with ID %s not found", missionID)
+}
+
+// removePaused deletes the files of a paused mission, the manifest last
+func (p *Pauser) removePaused(paused *PausedMission) error {
+	pausedDir := p.PausedDir()
+	if err := p.FS().Remove(filepath.Join(pausedDir, paused.MissionFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing paused mission file: %w", err)
+	}
+	if paused.LogFile != "" {
+		if err := p.FS().Remove(filepath.Join(pausedDir, paused.LogFile)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing paused log file: %w", err)
+		}
+	}
+	if paused.manifest != "" {
+		if err := p.FS().Remove(filepath.Join(pausedDir, paused.manifest)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing pause record: %w", err)
+		}
+	}
+	return nil
+}
+
+// describe summarizes the paused mission file of record. Unreadable missions are
+// still listed so they can be shown or dropped.
+func (p *Pauser) describe(record PauseRecord, manifest string) PausedMission {
+	paused := PausedMission{PauseRecord: record, manifest: manifest}
+	m, err := NewReader(p.FS(), filepath.Join(p.PausedDir(), record.MissionFile)).Read()
+	if err != nil {
+		return paused
+	}
+	paused.mission = m
+	if paused.MissionID == "" {
+		paused.MissionID = m.ID
+	}
+	paused.Intent = summarizeIntent(m.GetIntent())
+	paused.Status = m.Status
+	if from := m.PausedFrom(); from != "" {
+		paused.Status = from
+	}
+	paused.Progress = m.PlanProgress()
+	return paused
+}
+
+// legacyRecord builds a record for a TIMESTAMP-MISSIONID-mission.md file paused
+// without a manifest. Other file names are not paused missions.
+func (p *Pauser) legacyRecord(name string) (PauseRecord, bool) {
+	prefix, ok := strings.CutSuffix(name, "-mission.md")
+	if !ok || len(prefix) <= len(pausedTimeLayout)+1 || prefix[len(pausedTimeLayout)] != '-' {
+		return PauseRecord{}, false
+	}
+	pausedAt, err := time.ParseInLocation(pausedTimeLayout, prefix[:len(pausedTimeLayout)], time.Local)
+	if err != nil {
+		return PauseRecord{}, false
+	}
+
+	record := PauseRecord{
+		MissionID:   prefix[len(pausedTimeLayout)+1:],
+		PausedAt:    pausedAt.UTC(),
+		MissionFile: name,
+		LogFile:     p.existingFile(prefix + "-execution.log"),
+	}
+	// The ID in the mission itself is authoritative over the one in the file name
+	if id, err := NewReader(p.FS(), filepath.Join(p.PausedDir(), name)).GetMissionID(); err == nil && id != "" {
+		record.MissionID = id
+	}
+	return record, true
+}
+
+// existingFile returns name if it exists in the paused directory, or ""
+func (p *Pauser) existingFile(name string) string {
+	if exists, _ := afero.Exists(p.FS(), filepath.Join(p.PausedDir(), name)); exists {
+		return name
+	}
+	return ""
+}
+
+// pausedFileSafe replaces characters that are unsafe in file names so any mission
+// ID can be used in a paused file name. The manifest keeps the real ID.
+func pausedFileSafe(id string) string {
+	if id == "" {
+		return "mission"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -0,0 +1,138 @@
+package mission
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pauseMission writes an active mission with the given ID and pauses it
+func pauseMission(t *testing.T, fs afero.Fs, id string, gitClient git.GitClient) {
+	t.Helper()
+	missionPath := filepath.Join(".mission", "mission.md")
+	content := `---
+id: ` + id + `
+status: active
+---
+
+## INTENT
+Work on ` + id + `
+Second line
+
+## SCOPE
+pkg/a.go
+
+## PLAN
+- [x] First step
+- [ ] Second step
+`
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(content), 0644))
+	_, err := NewPauser(fs, missionPath, gitClient).Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+}
+
+func TestPauser_List(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	pauser := NewPauser(fs, missionPath, nil)
+
+	paused, err := pauser.List()
+	require.NoError(t, err)
+	assert.Empty(t, paused, "no paused directory means nothing is paused")
+
+	// IDs that do not fit the old TIMESTAMP-ID file name convention
+	pauseMission(t, fs, "feature/login", nil)
+
+	// A mission paused before manifests existed
+	legacy := `---
+id: legacy-1
+status: planned
+---
+
+## INTENT
+Legacy work
+`
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(".mission", "paused", "20240101-120000-legacy-1-mission.md"), []byte(legacy), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(".mission", "paused", "notes.md"), []byte("not a mission"), 0644))
+
+	paused, err = pauser.List()
+	require.NoError(t, err)
+	require.Len(t, paused, 2)
+
+	assert.Equal(t, "feature/login", paused[0].MissionID)
+	assert.Equal(t, "Work on feature/login", paused[0].Intent)
+	assert.Equal(t, "active", paused[0].Status)
+	require.NotNil(t, paused[0].Progress)
+	assert.Equal(t, 1, paused[0].Progress.Done)
+	assert.Equal(t, 2, paused[0].Progress.Total)
+	assert.Contains(t, paused[0].MissionFile, "-feature_login-mission.md")
+
+	assert.Equal(t, "legacy-1", paused[1].MissionID)
+	assert.Equal(t, "planned", paused[1].Status)
+	assert.Equal(t, 2024, paused[1].PausedAt.Year())
+}
+
+func TestPauser_Restore_ByManifestID(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	pauseMission(t, fs, "feature/login", nil)
+	pauseMission(t, fs, "x", nil)
+
+	result, err := NewPauser(fs, missionPath, nil).Restore(t.Context(), "feature/login", RestoreOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "feature/login", result.MissionID)
+
+	id, err := NewReader(fs, missionPath).GetMissionID()
+	require.NoError(t, err)
+	assert.Equal(t, "feature/login", id)
+
+	paused, err := NewPauser(fs, missionPath, nil).List()
+	require.NoError(t, err)
+	require.Len(t, paused, 1)
+	assert.Equal(t, "x", paused[0].MissionID)
+}
+
+func TestPauser_Show(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	pauseMission(t, fs, "show-1", nil)
+
+	paused, err := NewPauser(fs, missionPath, nil).Show("show-1")
+	require.NoError(t, err)
+	assert.Contains(t, paused.Content, "## INTENT")
+
+	_, err = NewPauser(fs, missionPath, nil).Show("missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "paused mission with ID missing not found")
+}
+
+func TestPauser_Drop(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	mockGit := &MockGitClient{
+		tags:         map[string]string{"HEAD": "base-hash"},
+		changedFiles: []git.FileChange{{Path: "pkg/a.go", Change: git.ChangeModified}},
+	}
+	pauseMission(t, fs, "drop-1", mockGit)
+
+	_, err := NewPauser(fs, missionPath, nil).Drop(t.Context(), "drop-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dropping requires git")
+
+	dropped, err := NewPauser(fs, missionPath, mockGit).Drop(t.Context(), "drop-1")
+	require.NoError(t, err)
+	assert.Equal(t, "refs/mission/paused/drop-1", dropped.StashRef)
+	assert.Equal(t, []string{"refs/mission/paused/drop-1"}, mockGit.deletedRefs)
+
+	files, err := afero.ReadDir(fs, filepath.Join(".mission", "paused"))
+	require.NoError(t, err)
+	assert.Empty(t, files, "paused files and manifest should be removed")
+
+	exists, err := afero.Exists(fs, missionPath)
+	require.NoError(t, err)
+	assert.False(t, exists, "dropping must not restore the mission")
+}
@@ -1,196 +1,384 @@
 package mission
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dnatag/mission-toolkit/pkg/git"
 	"github.com/dnatag/mission-toolkit/pkg/utils"
 	"github.com/spf13/afero"
 )
 
+// PausedStashRefPrefix is the ref namespace holding code changes of paused missions.
+// Keeping them out of refs/stash leaves the user's stash list alone.
+const PausedStashRefPrefix = "refs/mission/paused/"
+
 // Pauser handles pausing and restoring missions
 type Pauser struct {
 	*BaseService
-	reader *Reader
+	reader  *Reader
+	git     git.GitClient
+	command string // CLI command recorded in status history
+}
+
+// PauseOptions controls which working tree changes Pause stashes
+type PauseOptions struct {
+	// AllChanges stashes every working tree change instead of only files in SCOPE
+	AllChanges bool
+}
+
+// PauseRecord is the manifest saved next to a paused mission. It names the paused
+// files, so mission IDs never have to be recovered from file names.
+type PauseRecord struct {
+	MissionID   string    `json:"mission_id"`
+	PausedAt    time.Time `json:"paused_at"`
+	MissionFile string    `json:"mission_file"`
+	LogFile     string    `json:"log_file,omitempty"`
+	BaseCommit  string    `json:"base_commit,omitempty"`
+	StashRef    string    `json:"stash_ref,omitempty"`
+	StashCommit string    `json:"stash_commit,omitempty"`
+	Files       []string  `json:"files,omitempty"`
+	AllChanges  bool      `json:"all_changes,omitempty"`
+	Checkpoints []string  `json:"checkpoints,omitempty"`
+}
+
+// RestoreResult describes a restored mission and its reapplied code changes
+type RestoreResult struct {
+	MissionID string
+	Record    *PauseRecord
+	// Conflicts lists files left with conflict markers because they also changed since the pause
+	Conflicts []string
+	// HeadMoved reports that HEAD is no longer the commit the changes were stashed on
+	HeadMoved bool
+	// Overlaps lists SCOPE files changed since the pause, restored anyway with --force or --replay
+	Overlaps []ScopeOverlap
+	// Replayed lists the checkpoints recreated on top of HEAD
+	Replayed []string
 }
 
 // NewPauser creates a new Pauser instance for the specified mission file path.
-// The mission directory is derived from the path's directory component.
-func NewPauser(fs afero.Fs, path string) *Pauser {
+// The mission directory is derived from the path's directory component. git may be
+// nil, in which case only the mission files are paused and restored.
+func NewPauser(fs afero.Fs, path string, git git.GitClient) *Pauser {
 	missionDir := filepath.Dir(path)
 	base := NewBaseServiceWithPath(fs, missionDir, path)
 	return &Pauser{
 		BaseService: base,
 		reader:      NewReader(fs, path),
+		git:         git,
 	}
 }
 
+// SetCommand sets the CLI command recorded with the paused status and the status
+// restored on resume.
+func (p *Pauser) SetCommand(command string) {
+	p.command = command
+}
+
 // Pause moves the current mission to .mission/paused/ with timestamp.
-// The paused mission is saved with format: TIMESTAMP-MISSIONID-mission.md
-// along with its execution log if it exists.
-func (p *Pauser) Pause() error {
+// The paused mission is saved as TIMESTAMP-MISSIONID-mission.md along with its
+// execution log if it exists, and described by the TIMESTAMP-MISSIONID-pause.json
+// manifest. The paused copy records status paused in its status history. With a git client, working tree changes to SCOPE files (or all files)
+// are stashed to refs/mission/paused/<id> and the base commit and checkpoint tags
+// are recorded in the manifest.
+func (p *Pauser) Pause(ctx context.Context, opts PauseOptions) (*PauseRecord, error) {
 	missionPath := p.MissionPath()
 
 	// Check if mission exists
 	exists, err := afero.Exists(p.FS(), missionPath)
 	if err != nil {
-		return fmt.Errorf("checking mission existence: %w", err)
+		return nil, fmt.Errorf("checking mission existence: %w", err)
 	}
 
 	if !exists {
-		return fmt.Errorf("no current mission to pause")
+		return nil, fmt.Errorf("no current mission to pause")
 	}
 
 	// Read mission to get ID for naming
 	mission, err := p.reader.Read()
 	if err != nil {
-		return fmt.Errorf("reading mission: %w", err)
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+	if err := ValidateTransition(mission.Status, StatusPaused); err != nil {
+		return nil, err
 	}
 
 	// Create paused directory if it doesn't exist
-	pausedDir := filepath.Join(p.MissionDir(), "paused")
+	pausedDir := p.PausedDir()
 	if err := p.FS().MkdirAll(pausedDir, 0755); err != nil {
-		return fmt.Errorf("creating paused directory: %w", err)
+		return nil, fmt.Errorf("creating paused directory: %w", err)
 	}
 
 	// Generate timestamp for paused mission
-	timestamp := time.Now().Format("20060102-150405")
-	pausedFilename := fmt.Sprintf("%s-%s-mission.md", timestamp, mission.ID)
-	pausedPath := filepath.Join(pausedDir, pausedFilename)
+	now := time.Now()
+	prefix := fmt.Sprintf("%s-%s", now.Format(pausedTimeLayout), pausedFileSafe(mission.ID))
+	pausedPath := filepath.Join(pausedDir, prefix+"-mission.md")
 
-	// Copy mission file to paused directory
+	// Copy mission file to paused directory and mark the copy paused
 	if err := utils.CopyFile(p.FS(), missionPath, pausedPath); err != nil {
-		return fmt.Errorf("copying mission to paused directory: %w", err)
+		return nil, fmt.Errorf("copying mission to paused directory: %w", err)
+	}
+	writer := NewWriter(p.FS(), pausedPath)
+	writer.SetCommand(p.command)
+	if err := writer.UpdateStatus(StatusPaused); err != nil {
+		p.FS().Remove(pausedPath)
+		return nil, fmt.Errorf("recording paused status: %w", err)
 	}
 
 	// Copy execution log if it exists
 	logPath := filepath.Join(p.MissionDir(), "execution.log")
+	pausedLogPath := filepath.Join(pausedDir, prefix+"-execution.log")
 	if exists, _ := afero.Exists(p.FS(), logPath); exists {
-		pausedLogFilename := fmt.Sprintf("%s-%s-execution.log", timestamp, mission.ID)
-		pausedLogPath := filepath.Join(pausedDir, pausedLogFilename)
 		if err := utils.CopyFile(p.FS(), logPath, pausedLogPath); err != nil {
-			return fmt.Errorf("copying execution log: %w", err)
+			return nil, fmt.Errorf("copying execution log: %w", err)
 		}
 	}
 
+	record := &PauseRecord{
+		MissionID:   mission.ID,
+		PausedAt:    now.UTC(),
+		MissionFile: filepath.Base(pausedPath),
+		AllChanges:  opts.AllChanges,
+	}
+	if exists, _ := afero.Exists(p.FS(), pausedLogPath); exists {
+		record.LogFile = filepath.Base(pausedLogPath)
+	}
+
+	// Stash code changes; undo the copies if that fails so the mission stays active
+	if p.git != nil {
+		if err := p.stashChanges(ctx, mission, record); err != nil {
+			p.FS().Remove(pausedPath)
+			p.FS().Remove(pausedLogPath)
+			return nil, fmt.Errorf("stashing working tree changes: %w", err)
+		}
+	}
+	if err := p.writeRecord(filepath.Join(pausedDir, prefix+pauseManifestSuffix), record); err != nil {
+		p.FS().Remove(pausedPath)
+		p.FS().Remove(pausedLogPath)
+		return nil, err
+	}
+
 	// Remove current mission files
 	if err := p.FS().Remove(missionPath); err != nil {
-		return fmt.Errorf("removing current mission: %w", err)
+		return nil, fmt.Errorf("removing current mission: %w", err)
 	}
 
 	if exists, _ := afero.Exists(p.FS(), logPath); exists {
 		if err := p.FS().Remove(logPath); err != nil {
-			return fmt.Errorf("removing execution log: %w", err)
+			return nil, fmt.Errorf("removing execution log: %w", err)
 		}
 	}
 
-	return nil
+	return record, nil
 }
 
-// Restore moves a paused mission back to active state.
-// If missionID is empty, restores the most recently paused mission.
-// If missionID is provided, restores the specific mission with that ID.
-func (p *Pauser) Restore(missionID string) error {
-	pausedDir := filepath.Join(p.MissionDir(), "paused")
+// stashChanges records the base commit and checkpoints and stashes the working tree
+// changes selected by the record. A workspace without commits has nothing to stash.
+func (p *Pauser) stashChanges(ctx context.Context, mission *Mission, record *PauseRecord) error {
+	base, err := p.git.GetTagCommit(ctx, "HEAD")
+	if errors.Is(err, git.ErrNoCommits) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("resolving HEAD: %w", err)
+	}
+	record.BaseCommit = base
 
-	// Check if paused directory exists
-	exists, err := afero.Exists(p.FS(), pausedDir)
+	tags, err := p.git.ListTags(ctx, mission.ID+"-")
 	if err != nil {
-		return fmt.Errorf("checking paused directory: %w", err)
+		return fmt.Errorf("listing checkpoints: %w", err)
 	}
+	record.Checkpoints = missionCheckpointTags(mission.ID, tags)
 
-	if !exists {
-		return fmt.Errorf("no paused missions found")
+	changes, err := p.git.GetChangedFiles(ctx, "HEAD")
+	if err != nil {
+		return fmt.Errorf("listing changed files: %w", err)
+	}
+	scope := NewScope(p.FS(), mission.GetScope())
+	var files []string
+	for _, change := range changes {
+		if isExcludedScopePath(change.Path) || (!record.AllChanges && !scope.Match(change.Path)) {
+			continue
+		}
+		files = append(files, change.Path)
 	}
 
-	// List paused missions
-	files, err := afero.ReadDir(p.FS(), pausedDir)
+	ref := PausedStashRefPrefix + mission.ID
+	hash, err := p.git.StashChanges(ctx, ref, files, fmt.Sprintf("mission pause: %s", mission.ID))
+	if errors.Is(err, git.ErrNoChanges) {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("reading paused directory: %w", err)
-	}
-
-	var missionFile string
-	var logFile string
-
-	if missionID == "" {
-		// Find the most recent paused mission by timestamp
-		var latestTime time.Time
-		for _, file := range files {
-			if filepath.Ext(file.Name()) == ".md" {
-				// Extract timestamp from filename (YYYYMMDD-HHMMSS format)
-				if len(file.Name()) >= 15 {
-					timeStr := file.Name()[:15] // YYYYMMDD-HHMMSS
-					if t, err := time.Parse("20060102-150405", timeStr); err == nil {
-						if t.After(latestTime) {
-							latestTime = t
-							missionFile = file.Name()
-							// Look for corresponding log file
-							logName := timeStr + file.Name()[15:]
-							logName = logName[:len(logName)-10] + "-execution.log" // Replace -mission.md with -execution.log
-							logFile = logName
-						}
-					}
-				}
-			}
+		return err
+	}
+
+	record.StashRef = ref
+	record.StashCommit = hash
+	record.Files = files
+	return nil
+}
+
+// writeRecord saves pause metadata as JSON
+func (p *Pauser) writeRecord(path string, record *PauseRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding pause record: %w", err)
+	}
+	if err := afero.WriteFile(p.FS(), path, data, 0644); err != nil {
+		return fmt.Errorf("writing pause record: %w", err)
+	}
+	return nil
+}
+
+// readRecord loads a pause manifest
+func (p *Pauser) readRecord(path string) (*PauseRecord, error) {
+	data, err := afero.ReadFile(p.FS(), path)
+	if err != nil {
+		return nil, fmt.Errorf("reading pause record: %w", err)
+	}
+	var record PauseRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("parsing pause record %s: %w", filepath.Base(path), err)
+	}
+	return &record, nil
+}
+
+// missionCheckpointTags filters tags to the checkpoint and baseline tags of missionID
+func missionCheckpointTags(missionID string, tags []string) []string {
+	var checkpoints []string
+	for _, tag := range tags {
+		suffix := strings.TrimPrefix(tag, missionID+"-")
+		if suffix == "baseline" {
+			checkpoints = append(checkpoints, tag)
+			continue
 		}
-	} else {
-		// Find specific mission by ID
-		for _, file := range files {
-			if filepath.Ext(file.Name()) == ".md" && len(file.Name()) > 16 {
-				// Extract mission ID from filename: TIMESTAMP-MISSIONID-mission.md
-				parts := file.Name()[16:] // Remove timestamp part
-				if len(parts) > 11 && parts[len(parts)-11:] == "-mission.md" {
-					extractedID := parts[:len(parts)-11] // Remove -mission.md suffix
-					if extractedID == missionID {
-						missionFile = file.Name()
-						// Look for corresponding log file
-						logFile = file.Name()[:len(file.Name())-11] + "-execution.log"
-						break
-					}
-				}
-			}
+		if _, err := strconv.Atoi(suffix); err == nil {
+			checkpoints = append(checkpoints, tag)
 		}
 	}
+	return checkpoints
+}
 
-	if missionFile == "" {
-		if missionID == "" {
-			return fmt.Errorf("no paused missions found")
-		}
-		return fmt.Errorf("paused mission with ID %s not found", missionID)
+// Restore moves a paused mission back to active state.
+// If missionID is empty, restores the most recently paused mission.
+// If missionID is provided, restores the most recently paused mission with that ID.
+// Stashed code changes are reapplied with a three-way merge; files that also
+// changed since the pause are left with conflict markers and reported.
+// If SCOPE files changed since the pause or checkpoints are no longer in the
+// history of HEAD, Restore returns a *ScopeOverlapError unless opts allow it.
+func (p *Pauser) Restore(ctx context.Context, missionID string, opts RestoreOptions) (*RestoreResult, error) {
+	paused, err := p.find(missionID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if current mission exists
-	currentMissionPath := filepath.Join(p.MissionDir(), "mission.md")
+	currentMissionPath := p.MissionPath()
 	if exists, _ := afero.Exists(p.FS(), currentMissionPath); exists {
-		return fmt.Errorf("current mission exists, pause it first before restoring")
+		return nil, fmt.Errorf("current mission exists, pause it first before restoring")
 	}
 
-	// Restore mission file
-	pausedMissionPath := filepath.Join(pausedDir, missionFile)
-	if err := utils.CopyFile(p.FS(), pausedMissionPath, currentMissionPath); err != nil {
-		return fmt.Errorf("restoring mission file: %w", err)
+	overlaps, missing, err := p.checkRestore(ctx, paused)
+	if err != nil {
+		return nil, err
+	}
+	if (len(overlaps) > 0 || len(missing) > 0) && !opts.Force && !opts.Replay {
+		conflict := &ScopeOverlapError{MissionID: paused.MissionID, Overlaps: overlaps}
+		for _, cp := range missing {
+			conflict.MissingCheckpoints = append(conflict.MissingCheckpoints, cp.tag)
+		}
+		return nil, conflict
+	}
+
+	var replayed []string
+	if opts.Replay && len(missing) > 0 {
+		if replayed, err = p.replayCheckpoints(ctx, &paused.PauseRecord, missing); err != nil {
+			return nil, err
+		}
+	}
+
+	// Reapply stashed code changes before touching the mission files
+	result, err := p.applyStash(ctx, &paused.PauseRecord)
+	if err != nil {
+		return nil, err
+	}
+	result.Overlaps = overlaps
+	result.Replayed = replayed
+
+	// Restore mission file with the status it was paused in
+	pausedDir := p.PausedDir()
+	if err := utils.CopyFile(p.FS(), filepath.Join(pausedDir, paused.MissionFile), currentMissionPath); err != nil {
+		return nil, fmt.Errorf("restoring mission file: %w", err)
+	}
+	if err := p.resumeStatus(paused, currentMissionPath); err != nil {
+		return nil, err
 	}
 
 	// Restore log file if it exists
-	pausedLogPath := filepath.Join(pausedDir, logFile)
-	if exists, _ := afero.Exists(p.FS(), pausedLogPath); exists {
-		currentLogPath := filepath.Join(p.MissionDir(), "execution.log")
-		if err := utils.CopyFile(p.FS(), pausedLogPath, currentLogPath); err != nil {
-			return fmt.Errorf("restoring execution log: %w", err)
+	if paused.LogFile != "" {
+		pausedLogPath := filepath.Join(pausedDir, paused.LogFile)
+		if exists, _ := afero.Exists(p.FS(), pausedLogPath); exists {
+			currentLogPath := filepath.Join(p.MissionDir(), "execution.log")
+			if err := utils.CopyFile(p.FS(), pausedLogPath, currentLogPath); err != nil {
+				return nil, fmt.Errorf("restoring execution log: %w", err)
+			}
 		}
 	}
 
 	// Remove paused files
-	if err := p.FS().Remove(pausedMissionPath); err != nil {
-		return fmt.Errorf("removing paused mission file: %w", err)
+	if err := p.removePaused(paused); err != nil {
+		return nil, err
 	}
 
-	if exists, _ := afero.Exists(p.FS(), pausedLogPath); exists {
-		if err := p.FS().Remove(pausedLogPath); err != nil {
-			return fmt.Errorf("removing paused log file: %w", err)
-		}
+	if result.MissionID == "" {
+		result.MissionID, _ = NewReader(p.FS(), currentMissionPath).GetMissionID()
 	}
+	return result, nil
+}
 
+// resumeStatus moves a restored mission from paused back to the status it had when it
+// was paused. Missions paused without recording the status are left as they are.
+func (p *Pauser) resumeStatus(paused *PausedMission, path string) error {
+	if paused.mission == nil || !IsValidStatus(paused.mission.PausedFrom()) {
+		return nil
+	}
+	writer := NewWriter(p.FS(), path)
+	writer.SetCommand(p.command)
+	if err := writer.UpdateStatus(paused.mission.PausedFrom()); err != nil {
+		return fmt.Errorf("restoring mission status: %w", err)
+	}
 	return nil
 }
+
+// applyStash reapplies the code changes recorded in the pause record
+func (p *Pauser) applyStash(ctx context.Context, record *PauseRecord) (*RestoreResult, error) {
+	result := &RestoreResult{MissionID: record.MissionID, Record: record}
+	if record.StashRef == "" {
+		return result, nil
+	}
+	if p.git == nil {
+		return nil, fmt.Errorf("paused mission has stashed code changes in %s; restoring requires git", record.StashRef)
+	}
+
+	if head, err := p.git.GetTagCommit(ctx, "HEAD"); err == nil && head != record.BaseCommit {
+		result.HeadMoved = true
+	}
+
+	conflicts, err := p.git.ApplyStash(ctx, record.StashRef)
+	if err != nil {
+		return nil, fmt.Errorf("reapplying stashed changes: %w", err)
+	}
+	result.Conflicts = conflicts
+
+	// Conflicting files keep both versions in their markers, so the ref is no longer needed
+	if err := p.git.DeleteRef(ctx, record.StashRef); err != nil {
+		return nil, fmt.Errorf("deleting stash ref: %w", err)
+	}
+	return result, nil
+}
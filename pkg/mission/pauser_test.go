@@ -1,10 +1,13 @@
 package mission
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 
+	"github.com/dnatag/mission-toolkit/pkg/git"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,8 +41,8 @@ test.go`
 	require.NoError(t, err)
 
 	// Pause mission
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Pause()
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Pause(t.Context(), PauseOptions{})
 	require.NoError(t, err)
 
 	// Verify mission file was removed
@@ -61,10 +64,10 @@ test.go`
 	// Verify paused files exist
 	files, err := afero.ReadDir(fs, pausedDir)
 	require.NoError(t, err)
-	require.Len(t, files, 2, "should have 2 paused files")
+	require.Len(t, files, 3, "should have paused mission, log and manifest")
 
 	// Check that files have correct naming pattern
-	var foundMission, foundLog bool
+	var foundMission, foundLog, foundManifest bool
 	for _, file := range files {
 		name := file.Name()
 		if len(name) > 15 && name[15:] == "-"+missionID+"-mission.md" {
@@ -73,9 +76,13 @@ test.go`
 		if len(name) > 15 && name[15:] == "-"+missionID+"-execution.log" {
 			foundLog = true
 		}
+		if len(name) > 15 && name[15:] == "-"+missionID+"-pause.json" {
+			foundManifest = true
+		}
 	}
 	require.True(t, foundMission, "paused mission file should exist")
 	require.True(t, foundLog, "paused log file should exist")
+	require.True(t, foundManifest, "pause manifest should exist")
 }
 
 func TestPauser_Pause_NoMission(t *testing.T) {
@@ -85,12 +92,57 @@ func TestPauser_Pause_NoMission(t *testing.T) {
 	err := fs.MkdirAll(missionDir, 0755)
 	require.NoError(t, err)
 
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Pause()
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Pause(t.Context(), PauseOptions{})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "no current mission to pause")
 }
 
+func TestPauser_PauseAndRestore_Status(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeTestMission(t, fs, "---\nid: status-1\nstatus: active\n---\n\n## INTENT\nTest\n")
+
+	pauser := NewPauser(fs, ".mission/mission.md", nil)
+	pauser.SetCommand("m mission pause")
+	record, err := pauser.Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+
+	paused, err := NewReader(fs, filepath.Join(".mission/paused", record.MissionFile)).Read()
+	require.NoError(t, err)
+	assert.Equal(t, StatusPaused, paused.Status)
+	require.Len(t, paused.StatusHistory, 1)
+	assert.Equal(t, StatusActive, paused.StatusHistory[0].From)
+	assert.Equal(t, StatusPaused, paused.StatusHistory[0].To)
+	assert.Equal(t, "m mission pause", paused.StatusHistory[0].Command)
+
+	list, err := pauser.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, StatusActive, list[0].Status, "paused missions are listed with the status they resume with")
+
+	pauser.SetCommand("m mission restore")
+	_, err = pauser.Restore(t.Context(), "status-1", RestoreOptions{})
+	require.NoError(t, err)
+
+	restored, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, StatusActive, restored.Status)
+	require.Len(t, restored.StatusHistory, 2)
+	assert.Equal(t, StatusTransition{From: StatusPaused, To: StatusActive, At: restored.StatusHistory[1].At, Command: "m mission restore"}, restored.StatusHistory[1])
+}
+
+func TestPauser_Pause_CompletedMission(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeTestMission(t, fs, "---\nid: done-1\nstatus: completed\n---\n\n## INTENT\nTest\n")
+
+	_, err := NewPauser(fs, ".mission/mission.md", nil).Pause(t.Context(), PauseOptions{})
+	assert.ErrorContains(t, err, `cannot change status from "completed" to "paused"`)
+	exists, _ := afero.Exists(fs, ".mission/mission.md")
+	assert.True(t, exists, "the mission stays active")
+	exists, _ = afero.Exists(fs, ".mission/paused")
+	assert.False(t, exists, "nothing is paused")
+}
+
 func TestPauser_Restore(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	missionDir := ".mission"
@@ -120,8 +172,8 @@ Test restore mission`
 	require.NoError(t, err)
 
 	// Restore mission
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Restore(missionID)
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Restore(t.Context(), missionID, RestoreOptions{})
 	require.NoError(t, err)
 
 	// Verify mission file was restored
@@ -175,8 +227,8 @@ func TestPauser_Restore_NoMissionID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Restore without specifying mission ID (should restore most recent)
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Restore("")
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Restore(t.Context(), "", RestoreOptions{})
 	require.NoError(t, err)
 
 	// Verify newer mission was restored
@@ -217,8 +269,8 @@ func TestPauser_Restore_CurrentMissionExists(t *testing.T) {
 	require.NoError(t, err)
 
 	// Try to restore (should fail)
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Restore("test")
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Restore(t.Context(), "test", RestoreOptions{})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "current mission exists")
 }
@@ -230,8 +282,8 @@ func TestPauser_Restore_NoPausedMissions(t *testing.T) {
 	err := fs.MkdirAll(missionDir, 0755)
 	require.NoError(t, err)
 
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Restore("")
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Restore(t.Context(), "", RestoreOptions{})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "no paused missions found")
 }
@@ -248,8 +300,8 @@ func TestPauser_Pause_CorruptedMission(t *testing.T) {
 	err = afero.WriteFile(fs, filepath.Join(missionDir, "mission.md"), []byte("invalid yaml"), 0644)
 	require.NoError(t, err)
 
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Pause()
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Pause(t.Context(), PauseOptions{})
 	require.Error(t, err, "Should fail with corrupted mission file")
 }
 
@@ -274,8 +326,8 @@ Body`
 	err = fs.MkdirAll(pausedDir, 0444)
 	require.NoError(t, err)
 
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Pause()
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Pause(t.Context(), PauseOptions{})
 	// Should handle permission errors
 	if err != nil {
 		require.Contains(t, err.Error(), "permission denied")
@@ -296,8 +348,8 @@ func TestPauser_Restore_InvalidMissionID(t *testing.T) {
 	err = afero.WriteFile(fs, filepath.Join(pausedDir, invalidFile), []byte("content"), 0644)
 	require.NoError(t, err)
 
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Restore("")
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Restore(t.Context(), "", RestoreOptions{})
 	require.Error(t, err, "Should fail with invalid mission ID format")
 }
 
@@ -315,8 +367,8 @@ func TestPauser_Restore_CorruptedPausedMission(t *testing.T) {
 	err = afero.WriteFile(fs, filepath.Join(pausedDir, pausedFile), []byte("corrupted yaml"), 0644)
 	require.NoError(t, err)
 
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Restore("")
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Restore(t.Context(), "", RestoreOptions{})
 	require.Error(t, err, "Should fail with corrupted paused mission")
 }
 
@@ -345,8 +397,133 @@ Body 2`
 	err = afero.WriteFile(fs, filepath.Join(pausedDir, "20260118130000-mission.md"), []byte(mission2), 0644)
 	require.NoError(t, err)
 
-	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"))
-	err = pauser.Restore("")
+	pauser := NewPauser(fs, filepath.Join(missionDir, "mission.md"), nil)
+	_, err = pauser.Restore(t.Context(), "", RestoreOptions{})
 	// Should restore the most recent one or prompt for selection
 	require.NoError(t, err, "Should handle multiple paused missions")
 }
+
+const stashMission = `---
+id: stash-1
+status: active
+---
+
+## INTENT
+Stash test
+
+## SCOPE
+pkg/**/*.go
+`
+
+func TestPauser_Pause_StashesScopeChanges(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+
+	mockGit := &MockGitClient{
+		tags: map[string]string{"HEAD": "base-hash"},
+		changedFiles: []git.FileChange{
+			{Path: "pkg/a.go", Change: git.ChangeModified},
+			{Path: "pkg/sub/new.go", Change: git.ChangeAdded},
+			{Path: "README.md", Change: git.ChangeModified},
+			{Path: ".mission/mission.md", Change: git.ChangeModified},
+		},
+	}
+
+	record, err := NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "refs/mission/paused/stash-1", mockGit.stashRef)
+	assert.Equal(t, []string{"pkg/a.go", "pkg/sub/new.go"}, mockGit.stashedFiles)
+	assert.Equal(t, "base-hash", record.BaseCommit)
+	assert.Equal(t, "mock-stash-hash", record.StashCommit)
+
+	// The record is saved next to the paused mission
+	files, err := afero.Glob(fs, filepath.Join(".mission", "paused", "*-stash-1-pause.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := afero.ReadFile(fs, files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"base_commit": "base-hash"`)
+
+	// --all includes files outside SCOPE but never mission artifacts
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+	_, err = NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{AllChanges: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pkg/a.go", "pkg/sub/new.go", "README.md"}, mockGit.stashedFiles)
+}
+
+func TestPauser_Pause_HeadErrors(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+
+	// A repository without commits has nothing to stash
+	mockGit := &MockGitClient{headErr: fmt.Errorf("resolving HEAD: %w", git.ErrNoCommits)}
+	record, err := NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, record.BaseCommit)
+	assert.Empty(t, record.StashRef)
+
+	// Any other failure stops the pause before the mission files are removed
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+	mockGit = &MockGitClient{headErr: fmt.Errorf("git rev-parse: %w", git.ErrTimeout)}
+	_, err = NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{})
+	assert.ErrorIs(t, err, git.ErrTimeout)
+	exists, _ := afero.Exists(fs, missionPath)
+	assert.True(t, exists)
+	paused, err := NewPauser(fs, missionPath, nil).List()
+	require.NoError(t, err)
+	assert.Len(t, paused, 1, "only the first pause was recorded")
+}
+
+func TestPauser_Restore_ReappliesStash(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+
+	mockGit := &MockGitClient{
+		tags:         map[string]string{"HEAD": "base-hash"},
+		changedFiles: []git.FileChange{{Path: "pkg/a.go", Change: git.ChangeModified}},
+	}
+	_, err := NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+
+	// HEAD moved and the file changed meanwhile
+	mockGit.tags["HEAD"] = "new-head"
+	mockGit.stashConflicts = []string{"pkg/a.go"}
+
+	result, err := NewPauser(fs, missionPath, mockGit).Restore(t.Context(), "stash-1", RestoreOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "stash-1", result.MissionID)
+	assert.True(t, result.HeadMoved)
+	assert.Equal(t, []string{"pkg/a.go"}, result.Conflicts)
+	assert.Equal(t, []string{"refs/mission/paused/stash-1"}, mockGit.appliedRefs)
+	assert.Equal(t, []string{"refs/mission/paused/stash-1"}, mockGit.deletedRefs)
+
+	files, err := afero.ReadDir(fs, filepath.Join(".mission", "paused"))
+	require.NoError(t, err)
+	assert.Empty(t, files, "paused files and record should be removed")
+}
+
+func TestPauser_Restore_StashWithoutGit(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	missionPath := filepath.Join(".mission", "mission.md")
+	require.NoError(t, afero.WriteFile(fs, missionPath, []byte(stashMission), 0644))
+
+	mockGit := &MockGitClient{changedFiles: []git.FileChange{{Path: "pkg/a.go", Change: git.ChangeModified}}}
+	_, err := NewPauser(fs, missionPath, mockGit).Pause(t.Context(), PauseOptions{})
+	require.NoError(t, err)
+
+	_, err = NewPauser(fs, missionPath, nil).Restore(t.Context(), "stash-1", RestoreOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "restoring requires git")
+
+	exists, err := afero.Exists(fs, missionPath)
+	require.NoError(t, err)
+	assert.False(t, exists, "a failed restore must not restore the mission files")
+}
+
+func TestMissionCheckpointTags(t *testing.T) {
+	tags := []string{"stash-1-1", "stash-1-baseline", "stash-10-1", "stash-1-notes", "stash-1-2"}
+	assert.Equal(t, []string{"stash-1-1", "stash-1-baseline", "stash-1-2"}, missionCheckpointTags("stash-1", tags))
+}
@@ -0,0 +1,236 @@
+package mission
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plan step statuses
+const (
+	StepPending    = "pending"
+	StepInProgress = "in_progress"
+	StepDone       = "done"
+	StepSkipped    = "skipped"
+	StepFailed     = "failed"
+)
+
+// stepMarkers maps each step status to the checkbox marker rendered in the PLAN section
+var stepMarkers = map[string]string{
+	StepPending:    " ",
+	StepInProgress: "~",
+	StepDone:       "x",
+	StepSkipped:    "-",
+	StepFailed:     "!",
+}
+
+// stepTransitions lists the statuses each step status may move to. Any step can be
+// reset to pending; done and skipped steps must be reset before they can be restarted.
+var stepTransitions = map[string][]string{
+	StepPending:    {StepInProgress, StepDone, StepSkipped, StepFailed},
+	StepInProgress: {StepDone, StepSkipped, StepFailed, StepPending},
+	StepFailed:     {StepInProgress, StepSkipped, StepPending},
+	StepDone:       {StepPending},
+	StepSkipped:    {StepPending},
+}
+
+// planItemPattern matches a checkbox plan line and captures indent, marker and text
+var planItemPattern = regexp.MustCompile(`^(\s*)- \[([ xX~!-])\] (.*)$`)
+
+// stepIDPattern matches generated step IDs
+var stepIDPattern = regexp.MustCompile(`^s(\d+)$`)
+
+// PlanStep tracks the status of a single PLAN item. The ID stays attached to the
+// step text when the plan is edited, so steps can be referenced reliably.
+type PlanStep struct {
+	ID         string     `yaml:"id" json:"id"`
+	Text       string     `yaml:"text" json:"text"`
+	Status     string     `yaml:"status" json:"status"`
+	StartedAt  *time.Time `yaml:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt *time.Time `yaml:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+// PlanProgress summarizes plan step statuses
+type PlanProgress struct {
+	Total      int    `json:"total"`
+	Done       int    `json:"done"`
+	Skipped    int    `json:"skipped"`
+	Failed     int    `json:"failed"`
+	InProgress int    `json:"in_progress"`
+	Summary    string `json:"summary"`
+}
+
+// planItem is a checkbox line found in the PLAN section
+type planItem struct {
+	marker string
+	text   string
+}
+
+// PlanSteps returns the PLAN checkbox items with their IDs and timestamps.
+// The checkbox marker is authoritative for the status, so hand-edited plans stay
+// accurate; IDs and timestamps come from the plan_steps frontmatter, matched by text.
+func (m *Mission) PlanSteps() []PlanStep {
+	items := parsePlanItems(extractSection(m.Body, "PLAN"))
+	if len(items) == 0 {
+		return nil
+	}
+
+	next := 1
+	for _, stored := range m.Steps {
+		if match := stepIDPattern.FindStringSubmatch(stored.ID); match != nil {
+			if n, _ := strconv.Atoi(match[1]); n >= next {
+				next = n + 1
+			}
+		}
+	}
+
+	used := make(map[int]bool)
+	steps := make([]PlanStep, 0, len(items))
+	for _, item := range items {
+		step := PlanStep{Text: item.text}
+		for j, stored := range m.Steps {
+			if !used[j] && stored.Text == item.text {
+				used[j] = true
+				step = stored
+				break
+			}
+		}
+		if step.ID == "" {
+			step.ID = fmt.Sprintf("s%d", next)
+			next++
+		}
+		step.Status = stepStatusForMarker(item.marker)
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// PlanProgress summarizes the plan, e.g. "step 3/7 in progress"
+func (m *Mission) PlanProgress() *PlanProgress {
+	steps := m.PlanSteps()
+	if len(steps) == 0 {
+		return nil
+	}
+
+	p := &PlanProgress{Total: len(steps)}
+	current, failed := 0, 0
+	for i, step := range steps {
+		switch step.Status {
+		case StepDone:
+			p.Done++
+		case StepSkipped:
+			p.Skipped++
+		case StepFailed:
+			p.Failed++
+			if failed == 0 {
+				failed = i + 1
+			}
+		case StepInProgress:
+			p.InProgress++
+			if current == 0 {
+				current = i + 1
+			}
+		}
+	}
+
+	switch {
+	case current > 0:
+		p.Summary = fmt.Sprintf("step %d/%d in progress", current, p.Total)
+	case failed > 0:
+		p.Summary = fmt.Sprintf("step %d/%d failed", failed, p.Total)
+	case p.Done+p.Skipped == p.Total:
+		p.Summary = fmt.Sprintf("all %d steps complete", p.Total)
+	default:
+		p.Summary = fmt.Sprintf("%d/%d steps complete", p.Done+p.Skipped, p.Total)
+	}
+	return p
+}
+
+// ValidateStepTransition checks whether a plan step may move from one status to another
+func ValidateStepTransition(from, to string) error {
+	if _, ok := stepMarkers[to]; !ok {
+		return fmt.Errorf("unknown step status %q", to)
+	}
+	if from == to {
+		return nil
+	}
+	for _, allowed := range stepTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot change step status from %s to %s", from, to)
+}
+
+// findStep resolves a step reference given as an ID (s3) or a 1-based position (3)
+func findStep(steps []PlanStep, ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	for i, step := range steps {
+		if step.ID == ref {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(steps) {
+		return n - 1, nil
+	}
+	return -1, fmt.Errorf("step %s not found (total steps: %d)", ref, len(steps))
+}
+
+// applyStepStatus updates status and timestamps of step for a transition at now
+func applyStepStatus(step *PlanStep, status string, now time.Time) {
+	step.Status = status
+	switch status {
+	case StepPending:
+		step.StartedAt = nil
+		step.FinishedAt = nil
+	case StepInProgress:
+		step.StartedAt = &now
+		step.FinishedAt = nil
+	default:
+		step.FinishedAt = &now
+	}
+}
+
+// renderPlanMarker rewrites the checkbox marker of the index-th plan item
+func renderPlanMarker(planContent string, index int, status string) string {
+	lines := strings.Split(planContent, "\n")
+	count := 0
+	for i, line := range lines {
+		match := planItemPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if count == index {
+			lines[i] = fmt.Sprintf("%s- [%s] %s", match[1], stepMarkers[status], match[3])
+			break
+		}
+		count++
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parsePlanItems returns the checkbox items of a PLAN section in order
+func parsePlanItems(planContent string) []planItem {
+	var items []planItem
+	for _, line := range strings.Split(planContent, "\n") {
+		if match := planItemPattern.FindStringSubmatch(line); match != nil {
+			items = append(items, planItem{marker: match[2], text: strings.TrimSpace(match[3])})
+		}
+	}
+	return items
+}
+
+// stepStatusForMarker maps a checkbox marker back to a step status
+func stepStatusForMarker(marker string) string {
+	if marker == "X" {
+		return StepDone
+	}
+	for status, m := range stepMarkers {
+		if m == marker {
+			return status
+		}
+	}
+	return StepPending
+}
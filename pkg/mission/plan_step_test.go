@@ -0,0 +1,166 @@
+package mission
+
+import (
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/logger"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const planStepMission = `---
+id: step-1
+status: active
+---
+
+## INTENT
+Test
+
+## PLAN
+- [ ] First step
+- [ ] Second step
+- [ ] Third step
+`
+
+func newStepWriter(t *testing.T) (afero.Fs, *Writer) {
+	fs := afero.NewMemMapFs()
+	writeTestMission(t, fs, planStepMission)
+	writer := NewWriter(fs, ".mission/mission.md")
+	writer.loggerConfig = &logger.Config{Output: logger.OutputConsole}
+	return fs, writer
+}
+
+func readSteps(t *testing.T, fs afero.Fs) *Mission {
+	m, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	return m
+}
+
+func TestMission_PlanSteps(t *testing.T) {
+	m := &Mission{Body: "## PLAN\n- [x] Done\n- [~] Working\n- [-] Skipped\n- [!] Broken\n- [ ] Todo\n- [X] Upper\n\n## VERIFICATION\ngo test"}
+
+	steps := m.PlanSteps()
+	require.Len(t, steps, 6)
+	assert.Equal(t, "s1", steps[0].ID)
+	assert.Equal(t, "Done", steps[0].Text)
+
+	var statuses []string
+	for _, step := range steps {
+		statuses = append(statuses, step.Status)
+	}
+	assert.Equal(t, []string{StepDone, StepInProgress, StepSkipped, StepFailed, StepPending, StepDone}, statuses)
+
+	progress := m.PlanProgress()
+	assert.Equal(t, 6, progress.Total)
+	assert.Equal(t, "step 2/6 in progress", progress.Summary)
+}
+
+func TestMission_PlanSteps_StableIDs(t *testing.T) {
+	m := &Mission{
+		Body: "## PLAN\n- [ ] New first\n- [x] Original\n- [ ] Another",
+		Steps: []PlanStep{
+			{ID: "s1", Text: "Original", Status: StepDone},
+			{ID: "s2", Text: "Removed", Status: StepPending},
+		},
+	}
+
+	steps := m.PlanSteps()
+	require.Len(t, steps, 3)
+	assert.Equal(t, "s3", steps[0].ID)
+	assert.Equal(t, "s1", steps[1].ID)
+	assert.Equal(t, "s4", steps[2].ID)
+}
+
+func TestMission_PlanProgress(t *testing.T) {
+	tests := []struct {
+		plan string
+		want string
+	}{
+		{"- [ ] A\n- [ ] B", "0/2 steps complete"},
+		{"- [x] A\n- [ ] B", "1/2 steps complete"},
+		{"- [x] A\n- [!] B", "step 2/2 failed"},
+		{"- [x] A\n- [-] B", "all 2 steps complete"},
+	}
+	for _, tt := range tests {
+		m := &Mission{Body: "## PLAN\n" + tt.plan}
+		assert.Equal(t, tt.want, m.PlanProgress().Summary)
+	}
+
+	assert.Nil(t, (&Mission{Body: "## INTENT\nNo plan"}).PlanProgress())
+}
+
+func TestValidateStepTransition(t *testing.T) {
+	assert.NoError(t, ValidateStepTransition(StepPending, StepInProgress))
+	assert.NoError(t, ValidateStepTransition(StepInProgress, StepDone))
+	assert.NoError(t, ValidateStepTransition(StepFailed, StepInProgress))
+	assert.NoError(t, ValidateStepTransition(StepDone, StepPending))
+	assert.NoError(t, ValidateStepTransition(StepDone, StepDone))
+
+	assert.Error(t, ValidateStepTransition(StepDone, StepInProgress))
+	assert.Error(t, ValidateStepTransition(StepSkipped, StepFailed))
+	assert.Error(t, ValidateStepTransition(StepPending, "bogus"))
+}
+
+func TestWriter_UpdateStepStatus(t *testing.T) {
+	fs, writer := newStepWriter(t)
+
+	require.NoError(t, writer.UpdateStepStatus("2", StepInProgress, ""))
+	m := readSteps(t, fs)
+	assert.Contains(t, m.Body, "- [~] Second step")
+	steps := m.PlanSteps()
+	assert.Equal(t, "s2", steps[1].ID)
+	require.NotNil(t, steps[1].StartedAt)
+	assert.Nil(t, steps[1].FinishedAt)
+	assert.Equal(t, "step 2/3 in progress", m.PlanProgress().Summary)
+
+	require.NoError(t, writer.UpdateStepStatus("s2", StepDone, "finished"))
+	m = readSteps(t, fs)
+	assert.Contains(t, m.Body, "- [x] Second step")
+	steps = m.PlanSteps()
+	require.NotNil(t, steps[1].StartedAt)
+	require.NotNil(t, steps[1].FinishedAt)
+
+	require.NoError(t, writer.UpdateStepStatus("1", StepSkipped, ""))
+	require.NoError(t, writer.UpdateStepStatus("3", StepFailed, ""))
+	m = readSteps(t, fs)
+	assert.Contains(t, m.Body, "- [-] First step")
+	assert.Contains(t, m.Body, "- [!] Third step")
+
+	require.NoError(t, writer.UpdateStepStatus("s2", StepPending, ""))
+	m = readSteps(t, fs)
+	assert.Contains(t, m.Body, "- [ ] Second step")
+	steps = m.PlanSteps()
+	assert.Nil(t, steps[1].StartedAt)
+	assert.Nil(t, steps[1].FinishedAt)
+}
+
+func TestWriter_UpdateStepStatus_InvalidTransition(t *testing.T) {
+	_, writer := newStepWriter(t)
+
+	require.NoError(t, writer.UpdateStepStatus("1", StepDone, ""))
+	err := writer.UpdateStepStatus("1", StepInProgress, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot change step status from done to in_progress")
+
+	err = writer.UpdateStepStatus("s9", StepDone, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "step s9 not found (total steps: 3)")
+}
+
+func TestWriter_UpdateList_PlanAppendKeepsStepState(t *testing.T) {
+	fs, writer := newStepWriter(t)
+
+	require.NoError(t, writer.UpdateStepStatus("1", StepDone, ""))
+	require.NoError(t, writer.UpdateList("plan", []string{"Fourth step"}, true))
+
+	m := readSteps(t, fs)
+	assert.Contains(t, m.Body, "- [x] First step")
+	assert.Contains(t, m.Body, "- [ ] Fourth step")
+
+	steps := m.PlanSteps()
+	require.Len(t, steps, 4)
+	assert.Equal(t, "s1", steps[0].ID)
+	assert.Equal(t, "s4", steps[3].ID)
+	require.Len(t, m.Steps, 4, "step IDs should be recorded in frontmatter")
+}
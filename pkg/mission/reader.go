@@ -3,6 +3,7 @@ package mission
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/dnatag/mission-toolkit/pkg/md"
@@ -46,20 +47,7 @@ func (r *Reader) parse(data []byte) (*Mission, error) {
 		if err != nil {
 			return nil, fmt.Errorf("parsing frontmatter: %w", err)
 		}
-
-		// Convert frontmatter map to Mission struct via YAML marshaling
-		// This ensures type conversions are handled correctly
-		yamlData, err := yaml.Marshal(doc.Frontmatter)
-		if err != nil {
-			return nil, fmt.Errorf("marshaling frontmatter: %w", err)
-		}
-
-		mission := &Mission{Body: doc.Body}
-		if err := yaml.Unmarshal(yamlData, mission); err != nil {
-			return nil, fmt.Errorf("unmarshaling frontmatter: %w", err)
-		}
-
-		return mission, nil
+		return missionFromDocument(doc)
 	}
 
 	// Check for legacy # MISSION format (including # MISSION ARCHIVE and # MISSION: title)
@@ -70,6 +58,21 @@ func (r *Reader) parse(data []byte) (*Mission, error) {
 	return nil, fmt.Errorf("no frontmatter found in mission file")
 }
 
+// missionFromDocument converts a parsed document to a Mission struct.
+// The frontmatter map is round-tripped through YAML so type conversions are handled correctly.
+func missionFromDocument(doc *md.Document) (*Mission, error) {
+	yamlData, err := yaml.Marshal(doc.Frontmatter)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling frontmatter: %w", err)
+	}
+
+	mission := &Mission{Body: doc.Body}
+	if err := yaml.Unmarshal(yamlData, mission); err != nil {
+		return nil, fmt.Errorf("unmarshaling frontmatter: %w", err)
+	}
+	return mission, nil
+}
+
 // parseLegacy parses the legacy # MISSION format and its variants
 func (r *Reader) parseLegacy(data []byte) (*Mission, error) {
 	lines := bytes.Split(data, []byte("\n"))
@@ -116,8 +119,17 @@ func (r *Reader) parseLegacy(data []byte) (*Mission, error) {
 		case strings.HasPrefix(line, "id:"):
 			mission.ID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
 		case strings.HasPrefix(line, "completed_at:"), strings.HasPrefix(line, "duration_minutes:"):
-			// Parse additional metadata fields if present but don't fail on parsing errors
-			// These fields are informational and not critical for mission functionality
+			// Informational fields are kept as extra frontmatter; parsing never fails on them
+			key, value, _ := strings.Cut(line, ":")
+			value = strings.TrimSpace(value)
+			if mission.Extra == nil {
+				mission.Extra = make(map[string]interface{})
+			}
+			if n, err := strconv.Atoi(value); err == nil {
+				mission.Extra[key] = n
+			} else {
+				mission.Extra[key] = value
+			}
 		}
 	}
 
@@ -174,3 +186,17 @@ func (r *Reader) ReadScope() (string, error) {
 	}
 	return strings.Join(scope, "\n"), nil
 }
+
+// ReadResolvedScope returns the SCOPE section with glob and directory entries expanded
+// to the matching files, one per line. Like ReadScope, an empty scope is not an error.
+func (r *Reader) ReadResolvedScope() (string, error) {
+	mission, err := r.Read()
+	if err != nil {
+		return "", fmt.Errorf("reading mission file: %w", err)
+	}
+	files, err := NewScope(r.FS(), mission.GetScope()).Resolve()
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(files, "\n"), nil
+}
@@ -0,0 +1,175 @@
+package mission
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/afero"
+)
+
+// excludedScopeDirs are never part of a resolved scope, even when a pattern matches them
+var excludedScopeDirs = []string{".git", DefaultDir}
+
+// scopePattern is a single compiled SCOPE entry
+type scopePattern struct {
+	entry   string // entry as written in SCOPE
+	pattern string // doublestar pattern derived from the entry
+	negate  bool   // entry starts with "!"
+	literal bool   // entry names a single file (no glob, not a directory)
+}
+
+// Scope resolves SCOPE entries against a filesystem. Entries may be literal file
+// paths, directories (trailing slash or existing directory, matching everything
+// below them), doublestar globs such as pkg/**/*.go, and negations prefixed with
+// "!" that remove matches of any other entry regardless of order.
+type Scope struct {
+	fs       afero.Fs
+	patterns []scopePattern
+}
+
+// NewScope compiles SCOPE entries for matching and expansion against fs
+func NewScope(fs afero.Fs, entries []string) *Scope {
+	s := &Scope{fs: fs}
+	for _, entry := range entries {
+		if p, ok := s.compile(entry); ok {
+			s.patterns = append(s.patterns, p)
+		}
+	}
+	return s
+}
+
+// Match reports whether path is included by at least one entry and not excluded by a negation
+func (s *Scope) Match(filePath string) bool {
+	return len(s.MatchingEntries(filePath)) > 0
+}
+
+// MatchingEntries returns the non-negated entries that include path.
+// Returns nil if path is excluded by a negation or is a mission/git artifact.
+func (s *Scope) MatchingEntries(filePath string) []string {
+	filePath = normalizePath(filePath)
+	if isExcludedScopePath(filePath) {
+		return nil
+	}
+
+	var entries []string
+	for _, p := range s.patterns {
+		if !matchPattern(p.pattern, filePath) {
+			continue
+		}
+		if p.negate {
+			return nil
+		}
+		entries = append(entries, p.entry)
+	}
+	return entries
+}
+
+// IncludeEntries returns the non-negated entries as written in SCOPE
+func (s *Scope) IncludeEntries() []string {
+	var entries []string
+	for _, p := range s.patterns {
+		if !p.negate {
+			entries = append(entries, p.entry)
+		}
+	}
+	return entries
+}
+
+// HasPatterns reports whether any entry is a glob, directory or negation
+func (s *Scope) HasPatterns() bool {
+	for _, p := range s.patterns {
+		if !p.literal {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve expands the scope into a sorted list of files. Literal file entries are
+// always included, even if they do not exist yet, so new and deleted files stay in
+// scope. Glob and directory entries expand to the files currently on disk.
+func (s *Scope) Resolve() ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(file string) {
+		if !seen[file] && s.Match(file) {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	iofs := afero.NewIOFS(s.fs)
+	for _, p := range s.patterns {
+		if p.negate {
+			continue
+		}
+		if p.literal {
+			add(p.pattern)
+			continue
+		}
+		matches, err := doublestar.Glob(iofs, p.pattern, doublestar.WithFilesOnly(), doublestar.WithFailOnIOErrors())
+		if err != nil {
+			return nil, fmt.Errorf("expanding scope entry %q: %w", p.entry, err)
+		}
+		for _, match := range matches {
+			add(match)
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// compile converts a SCOPE entry into a doublestar pattern
+func (s *Scope) compile(entry string) (scopePattern, bool) {
+	raw := strings.TrimSpace(entry)
+	p := scopePattern{entry: raw}
+	if strings.HasPrefix(raw, "!") {
+		p.negate = true
+		raw = strings.TrimSpace(strings.TrimPrefix(raw, "!"))
+	}
+	if raw == "" {
+		return p, false
+	}
+
+	isDir := strings.HasSuffix(raw, "/")
+	cleaned := normalizePath(raw)
+	if !isDir && !hasGlobMeta(cleaned) {
+		isDir, _ = afero.DirExists(s.fs, cleaned)
+	}
+
+	switch {
+	case isDir:
+		p.pattern = path.Join(cleaned, "**")
+	case hasGlobMeta(cleaned):
+		p.pattern = cleaned
+	default:
+		p.pattern = cleaned
+		p.literal = !p.negate
+	}
+	return p, true
+}
+
+// matchPattern matches a normalized path against a doublestar pattern
+func matchPattern(pattern, filePath string) bool {
+	ok, err := doublestar.Match(pattern, filePath)
+	return err == nil && ok
+}
+
+// hasGlobMeta reports whether s contains doublestar meta characters
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[{")
+}
+
+// isExcludedScopePath reports whether path is inside a directory that is never in scope
+func isExcludedScopePath(filePath string) bool {
+	for _, dir := range excludedScopeDirs {
+		if filePath == dir || strings.HasPrefix(filePath, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
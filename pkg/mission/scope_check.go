@@ -0,0 +1,142 @@
+package mission
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+)
+
+// ScopeReport describes how the working tree changes relate to the mission SCOPE
+type ScopeReport struct {
+	MissionID   string           `json:"mission_id"`
+	Base        string           `json:"base"`
+	Compliant   bool             `json:"compliant"`
+	InScope     []git.FileChange `json:"in_scope"`
+	OutOfScope  []git.FileChange `json:"out_of_scope"`
+	UnusedScope []string         `json:"unused_scope"`
+	Message     string           `json:"message"`
+	NextStep    string           `json:"next_step"`
+}
+
+// Baseline lists the working tree changes since the baseline checkpoint of a mission,
+// as the checkpoint backend holding it sees them. checkpoint.Service implements it.
+type Baseline interface {
+	// BaselineChanges returns the baseline name and the changes since it, or an empty
+	// name before the first checkpoint
+	BaselineChanges(ctx context.Context, missionID string) (string, []git.FileChange, error)
+}
+
+// ScopeChecker compares working tree changes against the mission SCOPE
+type ScopeChecker struct {
+	*BaseService
+	reader   *Reader
+	git      git.GitClient
+	baseline Baseline
+}
+
+// NewScopeChecker creates a new ScopeChecker for the specified mission file path.
+// The mission directory is derived from the path's directory component. A nil
+// baseline compares against HEAD.
+func NewScopeChecker(fs afero.Fs, path string, gitClient git.GitClient, baseline Baseline) *ScopeChecker {
+	missionDir := filepath.Dir(path)
+	return &ScopeChecker{
+		BaseService: NewBaseServiceWithPath(fs, missionDir, path),
+		reader:      NewReader(fs, path),
+		git:         gitClient,
+		baseline:    baseline,
+	}
+}
+
+// Check diffs the working tree against the mission baseline checkpoint (or HEAD before the
+// first checkpoint) and classifies every changed file as in or out of scope using
+// the same pattern rules as checkpoint staging. Include entries that match no
+// changed file are reported as unused.
+func (s *ScopeChecker) Check(ctx context.Context) (*ScopeReport, error) {
+	m, err := s.reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+
+	base, changes, err := s.changes(ctx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := NewScope(s.FS(), m.GetScope())
+	used := make(map[string]bool)
+
+	report := &ScopeReport{
+		MissionID:   m.ID,
+		Base:        base,
+		InScope:     []git.FileChange{},
+		OutOfScope:  []git.FileChange{},
+		UnusedScope: []string{},
+	}
+
+	for _, change := range changes {
+		path := normalizePath(change.Path)
+		if s.isMissionArtifact(path) {
+			continue
+		}
+		entries := scope.MatchingEntries(path)
+		if len(entries) == 0 {
+			report.OutOfScope = append(report.OutOfScope, change)
+			continue
+		}
+		for _, entry := range entries {
+			used[entry] = true
+		}
+		report.InScope = append(report.InScope, change)
+	}
+
+	for _, entry := range scope.IncludeEntries() {
+		if !used[entry] {
+			report.UnusedScope = append(report.UnusedScope, entry)
+		}
+	}
+
+	report.Compliant = len(report.OutOfScope) == 0
+	if report.Compliant {
+		report.Message = fmt.Sprintf("All %d changed file(s) are within mission scope", len(report.InScope))
+		report.NextStep = "PROCEED"
+	} else {
+		report.Message = fmt.Sprintf("%d file(s) changed outside mission scope", len(report.OutOfScope))
+		report.NextStep = "STOP. Revert out-of-scope changes or ask the user to extend SCOPE before creating a checkpoint."
+	}
+
+	return report, nil
+}
+
+// changes returns the changes since the mission baseline, or since HEAD before the
+// first checkpoint
+func (s *ScopeChecker) changes(ctx context.Context, missionID string) (string, []git.FileChange, error) {
+	if s.baseline != nil {
+		base, changes, err := s.baseline.BaselineChanges(ctx, missionID)
+		if err != nil {
+			return "", nil, fmt.Errorf("listing changes since baseline: %w", err)
+		}
+		if base != "" {
+			return base, changes, nil
+		}
+	}
+	changes, err := s.git.GetChangedFiles(ctx, "HEAD")
+	if err != nil {
+		return "", nil, fmt.Errorf("listing changed files: %w", err)
+	}
+	return "HEAD", changes, nil
+}
+
+// isMissionArtifact reports whether path lives inside the top-level mission directory
+func (s *ScopeChecker) isMissionArtifact(path string) bool {
+	root := normalizePath(s.RootDir())
+	return path == root || strings.HasPrefix(path, root+"/")
+}
+
+// normalizePath cleans a path and converts it to forward slashes for comparison
+func normalizePath(path string) string {
+	return filepath.ToSlash(filepath.Clean(strings.TrimSpace(path)))
+}
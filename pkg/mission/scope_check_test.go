@@ -0,0 +1,92 @@
+package mission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scopeCheckMission = `---
+id: scope-1
+status: active
+---
+
+## INTENT
+Test intent
+
+## SCOPE
+- pkg/a.go
+- pkg/b.go
+- pkg/c_test.go
+`
+
+// stubBaseline returns fixed changes since a mission baseline
+type stubBaseline struct {
+	base    string
+	changes []git.FileChange
+	err     error
+}
+
+func (b *stubBaseline) BaselineChanges(ctx context.Context, missionID string) (string, []git.FileChange, error) {
+	return b.base, b.changes, b.err
+}
+
+func TestScopeChecker_Check(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte(scopeCheckMission), 0644))
+
+	baseline := &stubBaseline{
+		base: "scope-1-baseline",
+		changes: []git.FileChange{
+			{Path: "pkg/a.go", Change: git.ChangeModified},
+			{Path: "pkg/c_test.go", Change: git.ChangeAdded},
+			{Path: "README.md", Change: git.ChangeModified},
+			{Path: "pkg/old.go", Change: git.ChangeDeleted},
+			{Path: ".mission/execution.log", Change: git.ChangeAdded},
+		},
+	}
+
+	report, err := NewScopeChecker(fs, ".mission/mission.md", &MockGitClient{}, baseline).Check(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "scope-1-baseline", report.Base)
+	assert.False(t, report.Compliant)
+	assert.Len(t, report.InScope, 2)
+	assert.Equal(t, []git.FileChange{
+		{Path: "README.md", Change: git.ChangeModified},
+		{Path: "pkg/old.go", Change: git.ChangeDeleted},
+	}, report.OutOfScope)
+	assert.Equal(t, []string{"pkg/b.go"}, report.UnusedScope)
+	assert.Contains(t, report.NextStep, "STOP")
+}
+
+func TestScopeChecker_CheckCompliantBeforeBaseline(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte(scopeCheckMission), 0644))
+
+	gitClient := &MockGitClient{
+		changedFiles: []git.FileChange{{Path: "./pkg/b.go", Change: git.ChangeModified}},
+	}
+
+	report, err := NewScopeChecker(fs, ".mission/mission.md", gitClient, &stubBaseline{}).Check(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "HEAD", report.Base)
+	assert.True(t, report.Compliant)
+	assert.Empty(t, report.OutOfScope)
+	assert.Equal(t, []string{"pkg/a.go", "pkg/c_test.go"}, report.UnusedScope)
+}
+
+func TestScopeChecker_CheckBaselineError(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte(scopeCheckMission), 0644))
+
+	baseline := &stubBaseline{err: errors.New("listing checkpoints: lock held")}
+	_, err := NewScopeChecker(fs, ".mission/mission.md", &MockGitClient{}, baseline).Check(t.Context())
+	assert.ErrorContains(t, err, "lock held", "baseline errors are not replaced by a diff against HEAD")
+}
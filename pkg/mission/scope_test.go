@@ -0,0 +1,117 @@
+package mission
+
+import (
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScopeTestFs(t *testing.T) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for _, file := range []string{
+		"main.go",
+		"pkg/auth/login.go",
+		"pkg/auth/login_test.go",
+		"pkg/auth/token/jwt.go",
+		"pkg/store/db.go",
+		"docs/README.md",
+		".mission/mission.md",
+	} {
+		require.NoError(t, afero.WriteFile(fs, file, []byte("x"), 0644))
+	}
+	return fs
+}
+
+func TestScope_Match(t *testing.T) {
+	fs := newScopeTestFs(t)
+
+	tests := []struct {
+		name    string
+		entries []string
+		path    string
+		want    bool
+	}{
+		{"literal file", []string{"main.go"}, "main.go", true},
+		{"literal file with dot prefix", []string{"./main.go"}, "main.go", true},
+		{"literal mismatch", []string{"main.go"}, "pkg/store/db.go", false},
+		{"missing literal still matches", []string{"pkg/new.go"}, "pkg/new.go", true},
+		{"directory with trailing slash", []string{"pkg/auth/"}, "pkg/auth/token/jwt.go", true},
+		{"existing directory without slash", []string{"pkg/auth"}, "pkg/auth/login.go", true},
+		{"directory does not match sibling", []string{"pkg/auth/"}, "pkg/store/db.go", false},
+		{"doublestar glob", []string{"pkg/**/*.go"}, "pkg/auth/token/jwt.go", true},
+		{"single star does not cross directories", []string{"pkg/*.go"}, "pkg/auth/login.go", false},
+		{"negation excludes match", []string{"pkg/**/*.go", "!pkg/**/*_test.go"}, "pkg/auth/login_test.go", false},
+		{"negation order does not matter", []string{"!pkg/**/*_test.go", "pkg/auth/"}, "pkg/auth/login_test.go", false},
+		{"negation keeps other matches", []string{"pkg/**/*.go", "!pkg/**/*_test.go"}, "pkg/auth/login.go", true},
+		{"mission directory never matches", []string{"**"}, ".mission/mission.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewScope(fs, tt.entries).Match(tt.path))
+		})
+	}
+}
+
+func TestScope_Resolve(t *testing.T) {
+	fs := newScopeTestFs(t)
+
+	scope := NewScope(fs, []string{"pkg/auth/", "!pkg/**/*_test.go", "main.go", "pkg/new.go", "**/*.md"})
+	files, err := scope.Resolve()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"docs/README.md",
+		"main.go",
+		"pkg/auth/login.go",
+		"pkg/auth/token/jwt.go",
+		"pkg/new.go",
+	}, files)
+}
+
+func TestScope_HasPatterns(t *testing.T) {
+	fs := newScopeTestFs(t)
+
+	assert.False(t, NewScope(fs, []string{"main.go", "pkg/new.go"}).HasPatterns())
+	assert.True(t, NewScope(fs, []string{"main.go", "pkg/auth"}).HasPatterns())
+	assert.True(t, NewScope(fs, []string{"pkg/*.go"}).HasPatterns())
+	assert.True(t, NewScope(fs, []string{"main.go", "!main.go"}).HasPatterns())
+}
+
+func TestScope_IncludeEntries(t *testing.T) {
+	scope := NewScope(afero.NewMemMapFs(), []string{"pkg/**/*.go", "!pkg/**/*_test.go", " ", "main.go"})
+	assert.Equal(t, []string{"pkg/**/*.go", "main.go"}, scope.IncludeEntries())
+}
+
+func TestScopeChecker_GlobEntries(t *testing.T) {
+	fs := newScopeTestFs(t)
+	content := `---
+id: scope-glob
+status: active
+---
+
+## SCOPE
+- pkg/auth/
+- !pkg/**/*_test.go
+- docs/*.txt
+`
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte(content), 0644))
+
+	gitClient := &MockGitClient{
+		changedFiles: []git.FileChange{
+			{Path: "pkg/auth/token/jwt.go", Change: git.ChangeModified},
+			{Path: "pkg/auth/login_test.go", Change: git.ChangeModified},
+		},
+	}
+
+	report, err := NewScopeChecker(fs, ".mission/mission.md", gitClient, nil).Check(t.Context())
+	require.NoError(t, err)
+
+	assert.False(t, report.Compliant)
+	assert.Equal(t, []git.FileChange{{Path: "pkg/auth/token/jwt.go", Change: git.ChangeModified}}, report.InScope)
+	assert.Equal(t, []git.FileChange{{Path: "pkg/auth/login_test.go", Change: git.ChangeModified}}, report.OutOfScope)
+	assert.Equal(t, []string{"docs/*.txt"}, report.UnusedScope)
+}
@@ -0,0 +1,103 @@
+package mission
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mission lifecycle statuses
+const (
+	StatusPlanning  = "planning"
+	StatusPlanned   = "planned"
+	StatusActive    = "active"
+	StatusExecuted  = "executed"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusPaused    = "paused"
+)
+
+// transitions defines the allowed target statuses for each mission status.
+// The happy path is planning → planned → active → executed → completed;
+// failed allows a retry or re-plan; paused resumes to any non-terminal status or is
+// abandoned as failed without resuming first.
+var transitions = map[string][]string{
+	StatusPlanning:  {StatusPlanned, StatusFailed, StatusPaused},
+	StatusPlanned:   {StatusActive, StatusPlanning, StatusFailed, StatusPaused},
+	StatusActive:    {StatusExecuted, StatusFailed, StatusPaused},
+	StatusExecuted:  {StatusCompleted, StatusActive, StatusFailed, StatusPaused},
+	StatusCompleted: {},
+	StatusFailed:    {StatusActive, StatusPlanning, StatusPlanned, StatusPaused},
+	StatusPaused:    {StatusPlanning, StatusPlanned, StatusActive, StatusExecuted, StatusFailed},
+}
+
+// StatusTransition records a single status change in the mission frontmatter
+type StatusTransition struct {
+	From    string    `yaml:"from" json:"from"`
+	To      string    `yaml:"to" json:"to"`
+	At      time.Time `yaml:"at" json:"at"`
+	Command string    `yaml:"command,omitempty" json:"command,omitempty"`
+}
+
+// IsValidStatus reports whether status is a known mission status
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// ValidateTransition returns an error if moving from one status to another is not allowed.
+// Re-applying the current status is always allowed. Missions with an empty or unknown
+// current status (legacy or hand-edited files) may move to any known status.
+func ValidateTransition(from, to string) error {
+	if !IsValidStatus(to) {
+		return fmt.Errorf("unknown status %q (valid: %s)", to, strings.Join(validStatuses(), ", "))
+	}
+	if from == to || !IsValidStatus(from) {
+		return nil
+	}
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	if len(transitions[from]) == 0 {
+		return fmt.Errorf("cannot change status from %q to %q: %q is a final status", from, to, from)
+	}
+	return fmt.Errorf("cannot change status from %q to %q (allowed: %s)", from, to, strings.Join(transitions[from], ", "))
+}
+
+// PausedFrom returns the status a paused mission had when it was paused, which it
+// resumes with, or "" if the mission is not paused or its history does not say
+func (m *Mission) PausedFrom() string {
+	if m.Status != StatusPaused {
+		return ""
+	}
+	for i := len(m.StatusHistory) - 1; i >= 0; i-- {
+		if t := m.StatusHistory[i]; t.To == StatusPaused {
+			return t.From
+		}
+	}
+	return ""
+}
+
+// RetryCount returns how many times the mission was re-activated after a failure
+func (m *Mission) RetryCount() int {
+	count := 0
+	for _, t := range m.StatusHistory {
+		if t.From == StatusFailed && t.To == StatusActive {
+			count++
+		}
+	}
+	return count
+}
+
+// validStatuses returns all known statuses in sorted order
+func validStatuses() []string {
+	statuses := make([]string, 0, len(transitions))
+	for status := range transitions {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
@@ -0,0 +1,93 @@
+package mission
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/logger"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr string
+	}{
+		{name: "planning to planned", from: StatusPlanning, to: StatusPlanned},
+		{name: "planned to active", from: StatusPlanned, to: StatusActive},
+		{name: "active to executed", from: StatusActive, to: StatusExecuted},
+		{name: "executed to completed", from: StatusExecuted, to: StatusCompleted},
+		{name: "failed retry", from: StatusFailed, to: StatusActive},
+		{name: "paused resume", from: StatusPaused, to: StatusActive},
+		{name: "paused abandoned", from: StatusPaused, to: StatusFailed},
+		{name: "paused is not completed", from: StatusPaused, to: StatusCompleted, wantErr: "cannot change status"},
+		{name: "same status", from: StatusActive, to: StatusActive},
+		{name: "legacy empty status", from: "", to: StatusActive},
+		{name: "unknown target", from: StatusActive, to: "complted", wantErr: "unknown status"},
+		{name: "skip execution", from: StatusPlanned, to: StatusCompleted, wantErr: "cannot change status"},
+		{name: "completed is final", from: StatusCompleted, to: StatusActive, wantErr: "final status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTransition(tt.from, tt.to)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestWriter_UpdateStatus_RecordsHistory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "mission.md"
+	writer := &Writer{
+		BaseService:  NewBaseServiceWithPath(fs, "", path),
+		loggerConfig: &logger.Config{Output: logger.OutputConsole},
+	}
+	require.NoError(t, writer.Write(&Mission{ID: "hist-1", Status: StatusPlanned, Iteration: 1, Body: "## INTENT\nTest\n"}))
+
+	writer.SetCommand("m mission update --status active")
+	require.NoError(t, writer.UpdateStatus(StatusActive))
+	writer.SetCommand("m mission update --status failed")
+	require.NoError(t, writer.UpdateStatus(StatusFailed))
+	writer.SetCommand("m mission update --status active")
+	require.NoError(t, writer.UpdateStatus(StatusActive))
+	require.NoError(t, writer.UpdateStatus(StatusActive), "re-applying current status should be a no-op")
+
+	m, err := NewReader(fs, path).Read()
+	require.NoError(t, err)
+	require.Len(t, m.StatusHistory, 3)
+	assert.Equal(t, StatusPlanned, m.StatusHistory[0].From)
+	assert.Equal(t, StatusActive, m.StatusHistory[0].To)
+	assert.Equal(t, "m mission update --status active", m.StatusHistory[0].Command)
+	assert.False(t, m.StatusHistory[0].At.IsZero())
+	assert.Equal(t, 1, m.RetryCount())
+}
+
+func TestWriter_UpdateStatus_RejectsIllegalTransition(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "mission.md"
+	writer := &Writer{
+		BaseService:  NewBaseServiceWithPath(fs, "", path),
+		loggerConfig: &logger.Config{Output: logger.OutputConsole},
+	}
+	require.NoError(t, writer.Write(&Mission{ID: "illegal-1", Status: StatusPlanned, Iteration: 1, Body: "## INTENT\nTest\n"}))
+
+	err := writer.UpdateStatus("complted")
+	require.Error(t, err)
+	err = writer.UpdateStatus(StatusCompleted)
+	require.Error(t, err)
+
+	data, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(data), "status: planned"), "status should be unchanged")
+	assert.NotContains(t, string(data), "status_history")
+}
@@ -0,0 +1,253 @@
+package mission
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dnatag/mission-toolkit/pkg/logger"
+	"github.com/spf13/afero"
+)
+
+const (
+	// DefaultVerifyTimeout bounds each verification command
+	DefaultVerifyTimeout = 10 * time.Minute
+	// DefaultVerifyMaxOutput is the number of bytes kept from stdout and stderr
+	DefaultVerifyMaxOutput = 4096
+)
+
+// inlineCommandPattern extracts `command` spans from prose verification lines
+var inlineCommandPattern = regexp.MustCompile("`([^`]+)`")
+
+// listMarkerPattern strips bullet, numbered and checkbox list markers
+var listMarkerPattern = regexp.MustCompile(`^(?:[-*+]\s+(?:\[[ xX]\]\s+)?|\d+[.)]\s+)`)
+
+// CommandResult records a single verification command execution
+type CommandResult struct {
+	Command    string `json:"command"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMs int64  `json:"duration_ms"`
+	Stdout     string `json:"stdout,omitempty"`
+	Stderr     string `json:"stderr,omitempty"`
+	TimedOut   bool   `json:"timed_out,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+}
+
+// VerifyResult is the outcome of running the mission VERIFICATION section
+type VerifyResult struct {
+	MissionID string          `json:"mission_id"`
+	Passed    bool            `json:"passed"`
+	Commands  []CommandResult `json:"commands"`
+	Status    string          `json:"status,omitempty"`
+	Message   string          `json:"message"`
+	NextStep  string          `json:"next_step"`
+}
+
+// VerifyOptions controls how verification commands run
+type VerifyOptions struct {
+	Timeout      time.Duration // Per-command timeout, DefaultVerifyTimeout when zero
+	MaxOutput    int           // Bytes kept from stdout and stderr, DefaultVerifyMaxOutput when zero
+	UpdateStatus bool          // Transition the mission to executed or failed
+	Dir          string        // Working directory for commands, current directory when empty
+}
+
+// Verifier runs the VERIFICATION commands of a mission and records the results
+type Verifier struct {
+	*BaseService
+	reader       *Reader
+	loggerConfig *logger.Config
+}
+
+// NewVerifier creates a new Verifier for the specified mission file path.
+// The mission directory is derived from the path's directory component.
+// Results are logged to the execution.log next to the mission file only,
+// so console output stays machine-readable.
+func NewVerifier(fs afero.Fs, path string) *Verifier {
+	missionDir := filepath.Dir(path)
+	loggerConfig := logger.DefaultConfig()
+	loggerConfig.Output = logger.OutputFile
+	loggerConfig.FilePath = filepath.Join(missionDir, "execution.log")
+	loggerConfig.Fs = fs
+	return &Verifier{
+		BaseService:  NewBaseServiceWithPath(fs, missionDir, path),
+		reader:       NewReader(fs, path),
+		loggerConfig: loggerConfig,
+	}
+}
+
+// Verify runs each verification command in order through the shell, stopping at the
+// first failure. Every executed command is logged to execution.log. With
+// UpdateStatus set, the mission moves to executed on success or failed otherwise.
+func (v *Verifier) Verify(opts VerifyOptions) (*VerifyResult, error) {
+	m, err := v.reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading mission: %w", err)
+	}
+
+	commands := VerificationCommands(m.GetVerification())
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("no verification commands found in VERIFICATION section")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultVerifyTimeout
+	}
+	if opts.MaxOutput <= 0 {
+		opts.MaxOutput = DefaultVerifyMaxOutput
+	}
+
+	log := logger.NewWithConfig(m.ID, v.loggerConfig)
+	result := &VerifyResult{MissionID: m.ID, Passed: true, Commands: []CommandResult{}}
+
+	for _, command := range commands {
+		cmdResult := runVerification(command, opts)
+		result.Commands = append(result.Commands, cmdResult)
+
+		level := logger.LevelSuccess
+		if cmdResult.ExitCode != 0 {
+			level = logger.LevelFailed
+		}
+		log.LogStep(level, "Verification", formatCommandResult(cmdResult))
+
+		if cmdResult.ExitCode != 0 {
+			result.Passed = false
+			break
+		}
+	}
+
+	if result.Passed {
+		result.Message = fmt.Sprintf("Verification passed (%d command(s))", len(result.Commands))
+		result.NextStep = "PROCEED"
+	} else {
+		failed := result.Commands[len(result.Commands)-1]
+		result.Message = fmt.Sprintf("Verification failed: %q exited with code %d", failed.Command, failed.ExitCode)
+		if failed.TimedOut {
+			result.Message = fmt.Sprintf("Verification failed: %q timed out after %s", failed.Command, opts.Timeout)
+		}
+		result.NextStep = "STOP. Fix the failing verification command output before continuing."
+	}
+
+	if opts.UpdateStatus {
+		status := StatusExecuted
+		if !result.Passed {
+			status = StatusFailed
+		}
+		writer := NewWriter(v.FS(), v.MissionPath())
+		writer.SetCommand("m mission verify")
+		if err := writer.UpdateStatus(status); err != nil {
+			return result, fmt.Errorf("updating status to %s: %w", status, err)
+		}
+		result.Status = status
+	}
+
+	return result, nil
+}
+
+// VerificationCommands extracts shell commands from a VERIFICATION section.
+// Every line of a code fence is a command, and other lines contribute the commands
+// quoted in backticks, after list markers are stripped. Any other line is prose and
+// skipped, unless it is the only line of the section, as in the single command m.plan
+// writes. Blank lines and shell comments are ignored.
+func VerificationCommands(verification string) []string {
+	var commands, bare []string
+	inFence := false
+	for _, line := range strings.Split(verification, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if inFence {
+			commands = append(commands, line)
+			continue
+		}
+		line = strings.TrimSpace(listMarkerPattern.ReplaceAllString(line, ""))
+
+		if matches := inlineCommandPattern.FindAllStringSubmatch(line, -1); len(matches) > 0 {
+			for _, match := range matches {
+				if command := strings.TrimSpace(match[1]); command != "" {
+					commands = append(commands, command)
+				}
+			}
+			continue
+		}
+		if line != "" {
+			bare = append(bare, line)
+		}
+	}
+	if len(commands) == 0 && len(bare) == 1 {
+		return bare
+	}
+	return commands
+}
+
+// runVerification executes command through sh with the configured timeout
+func runVerification(command string, opts VerifyOptions) CommandResult {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = opts.Dir
+	// Background processes spawned by the shell can keep the output pipes open after
+	// a timeout kill; stop waiting for them shortly after the shell exits.
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := CommandResult{
+		Command:    command,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.TimedOut = true
+		result.ExitCode = -1
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		stderr.WriteString(err.Error())
+	}
+
+	var outTruncated, errTruncated bool
+	result.Stdout, outTruncated = truncateOutput(stdout.String(), opts.MaxOutput)
+	result.Stderr, errTruncated = truncateOutput(stderr.String(), opts.MaxOutput)
+	result.Truncated = outTruncated || errTruncated
+	return result
+}
+
+// truncateOutput keeps the last max bytes of output, where failures are usually reported
+func truncateOutput(output string, max int) (string, bool) {
+	if len(output) <= max {
+		return output, false
+	}
+	return "..." + output[len(output)-max:], true
+}
+
+// formatCommandResult renders a command result as a single key=value log message
+func formatCommandResult(r CommandResult) string {
+	msg := fmt.Sprintf("command=%q exit_code=%d duration_ms=%d", r.Command, r.ExitCode, r.DurationMs)
+	if r.TimedOut {
+		msg += " timed_out=true"
+	}
+	if r.Stdout != "" {
+		msg += fmt.Sprintf(" stdout=%q", r.Stdout)
+	}
+	if r.Stderr != "" {
+		msg += fmt.Sprintf(" stderr=%q", r.Stderr)
+	}
+	return msg
+}
@@ -0,0 +1,132 @@
+package mission
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeVerifyMission(t *testing.T, fs afero.Fs, status, verification string) {
+	writeTestMission(t, fs, "---\nid: verify-1\nstatus: "+status+"\n---\n\n## INTENT\nTest\n\n## VERIFICATION\n"+verification+"\n")
+}
+
+func TestVerificationCommands(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification string
+		want         []string
+	}{
+		{"single command", "go test ./...", []string{"go test ./..."}},
+		{"code fence", "```bash\ngo vet ./...\ngo test ./...\n```", []string{"go vet ./...", "go test ./..."}},
+		{"list markers", "- `go build ./...`\n1. `go test ./...`\n- [ ] `make lint`", []string{"go build ./...", "go test ./...", "make lint"}},
+		{"single listed command", "- go test ./...", []string{"go test ./..."}},
+		{"prose with backticks", "Run `go test ./pkg/...` and then `golangci-lint run`", []string{"go test ./pkg/...", "golangci-lint run"}},
+		{"prose is skipped", "Run the unit tests and confirm they pass\ngo test ./...", nil},
+		{"prose around commands", "Run the unit tests and confirm they pass\n```\ngo test ./...\n```\nThen `make lint`", []string{"go test ./...", "make lint"}},
+		{"fence keeps backticks", "```sh\ntest \"`go env GOOS`\" = linux\n```", []string{"test \"`go env GOOS`\" = linux"}},
+		{"comments and blanks", "# unit tests\n\ngo test ./...\n", []string{"go test ./..."}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, VerificationCommands(tt.verification))
+		})
+	}
+}
+
+func TestVerifier_Verify_Passes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeVerifyMission(t, fs, StatusActive, "- `echo hello`\n- `true`")
+
+	result, err := NewVerifier(fs, ".mission/mission.md").Verify(VerifyOptions{UpdateStatus: true})
+	require.NoError(t, err)
+
+	assert.True(t, result.Passed)
+	require.Len(t, result.Commands, 2)
+	assert.Equal(t, "hello\n", result.Commands[0].Stdout)
+	assert.Equal(t, 0, result.Commands[0].ExitCode)
+	assert.Equal(t, StatusExecuted, result.Status)
+	assert.Equal(t, "PROCEED", result.NextStep)
+
+	m, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, StatusExecuted, m.Status)
+	require.Len(t, m.StatusHistory, 1)
+	assert.Equal(t, "m mission verify", m.StatusHistory[0].Command)
+
+	log, err := afero.ReadFile(fs, ".mission/execution.log")
+	require.NoError(t, err)
+	assert.Contains(t, string(log), `command=\"echo hello\" exit_code=0`)
+	assert.Contains(t, string(log), "step=Verification")
+}
+
+func TestVerifier_Verify_StopsAtFirstFailure(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeVerifyMission(t, fs, StatusActive, "```\necho boom >&2; exit 3\necho never\n```")
+
+	result, err := NewVerifier(fs, ".mission/mission.md").Verify(VerifyOptions{UpdateStatus: true})
+	require.NoError(t, err)
+
+	assert.False(t, result.Passed)
+	require.Len(t, result.Commands, 1)
+	assert.Equal(t, 3, result.Commands[0].ExitCode)
+	assert.Equal(t, "boom\n", result.Commands[0].Stderr)
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.True(t, strings.HasPrefix(result.NextStep, "STOP"))
+
+	m, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, m.Status)
+}
+
+func TestVerifier_Verify_LeavesStatusByDefault(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeVerifyMission(t, fs, StatusActive, "false")
+
+	result, err := NewVerifier(fs, ".mission/mission.md").Verify(VerifyOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Empty(t, result.Status)
+
+	m, err := NewReader(fs, ".mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, StatusActive, m.Status)
+}
+
+func TestVerifier_Verify_Timeout(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeVerifyMission(t, fs, StatusActive, "exec sleep 5")
+
+	result, err := NewVerifier(fs, ".mission/mission.md").Verify(VerifyOptions{Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	assert.False(t, result.Passed)
+	assert.True(t, result.Commands[0].TimedOut)
+	assert.Equal(t, -1, result.Commands[0].ExitCode)
+	assert.Contains(t, result.Message, "timed out")
+}
+
+func TestVerifier_Verify_TruncatesOutput(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeVerifyMission(t, fs, StatusActive, "printf 'abcdefghij'")
+
+	result, err := NewVerifier(fs, ".mission/mission.md").Verify(VerifyOptions{MaxOutput: 4})
+	require.NoError(t, err)
+
+	assert.True(t, result.Commands[0].Truncated)
+	assert.Equal(t, "...ghij", result.Commands[0].Stdout)
+}
+
+func TestVerifier_Verify_NoCommands(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeVerifyMission(t, fs, StatusActive, "")
+
+	_, err := NewVerifier(fs, ".mission/mission.md").Verify(VerifyOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no verification commands")
+}
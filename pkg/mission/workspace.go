@@ -0,0 +1,239 @@
+package mission
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// DefaultDir is the default top-level mission directory
+	DefaultDir = ".mission"
+
+	activePointerFile = "active"
+	missionsDirName   = "missions"
+)
+
+// missionArtifacts are the per-mission files that move with a mission between
+// the unnamed root slot and its named directory.
+var missionArtifacts = []string{"mission.md", "execution.log", "plan.json", "id"}
+
+// Workspace manages multiple named missions under a top-level .mission directory.
+// Named missions keep their artifacts in <root>/missions/<id>/ and the active one
+// is selected by the <root>/active pointer file. Without a valid pointer the legacy
+// single-mission layout (<root>/mission.md) is used, which is also where new
+// missions are planned.
+type Workspace struct {
+	fs      afero.Fs
+	rootDir string
+}
+
+// MissionEntry describes a mission known to the workspace
+type MissionEntry struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Intent string `json:"intent,omitempty"`
+	Path   string `json:"path"`
+	Active bool   `json:"active"`
+}
+
+// NewWorkspace creates a workspace rooted at the given mission directory
+func NewWorkspace(fs afero.Fs, rootDir string) *Workspace {
+	return &Workspace{fs: fs, rootDir: rootDir}
+}
+
+// RootDir returns the top-level mission directory
+func (w *Workspace) RootDir() string {
+	return w.rootDir
+}
+
+// ActiveID returns the ID of the named mission selected by the pointer file.
+// Returns an empty string when no pointer exists or it references a missing mission.
+func (w *Workspace) ActiveID() string {
+	data, err := afero.ReadFile(w.fs, filepath.Join(w.rootDir, activePointerFile))
+	if err != nil {
+		return ""
+	}
+	id := strings.TrimSpace(string(data))
+	if id == "" {
+		return ""
+	}
+	if exists, _ := afero.Exists(w.fs, filepath.Join(w.DirFor(id), "mission.md")); !exists {
+		return ""
+	}
+	return id
+}
+
+// DirFor returns the artifact directory of the named mission with the given ID
+func (w *Workspace) DirFor(id string) string {
+	return filepath.Join(w.rootDir, missionsDirName, id)
+}
+
+// MissionDir returns the artifact directory of the active mission
+func (w *Workspace) MissionDir() string {
+	if id := w.ActiveID(); id != "" {
+		return w.DirFor(id)
+	}
+	return w.rootDir
+}
+
+// MissionPath returns the mission.md path of the active mission
+func (w *Workspace) MissionPath() string {
+	return filepath.Join(w.MissionDir(), "mission.md")
+}
+
+// List returns all missions in the workspace: the unnamed root slot (if occupied)
+// followed by named missions sorted by ID.
+func (w *Workspace) List() ([]MissionEntry, error) {
+	active := w.MissionPath()
+	var entries []MissionEntry
+
+	rootPath := filepath.Join(w.rootDir, "mission.md")
+	if exists, _ := afero.Exists(w.fs, rootPath); exists {
+		if entry, err := w.entry(rootPath, active); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	dirs, err := afero.ReadDir(w.fs, filepath.Join(w.rootDir, missionsDirName))
+	if err != nil {
+		return entries, nil
+	}
+
+	var named []MissionEntry
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		path := filepath.Join(w.DirFor(dir.Name()), "mission.md")
+		if exists, _ := afero.Exists(w.fs, path); !exists {
+			continue
+		}
+		entry, err := w.entry(path, active)
+		if err != nil {
+			continue
+		}
+		named = append(named, entry)
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].ID < named[j].ID })
+
+	return append(entries, named...), nil
+}
+
+// Switch makes the named mission with the given ID active. A mission occupying
+// the unnamed root slot is first moved into its own named directory so that it
+// keeps its log, plan and checkpoints and can be switched back to later.
+func (w *Workspace) Switch(id string) error {
+	if id == "" {
+		return fmt.Errorf("mission ID is required")
+	}
+
+	rootID, err := w.rootMissionID()
+	if err != nil {
+		return err
+	}
+	if rootID != "" {
+		if err := w.relocateRoot(rootID); err != nil {
+			return err
+		}
+	}
+
+	if exists, _ := afero.Exists(w.fs, filepath.Join(w.DirFor(id), "mission.md")); !exists {
+		return fmt.Errorf("mission %s not found", id)
+	}
+
+	return afero.WriteFile(w.fs, filepath.Join(w.rootDir, activePointerFile), []byte(id+"\n"), 0644)
+}
+
+// Detach clears the active pointer so a new mission can be planned in the root
+// slot. A mission occupying the root slot is moved into its named directory first.
+func (w *Workspace) Detach() error {
+	rootID, err := w.rootMissionID()
+	if err != nil {
+		return err
+	}
+	if rootID != "" {
+		if err := w.relocateRoot(rootID); err != nil {
+			return err
+		}
+	}
+	return w.clearPointer()
+}
+
+// Release removes the pointer if it references the given mission. Used after a
+// named mission has been archived or otherwise removed.
+func (w *Workspace) Release(id string) error {
+	data, err := afero.ReadFile(w.fs, filepath.Join(w.rootDir, activePointerFile))
+	if err != nil {
+		return nil
+	}
+	if strings.TrimSpace(string(data)) != id {
+		return nil
+	}
+	return w.clearPointer()
+}
+
+// clearPointer removes the active pointer file if present
+func (w *Workspace) clearPointer() error {
+	path := filepath.Join(w.rootDir, activePointerFile)
+	if exists, _ := afero.Exists(w.fs, path); !exists {
+		return nil
+	}
+	if err := w.fs.Remove(path); err != nil {
+		return fmt.Errorf("removing active mission pointer: %w", err)
+	}
+	return nil
+}
+
+// rootMissionID returns the ID of the mission in the unnamed root slot, if any
+func (w *Workspace) rootMissionID() (string, error) {
+	rootPath := filepath.Join(w.rootDir, "mission.md")
+	if exists, _ := afero.Exists(w.fs, rootPath); !exists {
+		return "", nil
+	}
+	m, err := NewReader(w.fs, rootPath).Read()
+	if err != nil {
+		return "", fmt.Errorf("reading current mission: %w", err)
+	}
+	return m.ID, nil
+}
+
+// relocateRoot moves the root slot artifacts into the named mission directory
+func (w *Workspace) relocateRoot(id string) error {
+	dir := w.DirFor(id)
+	if exists, _ := afero.Exists(w.fs, filepath.Join(dir, "mission.md")); exists {
+		return fmt.Errorf("mission %s already exists in %s", id, dir)
+	}
+	if err := w.fs.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating mission directory: %w", err)
+	}
+
+	for _, name := range missionArtifacts {
+		src := filepath.Join(w.rootDir, name)
+		if exists, _ := afero.Exists(w.fs, src); !exists {
+			continue
+		}
+		if err := w.fs.Rename(src, filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("moving %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// entry builds a MissionEntry from the mission file at path
+func (w *Workspace) entry(path, activePath string) (MissionEntry, error) {
+	m, err := NewReader(w.fs, path).Read()
+	if err != nil {
+		return MissionEntry{}, err
+	}
+	return MissionEntry{
+		ID:     m.ID,
+		Status: m.Status,
+		Intent: m.GetIntent(),
+		Path:   path,
+		Active: path == activePath,
+	}, nil
+}
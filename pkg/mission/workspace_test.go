@@ -0,0 +1,113 @@
+package mission
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeWorkspaceMission(t *testing.T, fs afero.Fs, path, id, status string) {
+	t.Helper()
+	content := "---\nid: " + id + "\nstatus: " + status + "\n---\n\n## INTENT\nIntent for " + id + "\n"
+	require.NoError(t, fs.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+}
+
+func TestWorkspace_LegacyLayout(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ws := NewWorkspace(fs, ".mission")
+
+	assert.Equal(t, "", ws.ActiveID())
+	assert.Equal(t, ".mission", ws.MissionDir())
+	assert.Equal(t, filepath.Join(".mission", "mission.md"), ws.MissionPath())
+}
+
+func TestWorkspace_SwitchRelocatesRootMission(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ws := NewWorkspace(fs, ".mission")
+
+	writeWorkspaceMission(t, fs, ".mission/mission.md", "mission-a", "active")
+	require.NoError(t, afero.WriteFile(fs, ".mission/execution.log", []byte("log a"), 0644))
+	require.NoError(t, afero.WriteFile(fs, ".mission/plan.json", []byte("{}"), 0644))
+	writeWorkspaceMission(t, fs, ".mission/missions/mission-b/mission.md", "mission-b", "planned")
+
+	require.NoError(t, ws.Switch("mission-b"))
+
+	assert.Equal(t, "mission-b", ws.ActiveID())
+	assert.Equal(t, filepath.Join(".mission", "missions", "mission-b", "mission.md"), ws.MissionPath())
+
+	// Root slot artifacts moved into their own directory
+	for _, name := range []string{"mission.md", "execution.log", "plan.json"} {
+		exists, _ := afero.Exists(fs, filepath.Join(".mission", name))
+		assert.False(t, exists, "%s should be moved out of the root slot", name)
+		exists, _ = afero.Exists(fs, filepath.Join(".mission", "missions", "mission-a", name))
+		assert.True(t, exists, "%s should be in mission-a directory", name)
+	}
+
+	require.NoError(t, ws.Switch("mission-a"))
+	assert.Equal(t, "mission-a", ws.ActiveID())
+}
+
+func TestWorkspace_SwitchUnknownMission(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ws := NewWorkspace(fs, ".mission")
+
+	err := ws.Switch("missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestWorkspace_DetachAndList(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ws := NewWorkspace(fs, ".mission")
+
+	writeWorkspaceMission(t, fs, ".mission/missions/mission-b/mission.md", "mission-b", "planned")
+	require.NoError(t, ws.Switch("mission-b"))
+	require.NoError(t, ws.Detach())
+	assert.Equal(t, "", ws.ActiveID())
+	assert.Equal(t, ".mission", ws.MissionDir())
+
+	// Plan a new mission in the root slot
+	writeWorkspaceMission(t, fs, ".mission/mission.md", "mission-c", "planning")
+
+	entries, err := ws.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "mission-c", entries[0].ID)
+	assert.True(t, entries[0].Active)
+	assert.Equal(t, "mission-b", entries[1].ID)
+	assert.False(t, entries[1].Active)
+	assert.Equal(t, "Intent for mission-b", entries[1].Intent)
+}
+
+func TestBaseService_RootDir(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	legacy := NewBaseServiceWithPath(fs, ".mission", ".mission/mission.md")
+	assert.Equal(t, ".mission", legacy.RootDir())
+
+	named := NewBaseServiceWithPath(fs, ".mission/missions/abc", ".mission/missions/abc/mission.md")
+	assert.Equal(t, ".mission", named.RootDir())
+}
+
+func TestArchiver_NamedMissionCleanup(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ws := NewWorkspace(fs, ".mission")
+
+	writeWorkspaceMission(t, fs, ".mission/missions/mission-b/mission.md", "mission-b", "completed")
+	require.NoError(t, ws.Switch("mission-b"))
+
+	archiver := NewArchiver(fs, ws.MissionPath(), &MockGitClient{commitMessage: "feat: b\n\nMission-Id: mission-b"})
+	require.NoError(t, archiver.Archive(t.Context(), false))
+	require.NoError(t, archiver.CleanupObsoleteFiles())
+
+	exists, _ := afero.Exists(fs, ".mission/completed/mission-b-mission.md")
+	assert.True(t, exists, "mission should be archived to the shared completed directory")
+	exists, _ = afero.DirExists(fs, ".mission/missions/mission-b")
+	assert.False(t, exists, "named mission directory should be removed")
+	exists, _ = afero.Exists(fs, ".mission/active")
+	assert.False(t, exists, "active pointer should be cleared")
+}
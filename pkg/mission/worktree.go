@@ -0,0 +1,115 @@
+package mission
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/spf13/afero"
+)
+
+// MissionWorktree is a git worktree created by m mission start --worktree, with the
+// mission it holds
+type MissionWorktree struct {
+	git.Worktree
+	// MissionID and Status describe the mission in the worktree; both are empty once the
+	// mission was archived
+	MissionID string `json:"mission_id,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// PruneResult describes the worktrees Prune cleaned up
+type PruneResult struct {
+	// Forgotten lists worktrees whose directories were already deleted
+	Forgotten []string
+	// Removed lists worktrees without a mission that were deleted
+	Removed []string
+	// Kept maps worktrees without a mission that could not be removed to the reason
+	Kept map[string]string
+}
+
+// WorktreeService lists and cleans up mission worktrees
+type WorktreeService struct {
+	fs  afero.Fs
+	git git.GitClient
+}
+
+// NewWorktreeService creates a WorktreeService for the repository of gitClient
+func NewWorktreeService(fs afero.Fs, gitClient git.GitClient) *WorktreeService {
+	return &WorktreeService{fs: fs, git: gitClient}
+}
+
+// List returns the linked worktrees that have a mission branch checked out
+func (w *WorktreeService) List(ctx context.Context) ([]MissionWorktree, error) {
+	worktrees, err := w.git.ListWorktrees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing worktrees: %w", err)
+	}
+
+	var missions []MissionWorktree
+	for i, wt := range worktrees {
+		if i == 0 || !strings.HasPrefix(wt.Branch, MissionBranchPrefix) {
+			continue
+		}
+		entry := MissionWorktree{Worktree: wt}
+		if !wt.Prunable {
+			path := NewWorkspace(w.fs, filepath.Join(wt.Path, DefaultDir)).MissionPath()
+			if m, err := NewReader(w.fs, path).Read(); err == nil {
+				entry.MissionID, entry.Status = m.ID, m.Status
+			}
+		}
+		missions = append(missions, entry)
+	}
+	return missions, nil
+}
+
+// Main returns the path of the main worktree, which holds the shared mission directory
+func (w *WorktreeService) Main(ctx context.Context) (string, error) {
+	worktrees, err := w.git.ListWorktrees(ctx)
+	if err != nil {
+		return "", fmt.Errorf("listing worktrees: %w", err)
+	}
+	if len(worktrees) == 0 {
+		return "", fmt.Errorf("no worktrees found")
+	}
+	return worktrees[0].Path, nil
+}
+
+// Remove deletes the worktree at path. Worktrees with local changes are refused.
+func (w *WorktreeService) Remove(ctx context.Context, path string) error {
+	if err := w.git.RemoveWorktree(ctx, path, false); err != nil {
+		return fmt.Errorf("removing worktree %s: %w", path, err)
+	}
+	return nil
+}
+
+// Prune forgets mission worktrees whose directories were deleted and removes those
+// whose mission was archived. Worktrees with local changes are kept.
+func (w *WorktreeService) Prune(ctx context.Context) (*PruneResult, error) {
+	worktrees, err := w.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PruneResult{Kept: make(map[string]string)}
+	for _, wt := range worktrees {
+		switch {
+		case wt.Prunable:
+			result.Forgotten = append(result.Forgotten, wt.Path)
+		case wt.MissionID == "":
+			if err := w.git.RemoveWorktree(ctx, wt.Path, false); err != nil {
+				result.Kept[wt.Path] = err.Error()
+				continue
+			}
+			result.Removed = append(result.Removed, wt.Path)
+		}
+	}
+	if len(result.Forgotten) > 0 {
+		if err := w.git.PruneWorktrees(ctx); err != nil {
+			return nil, fmt.Errorf("pruning worktrees: %w", err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package mission
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnatag/mission-toolkit/pkg/git"
+	"github.com/go-git/go-billy/v5/memfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMemGitClient creates an in-memory repository with one commit on master whose
+// working tree is fs
+func newMemGitClient(t *testing.T, fs afero.Fs) *git.MemGitClient {
+	repo, err := gogit.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Commit("Initial commit", &gogit.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	require.NoError(t, err)
+	return git.NewMemGitClient(repo, fs)
+}
+
+func TestStarter_StartWorktree(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, ".mission/mission.md", []byte(startMission), 0644))
+	require.NoError(t, afero.WriteFile(fs, ".mission/execution.log", []byte("log\n"), 0644))
+	gitClient := newMemGitClient(t, fs)
+
+	result, err := NewStarter(fs, ".mission/mission.md", gitClient).StartWithOptions(t.Context(), StartOptions{Worktree: true, WorktreeDir: "/worktrees"})
+	require.NoError(t, err)
+	assert.Equal(t, &StartResult{MissionID: "start-1", Branch: "mission/start-1", BaseBranch: "master", Worktree: "/worktrees/start-1"}, result)
+
+	branch, err := gitClient.CurrentBranch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch, "the current checkout keeps its branch")
+
+	for _, name := range []string{"mission.md", "execution.log"} {
+		exists, err := afero.Exists(fs, filepath.Join(".mission", name))
+		require.NoError(t, err)
+		assert.False(t, exists, "%s moved out of the main checkout", name)
+	}
+	log, err := afero.ReadFile(fs, "/worktrees/start-1/.mission/execution.log")
+	require.NoError(t, err)
+	assert.Equal(t, "log\n", string(log))
+	m, err := NewReader(fs, "/worktrees/start-1/.mission/mission.md").Read()
+	require.NoError(t, err)
+	assert.Equal(t, "mission/start-1", m.Branch)
+	assert.Equal(t, "master", m.BaseBranch)
+	assert.Equal(t, "/worktrees/start-1", m.Worktree)
+
+	worktrees := NewWorktreeService(fs, gitClient)
+	list, err := worktrees.List(t.Context())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "start-1", list[0].MissionID)
+	assert.Equal(t, "active", list[0].Status)
+	assert.Equal(t, "mission/start-1", list[0].Branch)
+
+	mainCheckout, err := worktrees.Main(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ".", mainCheckout)
+
+	// An active mission is never pruned
+	pruned, err := worktrees.Prune(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, pruned.Removed)
+	assert.Empty(t, pruned.Kept)
+
+	// Once archived, the worktree is removed
+	require.NoError(t, fs.Remove("/worktrees/start-1/.mission/mission.md"))
+	pruned, err = worktrees.Prune(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/worktrees/start-1"}, pruned.Removed)
+	list, err = worktrees.List(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestStarter_StartWorktree_LinksSharedFiles(t *testing.T) {
+	dir := t.TempDir()
+	fs := afero.NewOsFs()
+	root := filepath.Join(dir, ".mission")
+	require.NoError(t, fs.MkdirAll(root, 0755))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(root, "mission.md"), []byte(startMission), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(root, "backlog.md"), []byte("- idea\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(root, "checkpoints.json"), []byte("{}"), 0644))
+
+	_, err := NewStarter(fs, filepath.Join(root, "mission.md"), newMemGitClient(t, fs)).
+		StartWithOptions(t.Context(), StartOptions{Worktree: true, WorktreeDir: filepath.Join(dir, "worktrees")})
+	require.NoError(t, err)
+
+	worktreeRoot := filepath.Join(dir, "worktrees", "start-1", ".mission")
+	for _, name := range []string{"backlog.md", "completed"} {
+		target, err := os.Readlink(filepath.Join(worktreeRoot, name))
+		require.NoError(t, err, "%s is shared", name)
+		assert.Equal(t, filepath.Join(root, name), target)
+	}
+	_, err = os.Lstat(filepath.Join(worktreeRoot, "checkpoints.json"))
+	assert.True(t, os.IsNotExist(err), "checkpoints belong to one checkout")
+}
+
+func TestStarter_StartWorktree_NamedMission(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, ".mission/missions/start-1/mission.md", []byte(startMission), 0644))
+	require.NoError(t, afero.WriteFile(fs, ".mission/active", []byte("start-1\n"), 0644))
+	workspace := NewWorkspace(fs, ".mission")
+	require.Equal(t, ".mission/missions/start-1/mission.md", workspace.MissionPath())
+
+	_, err := NewStarter(fs, workspace.MissionPath(), newMemGitClient(t, fs)).StartWithOptions(t.Context(), StartOptions{Worktree: true, WorktreeDir: "/worktrees"})
+	require.NoError(t, err)
+
+	exists, err := afero.DirExists(fs, ".mission/missions/start-1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Empty(t, workspace.ActiveID(), "the active pointer is released")
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dnatag/mission-toolkit/pkg/logger"
 	"github.com/dnatag/mission-toolkit/pkg/md"
@@ -15,16 +17,21 @@ import (
 type Writer struct {
 	*BaseService
 	loggerConfig *logger.Config // Logger configuration for execution logging
+	command      string         // CLI command recorded in status history
 }
 
 // NewWriter creates a new mission writer for the specified mission file path.
 // The mission directory is derived from the path's directory component.
-// Logger is configured with default settings (both console and file output).
+// Logger is configured with default settings (both console and file output),
+// writing to the execution.log next to the mission file.
 func NewWriter(fs afero.Fs, path string) *Writer {
 	missionDir := filepath.Dir(path)
+	loggerConfig := logger.DefaultConfig()
+	loggerConfig.FilePath = filepath.Join(missionDir, "execution.log")
+	loggerConfig.Fs = fs
 	return &Writer{
 		BaseService:  NewBaseServiceWithPath(fs, missionDir, path),
-		loggerConfig: logger.DefaultConfig(),
+		loggerConfig: loggerConfig,
 	}
 }
 
@@ -38,12 +45,33 @@ func (w *Writer) Write(mission *Mission) error {
 	return afero.WriteFile(w.FS(), w.MissionPath(), []byte(content), 0644)
 }
 
+// SetCommand sets the CLI command recorded with subsequent status transitions.
+func (w *Writer) SetCommand(command string) {
+	w.command = command
+}
+
 // UpdateStatus updates the status field in the mission file while preserving the body.
+// The transition is validated against the mission lifecycle and appended to the
+// status history. Re-applying the current status is accepted but not recorded.
 func (w *Writer) UpdateStatus(newStatus string) error {
 	mission, err := NewReader(w.FS(), w.MissionPath()).Read()
 	if err != nil {
 		return fmt.Errorf("failed to read mission: %w", err)
 	}
+
+	if err := ValidateTransition(mission.Status, newStatus); err != nil {
+		return err
+	}
+	if mission.Status == newStatus {
+		return nil
+	}
+
+	mission.StatusHistory = append(mission.StatusHistory, StatusTransition{
+		From:    mission.Status,
+		To:      newStatus,
+		At:      time.Now().UTC().Truncate(time.Second),
+		Command: w.command,
+	})
 	mission.Status = newStatus
 	return w.Write(mission)
 }
@@ -53,7 +81,7 @@ func (w *Writer) CreateWithIntent(missionID string, intent string) error {
 	mission := &Mission{
 		ID:        missionID,
 		Iteration: 1,
-		Status:    "planning",
+		Status:    StatusPlanning,
 		Body:      fmt.Sprintf("## INTENT\n%s\n", intent),
 	}
 	return w.Write(mission)
@@ -71,8 +99,8 @@ func normalizePlanContent(content string) string {
 		indent := line[:len(line)-len(trimmed)]
 		var normalized string
 
-		// Already checkbox format
-		if strings.HasPrefix(trimmed, "- [ ] ") || strings.HasPrefix(trimmed, "- [x] ") {
+		// Already checkbox format (any step status marker)
+		if planItemPattern.MatchString(trimmed) {
 			continue
 		}
 		// Dash with number: "- 1. item" → "- [ ] item"
@@ -128,6 +156,12 @@ func (w *Writer) UpdateSection(section string, content string) error {
 		return fmt.Errorf("updating section %q: %w", section, err)
 	}
 
+	if strings.ToLower(section) == "plan" {
+		if err := w.syncPlanSteps(doc); err != nil {
+			return err
+		}
+	}
+
 	return w.writeDocument(doc)
 }
 
@@ -152,9 +186,13 @@ func (w *Writer) UpdateList(section string, items []string, appendMode bool) err
 	}
 
 	var updateErr error
-	if appendMode {
+	switch {
+	case appendMode && section == "plan":
+		// Append to the raw content so existing step markers are preserved
+		updateErr = w.appendPlanItems(doc, formattedItems)
+	case appendMode:
 		updateErr = doc.AppendSectionList(sectionName, formattedItems)
-	} else {
+	default:
 		updateErr = doc.UpdateSectionList(sectionName, formattedItems)
 	}
 
@@ -162,44 +200,114 @@ func (w *Writer) UpdateList(section string, items []string, appendMode bool) err
 		return fmt.Errorf("updating %q section: %w", section, updateErr)
 	}
 
+	if section == "plan" {
+		if err := w.syncPlanSteps(doc); err != nil {
+			return err
+		}
+	}
+
 	return w.writeDocument(doc)
 }
 
-// MarkPlanStepComplete marks a specific plan step as completed and optionally logs a message.
-func (w *Writer) MarkPlanStepComplete(step int, status, message string) error {
-	mission, err := NewReader(w.FS(), w.MissionPath()).Read()
+// appendPlanItems appends formatted checkbox items to the PLAN section content.
+func (w *Writer) appendPlanItems(doc *md.Document, items []string) error {
+	content, err := doc.GetSection("PLAN")
 	if err != nil {
-		return fmt.Errorf("reading mission: %w", err)
+		return err
 	}
-
-	// Handle logging if message is provided
-	if message != "" {
-		w.logPlanStep(mission.ID, step, status, message)
+	lines := []string{}
+	if content != "" {
+		lines = append(lines, content)
+	}
+	for _, item := range items {
+		lines = append(lines, "- "+item)
 	}
+	return doc.UpdateSectionContent("PLAN", strings.Join(lines, "\n"))
+}
+
+// MarkPlanStepComplete marks a specific plan step as completed and optionally logs a message.
+// Unlike UpdateStepStatus, the step is marked done regardless of its current status.
+func (w *Writer) MarkPlanStepComplete(step int, status, message string) error {
+	return w.setStepStatus(strconv.Itoa(step), StepDone, status, message, false)
+}
+
+// UpdateStepStatus moves a plan step, referenced by ID (s3) or position (3), to a new
+// status, records its start or finish time and re-renders its checkbox marker.
+// The message, if any, is logged to execution.log.
+func (w *Writer) UpdateStepStatus(ref, status, message string) error {
+	level := ""
+	switch status {
+	case StepDone:
+		level = logger.LevelSuccess
+	case StepFailed:
+		level = logger.LevelFailed
+	}
+	return w.setStepStatus(ref, status, level, message, true)
+}
 
+// setStepStatus applies a step status change, optionally validating the transition.
+func (w *Writer) setStepStatus(ref, status, level, message string, validate bool) error {
 	doc, err := w.parseDocument()
 	if err != nil {
 		return err
 	}
-
-	// Get the raw section content to preserve checkbox states
-	planContent, err := doc.GetSection("PLAN")
+	mission, err := missionFromDocument(doc)
 	if err != nil {
-		return fmt.Errorf("getting plan section: %w", err)
+		return err
 	}
 
-	updatedContent, err := w.markStepComplete(planContent, step)
+	steps := mission.PlanSteps()
+	index, err := findStep(steps, ref)
 	if err != nil {
 		return err
 	}
+	if validate {
+		if err := ValidateStepTransition(steps[index].Status, status); err != nil {
+			return fmt.Errorf("step %s: %w", steps[index].ID, err)
+		}
+	}
+
+	if message != "" {
+		w.logPlanStep(mission.ID, index+1, level, message)
+	}
+	if steps[index].Status == status {
+		return nil
+	}
+
+	applyStepStatus(&steps[index], status, time.Now().UTC().Truncate(time.Second))
 
-	if err := doc.UpdateSectionContent("PLAN", updatedContent); err != nil {
+	planContent, err := doc.GetSection("PLAN")
+	if err != nil {
+		return fmt.Errorf("getting plan section: %w", err)
+	}
+	if err := doc.UpdateSectionContent("PLAN", renderPlanMarker(planContent, index, status)); err != nil {
 		return fmt.Errorf("updating plan section: %w", err)
 	}
+	if len(doc.Frontmatter) > 0 {
+		doc.Frontmatter["plan_steps"] = steps
+	}
 
 	return w.writeDocument(doc)
 }
 
+// syncPlanSteps records plan step IDs in the frontmatter after the PLAN section changed.
+// Legacy documents without frontmatter are left untouched.
+func (w *Writer) syncPlanSteps(doc *md.Document) error {
+	if len(doc.Frontmatter) == 0 {
+		return nil
+	}
+	mission, err := missionFromDocument(doc)
+	if err != nil {
+		return err
+	}
+	if steps := mission.PlanSteps(); len(steps) > 0 {
+		doc.Frontmatter["plan_steps"] = steps
+	} else {
+		delete(doc.Frontmatter, "plan_steps")
+	}
+	return nil
+}
+
 // parseDocument reads and parses the mission document.
 func (w *Writer) parseDocument() (*md.Document, error) {
 	data, err := afero.ReadFile(w.FS(), w.MissionPath())
@@ -239,70 +347,70 @@ func (w *Writer) logPlanStep(missionID string, step int, status, message string)
 	log.LogStep(status, fmt.Sprintf("Plan Step %d", step), message)
 }
 
-// markStepComplete marks a specific step as complete in the plan content.
-// Expects plan content to be in checkbox format (normalized by UpdateSection/UpdateList).
-func (w *Writer) markStepComplete(planContent string, step int) (string, error) {
-	lines := strings.Split(planContent, "\n")
-	planStepCount := 0
+// UpdateFrontmatter applies frontmatter edits: key=value sets a value (repeat it or use
+// commas to build a list), key+=value and key-=value add to or remove from a list, and
+// -key removes a key. Known keys are type checked; unknown keys are kept as written.
+func (w *Writer) UpdateFrontmatter(pairs []string) error {
+	edits, err := ParseFrontmatterEdits(pairs)
+	if err != nil {
+		return err
+	}
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "- [ ] ") || strings.HasPrefix(trimmed, "- [x] ") {
-			planStepCount++
-			if planStepCount == step {
-				lines[i] = strings.Replace(line, "- [ ]", "- [x]", 1)
-			}
+	doc, err := w.parseDocument()
+	if err != nil {
+		return err
+	}
+	if len(doc.Frontmatter) == 0 {
+		// Legacy missions are converted to YAML frontmatter on first edit
+		mission, err := NewReader(w.FS(), w.MissionPath()).Read()
+		if err != nil {
+			return fmt.Errorf("reading mission: %w", err)
 		}
+		doc.Frontmatter = frontmatterMap(mission)
+		doc.Body = mission.Body
 	}
 
-	if step < 1 || step > planStepCount {
-		return "", fmt.Errorf("step %d not found (total steps: %d)", step, planStepCount)
+	if err := applyFrontmatterEdits(doc.Frontmatter, edits); err != nil {
+		return err
+	}
+	if _, err := missionFromDocument(doc); err != nil {
+		return err
 	}
 
-	return strings.Join(lines, "\n"), nil
+	return w.writeDocument(doc)
 }
 
-// UpdateFrontmatter updates frontmatter fields.
-func (w *Writer) UpdateFrontmatter(pairs []string) error {
-	mission, err := NewReader(w.FS(), w.MissionPath()).Read()
-	if err != nil {
-		return fmt.Errorf("reading mission: %w", err)
+// format formats a Mission struct into markdown with YAML frontmatter using pkg/md abstraction.
+func (w *Writer) format(mission *Mission) (string, error) {
+	// Use pkg/md to write document with frontmatter
+	doc := &md.Document{
+		Frontmatter: frontmatterMap(mission),
+		Body:        mission.Body,
 	}
 
-	for _, pair := range pairs {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid frontmatter pair: %s", pair)
-		}
-		key, value := parts[0], parts[1]
-
-		switch key {
-		case "track":
-			var track int
-			fmt.Sscanf(value, "%d", &track)
-			mission.Track = track
-		case "type":
-			mission.Type = value
-		case "domains":
-			mission.Domains = append(mission.Domains, value)
-		}
+	data, err := doc.Write()
+	if err != nil {
+		return "", fmt.Errorf("writing document: %w", err)
 	}
 
-	return w.Write(mission)
+	return string(data), nil
 }
 
-// format converts a Mission struct to markdown with YAML frontmatter.
-// format formats a Mission struct into markdown with YAML frontmatter using pkg/md abstraction.
-func (w *Writer) format(mission *Mission) (string, error) {
-	// Build frontmatter map with required fields
-	frontmatter := map[string]interface{}{
-		"id":        mission.ID,
-		"type":      mission.Type,
-		"track":     mission.Track,
-		"iteration": mission.Iteration,
-		"status":    mission.Status,
+// frontmatterMap builds the frontmatter for a mission. Extra keys are copied first
+// so the struct fields always win.
+func frontmatterMap(mission *Mission) map[string]interface{} {
+	frontmatter := make(map[string]interface{}, len(mission.Extra)+5)
+	for key, value := range mission.Extra {
+		frontmatter[key] = value
 	}
 
+	// Required fields
+	frontmatter["id"] = mission.ID
+	frontmatter["type"] = mission.Type
+	frontmatter["track"] = mission.Track
+	frontmatter["iteration"] = mission.Iteration
+	frontmatter["status"] = mission.Status
+
 	// Add optional fields if present
 	if mission.ParentMission != "" {
 		frontmatter["parent_mission"] = mission.ParentMission
@@ -312,16 +420,25 @@ func (w *Writer) format(mission *Mission) (string, error) {
 		frontmatter["domains"] = mission.Domains
 	}
 
-	// Use pkg/md to write document with frontmatter
-	doc := &md.Document{
-		Frontmatter: frontmatter,
-		Body:        mission.Body,
+	if mission.Branch != "" {
+		frontmatter["branch"] = mission.Branch
 	}
 
-	data, err := doc.Write()
-	if err != nil {
-		return "", fmt.Errorf("writing document: %w", err)
+	if mission.BaseBranch != "" {
+		frontmatter["base_branch"] = mission.BaseBranch
 	}
 
-	return string(data), nil
+	if mission.Worktree != "" {
+		frontmatter["worktree"] = mission.Worktree
+	}
+
+	if len(mission.StatusHistory) > 0 {
+		frontmatter["status_history"] = mission.StatusHistory
+	}
+
+	if len(mission.Steps) > 0 {
+		frontmatter["plan_steps"] = mission.Steps
+	}
+
+	return frontmatter
 }
@@ -114,7 +114,7 @@ id: preserve-test
 type: DRY
 track: 3
 iteration: 2
-status: planned
+status: executed
 parent_mission: parent-123
 ---
 
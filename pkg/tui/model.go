@@ -96,6 +96,11 @@ type currentMissionMsg struct {
 	err     error
 }
 
+type archivedMissionMsg struct {
+	mission *mission.Mission
+	err     error
+}
+
 type initialMissionsMsg struct {
 	missions    []*mission.Mission
 	totalCount  int
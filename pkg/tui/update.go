@@ -1,11 +1,10 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbletea"
@@ -46,6 +45,12 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case archivedMissionMsg:
+		if msg.err == nil && m.selectedMission != nil && m.selectedMission.ID == msg.mission.ID {
+			m.selectedMission = msg.mission
+		}
+		return m, nil
+
 	case refreshTickMsg:
 		// Refresh execution log for active missions
 		if m.currentMission != nil && m.currentMission.Status == "active" {
@@ -139,6 +144,8 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.leftPaneScrollY = 0
 					m.rightPaneScrollX = 0
 					m.rightPaneScrollY = 0
+					// The list holds index summaries; load the full archived mission
+					return m, loadArchivedMission(m.selectedMission.ID)
 				}
 			}
 		case "up", "k":
@@ -228,7 +235,8 @@ func loadExecutionLog(missionID string, isActive bool) tea.Cmd {
 	return func() tea.Msg {
 		var logPath string
 		if isActive {
-			logPath = ".mission/execution.log"
+			workspace := mission.NewWorkspace(afero.NewOsFs(), mission.DefaultDir)
+			logPath = filepath.Join(workspace.MissionDir(), "execution.log")
 		} else {
 			logPath = fmt.Sprintf(".mission/completed/%s-execution.log", missionID)
 		}
@@ -251,15 +259,10 @@ func loadCommitMessage(missionID string) tea.Cmd {
 			return commitMsg{content: string(content)}
 		}
 
-		// Fallback: try to get from git using the final consolidated commit
-		// The final commit should be tagged when the mission is completed
+		// Fallback: find the mission commit in git by its Mission-Id trailer
 		gitClient := git.NewCmdGitClient(".")
-
-		// Look for the mission's final commit by checking recent commits
-		// that contain the mission ID in the commit message
-		commitMessage, err := gitClient.GetCommitMessage("HEAD")
-		if err == nil && strings.Contains(commitMessage, missionID) {
-			return commitMsg{content: commitMessage}
+		if commits, err := gitClient.FindCommitsByTrailer(context.Background(), mission.TrailerMissionID, missionID); err == nil && len(commits) > 0 {
+			return commitMsg{content: commits[0].Message}
 		}
 
 		// If that fails, provide a helpful message
@@ -272,7 +275,7 @@ func loadCommitMessage(missionID string) tea.Cmd {
 // loadCurrentMission loads the current active mission
 func loadCurrentMission() tea.Msg {
 	fs := afero.NewOsFs()
-	missionPath := ".mission/mission.md"
+	missionPath := mission.NewWorkspace(fs, mission.DefaultDir).MissionPath()
 	reader := mission.NewReader(fs, missionPath)
 	m, err := reader.Read()
 	if err != nil {
@@ -286,66 +289,35 @@ func loadInitialMissions() tea.Msg {
 	return loadCompletedMissionsBatch(0, -1) // Load all missions
 }
 
-// loadCompletedMissionsBatch loads a batch of completed missions
+// loadCompletedMissionsBatch loads a batch of completed missions from the archive index
 func loadCompletedMissionsBatch(offset, limit int) tea.Msg {
-	fs := afero.NewOsFs()
-	completedDir := ".mission/completed"
-	entries, err := os.ReadDir(completedDir)
+	index := mission.NewArchiveIndex(afero.NewOsFs(), filepath.Join(mission.DefaultDir, "completed"))
+	entries, total, err := index.Page(offset, limit)
 	if err != nil {
 		return initialMissionsMsg{err: err}
 	}
 
-	var missionFiles []string
+	missions := make([]*mission.Mission, 0, len(entries))
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), "-mission.md") {
-			missionFiles = append(missionFiles, entry.Name())
-		}
-	}
-
-	sort.Slice(missionFiles, func(i, j int) bool {
-		return missionFiles[i] > missionFiles[j]
-	})
-
-	var missions []*mission.Mission
-	loaded := 0
-	fileIndex := 0
-
-	for fileIndex < len(missionFiles) && loaded < offset {
-		path := filepath.Join(completedDir, missionFiles[fileIndex])
-		reader := mission.NewReader(fs, path)
-		_, err := reader.Read()
-		if err == nil {
-			loaded++
-		}
-		fileIndex++
-	}
-
-	batchLoaded := 0
-	for fileIndex < len(missionFiles) && (limit < 0 || batchLoaded < limit) {
-		path := filepath.Join(completedDir, missionFiles[fileIndex])
-		reader := mission.NewReader(fs, path)
-		m, err := reader.Read()
-		if err == nil {
-			missions = append(missions, m)
-			batchLoaded++
-		}
-		fileIndex++
-	}
-
-	totalLoadable := 0
-	for _, filename := range missionFiles {
-		path := filepath.Join(completedDir, filename)
-		reader := mission.NewReader(fs, path)
-		_, err := reader.Read()
-		if err == nil {
-			totalLoadable++
-		}
+		missions = append(missions, entry.Mission())
 	}
 
 	return initialMissionsMsg{
 		missions:    missions,
-		totalCount:  totalLoadable,
+		totalCount:  total,
 		loadedCount: len(missions),
 		offset:      offset,
 	}
 }
+
+// loadArchivedMission loads the full archived mission file for the details view
+func loadArchivedMission(missionID string) tea.Cmd {
+	return func() tea.Msg {
+		path := fmt.Sprintf(".mission/completed/%s-mission.md", missionID)
+		m, err := mission.NewReader(afero.NewOsFs(), path).Read()
+		if err != nil {
+			return archivedMissionMsg{err: err}
+		}
+		return archivedMissionMsg{mission: m}
+	}
+}
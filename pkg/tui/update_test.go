@@ -120,9 +120,12 @@ func TestUpdate_KeyEnter_Esc(t *testing.T) {
 		t.Errorf("expected selectedMission ID 'm1', got '%s'", model.selectedMission.ID)
 	}
 
-	// Verify no command is returned (lazy loading)
-	if cmd != nil {
-		t.Error("expected no command on Enter (lazy loading)")
+	// Only the full archived mission is loaded; logs stay lazy
+	if cmd == nil {
+		t.Fatal("expected a command loading the archived mission on Enter")
+	}
+	if _, ok := cmd().(archivedMissionMsg); !ok {
+		t.Error("expected Enter to load the archived mission")
 	}
 
 	// Verify execution log is not loaded yet
@@ -148,6 +151,25 @@ func TestUpdate_KeyEnter_Esc(t *testing.T) {
 	}
 }
 
+func TestUpdate_ArchivedMissionMsg(t *testing.T) {
+	m := NewDashboardModel()
+	m.selectedMission = &mission.Mission{ID: "m1", Status: "completed", Body: "## INTENT\nSummary"}
+
+	// A mission loaded for a different selection is ignored
+	updated, _ := m.Update(archivedMissionMsg{mission: &mission.Mission{ID: "m2"}})
+	model := updated.(DashboardModel)
+	if model.selectedMission.ID != "m1" {
+		t.Errorf("expected selection to stay on m1, got %s", model.selectedMission.ID)
+	}
+
+	full := &mission.Mission{ID: "m1", Status: "completed", Body: "## INTENT\nSummary\n\n## PLAN\n- [x] Step"}
+	updated, _ = model.Update(archivedMissionMsg{mission: full})
+	model = updated.(DashboardModel)
+	if model.selectedMission != full {
+		t.Error("expected selectedMission to be replaced by the full archived mission")
+	}
+}
+
 func TestUpdate_KeyNavigation(t *testing.T) {
 	m := NewDashboardModel()
 	m.completedMissions = []*mission.Mission{
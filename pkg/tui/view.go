@@ -166,6 +166,10 @@ func (m DashboardModel) renderCurrentMission() string {
 		nextSteps = "Unknown status - use '@m.plan' to create a new mission"
 	}
 
+	if progress := mission.PlanProgress(); progress != nil {
+		nextSteps = fmt.Sprintf("Plan: %s\n%s", progress.Summary, nextSteps)
+	}
+
 	content := fmt.Sprintf("%s %s (Track %d)\n\n%s\n\n%s",
 		statusStyle.Render(strings.ToUpper(mission.Status)),
 		mission.Type,
@@ -256,7 +260,11 @@ func (m DashboardModel) renderMissionDetails(mission *mission.Mission) string {
 
 	if len(plan) > 0 {
 		sections = append(sections, "")
-		sections = append(sections, "Plan:")
+		if progress := mission.PlanProgress(); progress != nil {
+			sections = append(sections, fmt.Sprintf("Plan (%s):", progress.Summary))
+		} else {
+			sections = append(sections, "Plan:")
+		}
 		for _, p := range plan {
 			sections = append(sections, fmt.Sprintf("  %s", p))
 		}
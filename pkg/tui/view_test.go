@@ -125,6 +125,20 @@ func TestRenderMissionDetails(t *testing.T) {
 	}
 }
 
+func TestRenderMissionDetails_PlanProgress(t *testing.T) {
+	m := NewDashboardModel()
+	testMission := &mission.Mission{
+		ID:     "test-123",
+		Status: "active",
+		Body:   "## INTENT\nTest intent\n\n## PLAN\n- [x] First\n- [-] Second\n- [~] Third\n- [ ] Fourth",
+	}
+
+	output := m.renderMissionDetails(testMission)
+	if !strings.Contains(output, "step 3/4 in progress") {
+		t.Errorf("expected plan progress in output, got: %s", output)
+	}
+}
+
 func TestRenderDashboardView(t *testing.T) {
 	m := NewDashboardModel()
 	m.width = 100 // Set width to trigger pane calculation